@@ -0,0 +1,211 @@
+// Package client provides a Go client for the flax control api.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moorara/flax/spec"
+)
+
+const pollInterval = 100 * time.Millisecond
+
+// Client is a client for the flax control api.
+type Client struct {
+	addr   string
+	client *http.Client
+}
+
+// New creates a new client for a flax control api.
+// addr is the base address of the control port (i.e. http://localhost:9999).
+func New(addr string) *Client {
+	return &Client{
+		addr: strings.TrimSuffix(addr, "/"),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// ResponseError is returned when the control api responds with an unexpected status code.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("flax: %d %s", e.StatusCode, e.Message)
+}
+
+// do sends a request to the control api and decodes the response body into out if not nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}, expectedStatusCode int) error {
+	var body io.Reader
+	if in != nil {
+		buf := new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(in); err != nil {
+			return err
+		}
+		body = buf
+	}
+
+	req, err := http.NewRequest(method, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatusCode {
+		msg := struct {
+			Message string `json:"message"`
+		}{}
+		_ = json.NewDecoder(resp.Body).Decode(&msg)
+
+		return &ResponseError{
+			StatusCode: resp.StatusCode,
+			Message:    msg.Message,
+		}
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
+
+// Health checks whether or not the flax server is ready.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, "GET", "/health", nil, nil, http.StatusOK)
+}
+
+// WaitReady blocks until the flax server is ready or the context is done.
+func (c *Client) WaitReady(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Health(ctx); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Mocks returns all registered mocks.
+func (c *Client) Mocks(ctx context.Context) ([]spec.MockInfo, error) {
+	mocks := []spec.MockInfo{}
+	if err := c.do(ctx, "GET", "/mocks", nil, &mocks, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return mocks, nil
+}
+
+// AddHTTPMock registers a new http mock and returns its id.
+// If the mock already exists, it will be replaced.
+func (c *Client) AddHTTPMock(ctx context.Context, m spec.HTTPMock) (string, error) {
+	info := spec.MockInfo{}
+	if err := c.do(ctx, "POST", "/mocks/http", m, &info, http.StatusCreated); err != nil {
+		return "", err
+	}
+
+	return info.ID, nil
+}
+
+// AddRESTMock registers a new rest mock and returns its id.
+// If the mock already exists, it will be replaced.
+func (c *Client) AddRESTMock(ctx context.Context, m spec.RESTMock) (string, error) {
+	info := spec.MockInfo{}
+	if err := c.do(ctx, "POST", "/mocks/rest", m, &info, http.StatusCreated); err != nil {
+		return "", err
+	}
+
+	return info.ID, nil
+}
+
+// DeleteMock deregisters an existing mock by its id.
+func (c *Client) DeleteMock(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/mocks/"+id, nil, nil, http.StatusNoContent)
+}
+
+// Reset restores the mocks defined in the spec and clears the journal.
+func (c *Client) Reset(ctx context.Context) error {
+	return c.do(ctx, "POST", "/reset", nil, nil, http.StatusNoContent)
+}
+
+// Reload reads the spec again and replaces all mocks with the ones from the spec.
+func (c *Client) Reload(ctx context.Context) error {
+	return c.do(ctx, "POST", "/reload", nil, nil, http.StatusNoContent)
+}
+
+// Journal returns all requests received by the mock server.
+func (c *Client) Journal(ctx context.Context) ([]spec.Request, error) {
+	requests := []spec.Request{}
+	if err := c.do(ctx, "GET", "/journal", nil, &requests, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ClearJournal removes all requests received and callbacks sent by the mock server.
+func (c *Client) ClearJournal(ctx context.Context) error {
+	return c.do(ctx, "DELETE", "/journal", nil, nil, http.StatusNoContent)
+}
+
+// Verify checks a verification against the requests received by the mock server.
+func (c *Client) Verify(ctx context.Context, v spec.Verification) (*spec.VerificationResult, error) {
+	result := new(spec.VerificationResult)
+	if err := c.do(ctx, "POST", "/verify", v, result, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Callbacks returns all callbacks sent by the mock server.
+func (c *Client) Callbacks(ctx context.Context) ([]spec.CallbackDelivery, error) {
+	deliveries := []spec.CallbackDelivery{}
+	if err := c.do(ctx, "GET", "/callbacks", nil, &deliveries, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Chaos returns whether or not chaos is enabled.
+func (c *Client) Chaos(ctx context.Context) (bool, error) {
+	status := spec.ChaosStatus{}
+	if err := c.do(ctx, "GET", "/chaos", nil, &status, http.StatusOK); err != nil {
+		return false, err
+	}
+
+	return status.Enabled, nil
+}
+
+// SetChaos enables or disables chaos.
+func (c *Client) SetChaos(ctx context.Context, enabled bool) error {
+	return c.do(ctx, "PUT", "/chaos", spec.ChaosStatus{Enabled: enabled}, nil, http.StatusOK)
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moorara/flax/internal/service"
+	"github.com/moorara/flax/spec"
+	"github.com/moorara/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServers() (*httptest.Server, *httptest.Server) {
+	s := spec.DefaultSpec()
+	mocks := service.NewMockService(log.NewNopLogger())
+	mocks.Load(s)
+	read := func() (*spec.Spec, error) { return s, nil }
+	control := service.NewControlService(log.NewNopLogger(), read, s, mocks)
+
+	return httptest.NewServer(mocks), httptest.NewServer(control.Router())
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		addr         string
+		expectedAddr string
+	}{
+		{"OK", "http://localhost:9999", "http://localhost:9999"},
+		{"TrailingSlash", "http://localhost:9999/", "http://localhost:9999"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(tc.addr)
+
+			assert.NotNil(t, c)
+			assert.NotNil(t, c.client)
+			assert.Equal(t, tc.expectedAddr, c.addr)
+		})
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	err := &ResponseError{
+		StatusCode: 404,
+		Message:    "mock abcdef not found",
+	}
+
+	assert.Equal(t, "flax: 404 mock abcdef not found", err.Error())
+}
+
+func TestClientWaitReady(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		mockServer, controlServer := newTestServers()
+		defer mockServer.Close()
+		defer controlServer.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		err := New(controlServer.URL).WaitReady(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		err := New(ts.URL).WaitReady(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+func TestClient(t *testing.T) {
+	mockServer, controlServer := newTestServers()
+	defer mockServer.Close()
+	defer controlServer.Close()
+
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close()
+
+	ctx := context.Background()
+	c := New(controlServer.URL)
+
+	httpID, err := c.AddHTTPMock(ctx, spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"POST"},
+			Path:    "/api/v1/sendMessage",
+		},
+		HTTPResponse: &spec.HTTPResponse{
+			StatusCode: 201,
+		},
+		Callbacks: []spec.HTTPCallback{
+			{URL: webhookServer.URL + "/messages"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, httpID)
+
+	restID, err := c.AddRESTMock(ctx, spec.RESTMock{
+		RESTExpect: spec.RESTExpect{
+			BasePath: "/api/v1/teams",
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, restID)
+
+	mocks, err := c.Mocks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 3)
+
+	res, err := http.Post(mockServer.URL+"/api/v1/sendMessage", "application/json", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, res.StatusCode)
+	res.Body.Close()
+
+	requests, err := c.Journal(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "POST /api/v1/sendMessage", requests[0].Mock)
+
+	var deliveries []spec.CallbackDelivery
+	if assert.Eventually(t, func() bool {
+		deliveries, err = c.Callbacks(ctx)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, 10*time.Millisecond) {
+		assert.Equal(t, webhookServer.URL+"/messages", deliveries[0].URL)
+		assert.True(t, deliveries[0].Delivered)
+	}
+
+	result, err := c.Verify(ctx, spec.Verification{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"POST"},
+			Path:    "/api/v1/sendMessage",
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 1, result.Count)
+
+	err = c.ClearJournal(ctx)
+	assert.NoError(t, err)
+
+	requests, err = c.Journal(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, requests)
+
+	err = c.DeleteMock(ctx, httpID)
+	assert.NoError(t, err)
+
+	err = c.DeleteMock(ctx, httpID)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*ResponseError).StatusCode)
+
+	err = c.Reset(ctx)
+	assert.NoError(t, err)
+
+	mocks, err = c.Mocks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 1)
+
+	err = c.Reload(ctx)
+	assert.NoError(t, err)
+
+	mocks, err = c.Mocks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, mocks, 1)
+
+	_, err = c.Chaos(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*ResponseError).StatusCode)
+
+	err = c.SetChaos(ctx, true)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*ResponseError).StatusCode)
+}
@@ -4,15 +4,21 @@ import "time"
 
 // Global is the single global store for all configuration values
 var Global = struct {
-	Name        string `flag:"-" env:"-" file:"-"`
-	LogLevel    string
-	ControlPort uint16
-	SpecFile    string
-	GracePeriod time.Duration
+	Name          string `flag:"-" env:"-" file:"-"`
+	LogLevel      string
+	LogBodies     bool
+	LogBodyLimit  int
+	LogRedact     []string
+	ControlPort   uint16
+	SpecFile      string
+	SpecVars      []string
+	GracePeriod   time.Duration
+	TraceEndpoint string
 }{
-	Name:        "flax",
-	LogLevel:    "debug",
-	ControlPort: 9999,
-	SpecFile:    "flax.yaml",
-	GracePeriod: 30 * time.Second,
+	Name:         "flax",
+	LogLevel:     "debug",
+	LogBodyLimit: 1024,
+	ControlPort:  9999,
+	SpecFile:     "flax.yaml",
+	GracePeriod:  30 * time.Second,
 }
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/moorara/flax/cmd/config"
 	"github.com/moorara/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // HTTPServer is the interface for http.Server
@@ -20,20 +23,47 @@ type HTTPServer interface {
 
 // APIServer is an http server for mocked http endpoints.
 type APIServer struct {
+	name   string
 	logger log.Logger
 	server HTTPServer
 }
 
 // NewAPIServer creates an http mock server.
+// Mock servers also accept HTTP/2 without TLS (h2c), so they can serve gRPC mocks.
 func NewAPIServer(logger log.Logger, port uint16, handler http.Handler) *APIServer {
+	return newServer("http mock server", logger, port, h2c.NewHandler(handler, &http2.Server{}))
+}
+
+// NewListenerServer creates an http mock server for the mocks of a listener.
+func NewListenerServer(logger log.Logger, listener string, port uint16, handler http.Handler) *APIServer {
+	return newServer(fmt.Sprintf("http mock server for %s", listener), logger, port, h2c.NewHandler(handler, &http2.Server{}))
+}
+
+// NewControlServer creates an http server for the control api.
+func NewControlServer(logger log.Logger, port uint16, handler http.Handler) *APIServer {
+	return newServer("control server", logger, port, handler)
+}
+
+func newServer(name string, logger log.Logger, port uint16, handler http.Handler) *APIServer {
 	addr := fmt.Sprintf(":%d", port)
 
+	// The contexts of requests are canceled on shutdown, so long-lived responses (i.e. event streams) stop.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	server.RegisterOnShutdown(cancel)
+
 	return &APIServer{
+		name:   name,
 		logger: logger,
-		server: &http.Server{
-			Addr:    addr,
-			Handler: handler,
-		},
+		server: server,
 	}
 }
 
@@ -46,28 +76,28 @@ func (s *APIServer) Start() {
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 		sig := <-sigs
-		s.logger.Infof("http mock server received signal %s", sig.String())
+		s.logger.Infof("%s received signal %s", s.name, sig.String())
 
 		ctx, cancel := context.WithTimeout(context.Background(), config.Global.GracePeriod)
 		defer cancel()
 
 		err := s.server.Shutdown(ctx)
 		if err != nil {
-			s.logger.Errorf("http mock server failed to gracefully shutdown: %s", err)
+			s.logger.Errorf("%s failed to gracefully shutdown: %s", s.name, err)
 		} else {
-			s.logger.Info("http mock server was gracefully shutdown.")
+			s.logger.Infof("%s was gracefully shutdown.", s.name)
 		}
 
 		close(done)
 	}()
 
-	s.logger.Info("http mock server starting ...")
+	s.logger.Infof("%s starting ...", s.name)
 
 	// ListenAndServe always returns a non-nil error.
 	// After Shutdown or Close, the returned error is ErrServerClosed.
 	err := s.server.ListenAndServe()
 	if err != http.ErrServerClosed {
-		s.logger.Errorf("http mock server errored: %s", err)
+		s.logger.Errorf("%s errored: %s", s.name, err)
 	}
 
 	<-done
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/moorara/log"
 	"github.com/stretchr/testify/assert"
@@ -45,11 +46,82 @@ func TestNewAPIServer(t *testing.T) {
 
 			assert.NotNil(t, apiServer)
 			assert.NotNil(t, apiServer.server)
+			assert.Equal(t, "http mock server", apiServer.name)
 			assert.Equal(t, tc.logger, apiServer.logger)
 		})
 	}
 }
 
+func TestNewControlServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		logger  log.Logger
+		port    uint16
+		handler http.Handler
+	}{
+		{
+			"OK",
+			log.NewNopLogger(),
+			9999,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			controlServer := NewControlServer(tc.logger, tc.port, tc.handler)
+
+			assert.NotNil(t, controlServer)
+			assert.NotNil(t, controlServer.server)
+			assert.Equal(t, "control server", controlServer.name)
+			assert.Equal(t, tc.logger, controlServer.logger)
+		})
+	}
+}
+
+func TestNewListenerServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		logger   log.Logger
+		listener string
+		port     uint16
+		handler  http.Handler
+	}{
+		{
+			"OK",
+			log.NewNopLogger(),
+			"payments",
+			8081,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			listenerServer := NewListenerServer(tc.logger, tc.listener, tc.port, tc.handler)
+
+			assert.NotNil(t, listenerServer)
+			assert.NotNil(t, listenerServer.server)
+			assert.Equal(t, "http mock server for payments", listenerServer.name)
+			assert.Equal(t, tc.logger, listenerServer.logger)
+		})
+	}
+}
+
+func TestAPIServerShutdown(t *testing.T) {
+	apiServer := NewAPIServer(log.NewNopLogger(), 8080, http.NotFoundHandler())
+	server := apiServer.server.(*http.Server)
+	ctx := server.BaseContext(nil)
+
+	assert.NoError(t, server.Shutdown(context.Background()))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "request context not canceled on shutdown")
+	}
+}
+
 func TestAPIServerStart(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// ExporterOptions returns the options for an OTLP/HTTP exporter sending spans to a collector url.
+// The url path defaults to /v1/traces.
+func ExporterOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace endpoint: %s", err)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("trace endpoint must be an absolute url")
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+	}
+
+	switch u.Scheme {
+	case "http":
+		opts = append(opts, otlptracehttp.WithInsecure())
+	case "https":
+	default:
+		return nil, fmt.Errorf("unsupported trace endpoint scheme: %s", u.Scheme)
+	}
+
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+
+	return opts, nil
+}
+
+// Setup configures the global tracer provider and the global W3C trace context propagator.
+// If endpoint is empty, trace contexts are propagated but spans are not exported.
+// The returned function flushes the remaining spans and shuts down the tracer provider.
+func Setup(ctx context.Context, name, version, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts, err := ExporterOptions(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(name),
+		semconv.ServiceVersionKey.String(version),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
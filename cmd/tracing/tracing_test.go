@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestExporterOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      string
+		expectedLen   int
+		expectedError string
+	}{
+		{"HTTP", "http://localhost:4318", 2, ""},
+		{"HTTPS", "https://collector.example.com", 1, ""},
+		{"WithPath", "http://localhost:4318/otlp/v1/traces", 3, ""},
+		{"InvalidURL", "http://local host", 0, `invalid trace endpoint: parse "http://local host": invalid character " " in host name`},
+		{"RelativeURL", "localhost", 0, "trace endpoint must be an absolute url"},
+		{"InvalidScheme", "grpc://localhost:4317", 0, "unsupported trace endpoint scheme: grpc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := ExporterOptions(tc.endpoint)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Len(t, opts, tc.expectedLen)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, opts)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	var paths []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	tests := []struct {
+		name          string
+		endpoint      string
+		expectedPaths []string
+	}{
+		{"WithoutEndpoint", "", nil},
+		{"WithEndpoint", collector.URL, []string{"/v1/traces"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			paths = nil
+			ctx := context.Background()
+
+			shutdown, err := Setup(ctx, "flax", "0.1.0", tc.endpoint)
+			assert.NoError(t, err)
+			assert.NotNil(t, otel.GetTextMapPropagator())
+
+			_, span := otel.Tracer("test").Start(ctx, "test")
+			span.End()
+
+			err = shutdown(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPaths, paths)
+		})
+	}
+}
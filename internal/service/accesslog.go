@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/moorara/flax/spec"
+	"github.com/moorara/log"
+)
+
+const (
+	defaultMaxBodySize = 1024
+	redacted           = "[REDACTED]"
+
+	// redactMargin is the number of bytes captured past the max body size,
+	// so secrets crossing the max body size are still redacted before truncation.
+	redactMargin = 1024
+)
+
+type mockContextKey struct{}
+
+// withMock returns a new context carrying the mock matching a request.
+func withMock(ctx context.Context, mock string) context.Context {
+	return context.WithValue(ctx, mockContextKey{}, mock)
+}
+
+func mockFromContext(ctx context.Context) string {
+	mock, _ := ctx.Value(mockContextKey{}).(string)
+	return mock
+}
+
+// AccessLogOptions are the configurations for the access log.
+type AccessLogOptions struct {
+	// Bodies determines whether or not request and response bodies are logged.
+	Bodies bool
+	// MaxBodySize is the maximum number of bytes logged for a body.
+	// Longer bodies are truncated. The default is 1024.
+	MaxBodySize int
+	// Redact is a list of regular expressions for hiding sensitive data in bodies.
+	// If an expression has capture groups, only the groups are redacted.
+	Redact []string
+}
+
+// AccessLogger logs every request served by the mock server.
+type AccessLogger struct {
+	logger      log.Logger
+	bodies      bool
+	maxBodySize int
+	redact      []*regexp.Regexp
+}
+
+// NewAccessLogger creates a new instance of AccessLogger.
+func NewAccessLogger(logger log.Logger, opts AccessLogOptions) (*AccessLogger, error) {
+	a := &AccessLogger{
+		logger:      logger,
+		bodies:      opts.Bodies,
+		maxBodySize: opts.MaxBodySize,
+	}
+
+	if a.maxBodySize <= 0 {
+		a.maxBodySize = defaultMaxBodySize
+	}
+
+	for _, expr := range opts.Redact {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact expression %q: %s", expr, err)
+		}
+		a.redact = append(a.redact, re)
+	}
+
+	return a, nil
+}
+
+// sanitize redacts sensitive data in a body and truncates it.
+// truncated is the number of bytes already dropped from the end of the body while capturing it,
+// which only happens past the redact margin.
+// Redaction happens first, so a secret crossing the max body size cannot escape the redact expressions
+// unless it is longer than the redact margin.
+func (a *AccessLogger) sanitize(body []byte, truncated int) string {
+	for _, re := range a.redact {
+		body = redact(re, body)
+	}
+
+	if len(body) > a.maxBodySize {
+		truncated += len(body) - a.maxBodySize
+		body = body[:a.maxBodySize]
+	}
+
+	if truncated > 0 {
+		return fmt.Sprintf("%s... (%d bytes truncated)", body, truncated)
+	}
+
+	return string(body)
+}
+
+// redact replaces the matches of a regular expression, or only their capture groups if any, in a body.
+func redact(re *regexp.Regexp, body []byte) []byte {
+	var buf bytes.Buffer
+	last := 0
+
+	for _, loc := range re.FindAllSubmatchIndex(body, -1) {
+		if len(loc) == 2 {
+			buf.Write(body[last:loc[0]])
+			buf.WriteString(redacted)
+			last = loc[1]
+			continue
+		}
+
+		for i := 2; i < len(loc); i += 2 {
+			if loc[i] < 0 || loc[i] < last {
+				continue
+			}
+			buf.Write(body[last:loc[i]])
+			buf.WriteString(redacted)
+			last = loc[i+1]
+		}
+	}
+
+	buf.Write(body[last:])
+
+	return buf.Bytes()
+}
+
+// Handler wraps an http handler and logs every request served by it.
+func (a *AccessLogger) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var reqBody []byte
+		if a.bodies && r.Body != nil && !spec.IsGRPC(r) {
+			reqBody, _ = ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+		}
+
+		rw := &accessLogWriter{
+			responseWriter: newResponseWriter(w),
+			capture:        a.bodies,
+			limit:          a.maxBodySize + redactMargin,
+		}
+
+		// Requests aborted by panicking (i.e. dropped by chaos) are logged too, and then the panic continues.
+		defer func() {
+			aborted := recover()
+
+			kv := []interface{}{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"mock", mockFromContext(r.Context()),
+				"status", rw.statusCode,
+				"duration", time.Since(start).String(),
+				"bytes", rw.bytes,
+			}
+
+			if aborted != nil {
+				kv = append(kv, "aborted", true)
+			}
+
+			if a.bodies {
+				kv = append(kv,
+					"request.body", a.sanitize(reqBody, 0),
+					"response.body", a.sanitize(rw.body.Bytes(), rw.truncated),
+				)
+			}
+
+			a.logger.Info("request served", kv...)
+
+			if aborted != nil {
+				panic(aborted)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// accessLogWriter captures the response body in addition to the status code and the number of bytes written.
+// Only up to limit bytes are captured, so long or endless responses (i.e. streams) do not grow the memory.
+// The limit is the max body size plus the redact margin.
+type accessLogWriter struct {
+	*responseWriter
+	capture   bool
+	limit     int
+	body      bytes.Buffer
+	truncated int
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	n, err := w.responseWriter.Write(b)
+	if w.capture {
+		keep := n
+		if room := w.limit - w.body.Len(); keep > room {
+			keep = room
+		}
+		w.body.Write(b[:keep])
+		w.truncated += n - keep
+	}
+	return n, err
+}
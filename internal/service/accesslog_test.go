@@ -0,0 +1,255 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/moorara/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	message string
+	kv      map[string]interface{}
+}
+
+// mockLogger records info logs.
+type mockLogger struct {
+	log.Logger
+	entries []logEntry
+}
+
+func (l *mockLogger) Info(message string, kv ...interface{}) {
+	entry := logEntry{
+		message: message,
+		kv:      map[string]interface{}{},
+	}
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		entry.kv[kv[i].(string)] = kv[i+1]
+	}
+
+	l.entries = append(l.entries, entry)
+}
+
+func TestNewAccessLogger(t *testing.T) {
+	tests := []struct {
+		name                string
+		opts                AccessLogOptions
+		expectedMaxBodySize int
+		expectedError       string
+	}{
+		{
+			name:                "Defaults",
+			opts:                AccessLogOptions{},
+			expectedMaxBodySize: 1024,
+		},
+		{
+			name: "OK",
+			opts: AccessLogOptions{
+				Bodies:      true,
+				MaxBodySize: 64,
+				Redact:      []string{`"password":"([^"]*)"`},
+			},
+			expectedMaxBodySize: 64,
+		},
+		{
+			name: "InvalidRedact",
+			opts: AccessLogOptions{
+				Redact: []string{`(`},
+			},
+			expectedError: "invalid redact expression \"(\": error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewAccessLogger(log.NewNopLogger(), tc.opts)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.opts.Bodies, a.bodies)
+				assert.Equal(t, tc.expectedMaxBodySize, a.maxBodySize)
+				assert.Len(t, a.redact, len(tc.opts.Redact))
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, a)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		body         string
+		expectedBody string
+	}{
+		{
+			name:         "NoMatch",
+			expr:         `secret`,
+			body:         `{"name":"jane"}`,
+			expectedBody: `{"name":"jane"}`,
+		},
+		{
+			name:         "Match",
+			expr:         `Bearer [A-Za-z0-9.]+`,
+			body:         `token=Bearer abc.def and Bearer xyz`,
+			expectedBody: `token=[REDACTED] and [REDACTED]`,
+		},
+		{
+			name:         "Groups",
+			expr:         `"(password|token)":"([^"]*)"`,
+			body:         `{"user":"jane","password":"secret","token":"abc"}`,
+			expectedBody: `{"user":"jane","[REDACTED]":"[REDACTED]","[REDACTED]":"[REDACTED]"}`,
+		},
+		{
+			name:         "SingleGroup",
+			expr:         `"password":"([^"]*)"`,
+			body:         `{"user":"jane","password":"secret"}`,
+			expectedBody: `{"user":"jane","password":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := redact(regexp.MustCompile(tc.expr), []byte(tc.body))
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestAccessLogWriter(t *testing.T) {
+	w := &accessLogWriter{
+		responseWriter: newResponseWriter(httptest.NewRecorder()),
+		capture:        true,
+		limit:          8,
+	}
+
+	for _, chunk := range []string{"data: 1\n", "\n", "data: 2\n\n"} {
+		n, err := w.Write([]byte(chunk))
+		assert.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, "data: 1\n", w.body.String())
+	assert.Equal(t, 10, w.truncated)
+	assert.Equal(t, 18, w.bytes)
+}
+
+func TestAccessLoggerHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            AccessLogOptions
+		mock            string
+		reqBody         string
+		resStatusCode   int
+		resBody         string
+		expectedEntries map[string]interface{}
+	}{
+		{
+			name:          "WithoutBodies",
+			opts:          AccessLogOptions{},
+			mock:          "POST /api/v1/login",
+			reqBody:       `{"user":"jane","password":"secret"}`,
+			resStatusCode: 200,
+			resBody:       `{"token":"abc"}`,
+			expectedEntries: map[string]interface{}{
+				"method": "POST",
+				"path":   "/api/v1/login",
+				"mock":   "POST /api/v1/login",
+				"status": 200,
+				"bytes":  15,
+			},
+		},
+		{
+			name: "WithBodies",
+			opts: AccessLogOptions{
+				Bodies:      true,
+				MaxBodySize: 12,
+				Redact:      []string{`"password":"([^"]*)"`},
+			},
+			mock:          "",
+			reqBody:       `{"password":"secret"}`,
+			resStatusCode: 404,
+			resBody:       `404 page not found`,
+			expectedEntries: map[string]interface{}{
+				"method":        "POST",
+				"path":          "/api/v1/login",
+				"mock":          "",
+				"status":        404,
+				"bytes":         18,
+				"request.body":  `{"password":... (13 bytes truncated)`,
+				"response.body": `404 page not... (6 bytes truncated)`,
+			},
+		},
+		{
+			name: "SecretCrossingMaxBodySize",
+			opts: AccessLogOptions{
+				Bodies:      true,
+				MaxBodySize: 12,
+				Redact:      []string{`"token":"([^"]*)"`},
+			},
+			mock:          "POST /api/v1/login",
+			reqBody:       "",
+			resStatusCode: 200,
+			resBody:       `{"token":"abcdefghijkl"}`,
+			expectedEntries: map[string]interface{}{
+				"bytes":         24,
+				"response.body": `{"token":"[R... (10 bytes truncated)`,
+			},
+		},
+		{
+			name: "Redacted",
+			opts: AccessLogOptions{
+				Bodies: true,
+				Redact: []string{`"password":"([^"]*)"`},
+			},
+			mock:          "POST /api/v1/login",
+			reqBody:       `{"password":"secret"}`,
+			resStatusCode: 201,
+			resBody:       "",
+			expectedEntries: map[string]interface{}{
+				"status":        201,
+				"bytes":         0,
+				"request.body":  `{"password":"[REDACTED]"}`,
+				"response.body": "",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &mockLogger{}
+			a, err := NewAccessLogger(logger, tc.opts)
+			assert.NoError(t, err)
+
+			handler := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.resStatusCode)
+				_, _ = w.Write([]byte(tc.resBody))
+			}))
+
+			req := httptest.NewRequest("POST", "/api/v1/login", strings.NewReader(tc.reqBody))
+			req = req.WithContext(withMock(req.Context(), tc.mock))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.resStatusCode, res.Result().StatusCode)
+			assert.Equal(t, tc.resBody, res.Body.String())
+
+			assert.Len(t, logger.entries, 1)
+			assert.Equal(t, "request served", logger.entries[0].message)
+			assert.NotEmpty(t, logger.entries[0].kv["duration"])
+			for key, val := range tc.expectedEntries {
+				assert.Equal(t, val, logger.entries[0].kv[key], key)
+			}
+			if !tc.opts.Bodies {
+				assert.NotContains(t, logger.entries[0].kv, "request.body")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/moorara/flax/spec"
+	"github.com/moorara/log"
+)
+
+// SpecReader reads a spec from its source.
+type SpecReader func() (*spec.Spec, error)
+
+// ControlService provides the control api for managing mocks at runtime.
+type ControlService struct {
+	mutex  sync.Mutex
+	logger log.Logger
+	read   SpecReader
+	spec   *spec.Spec
+	mocks  *MockService
+}
+
+// NewControlService creates a new instance of ControlService.
+// The given spec is used for restoring the mocks on reset.
+// The spec reader is used for reloading the spec.
+func NewControlService(logger log.Logger, read SpecReader, s *spec.Spec, mocks *MockService) *ControlService {
+	return &ControlService{
+		logger: logger,
+		read:   read,
+		spec:   s,
+		mocks:  mocks,
+	}
+}
+
+func (c *ControlService) currentSpec() *spec.Spec {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.spec
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, spec.JSON{
+		"message": err.Error(),
+	})
+}
+
+func mockID(m Mock) string {
+	return strconv.FormatUint(m.Hash(), 16)
+}
+
+// Router creates a new router for the control api.
+func (c *ControlService) Router() *mux.Router {
+	router := mux.NewRouter()
+
+	router.Methods("GET").Path("/health").HandlerFunc(c.health)
+	router.Methods("GET").Path("/metrics").Handler(c.mocks.Metrics().Handler())
+	router.Methods("GET").Path("/mocks").HandlerFunc(c.listMocks)
+	router.Methods("POST").Path("/mocks/http").HandlerFunc(c.addHTTPMock)
+	router.Methods("POST").Path("/mocks/rest").HandlerFunc(c.addRESTMock)
+	router.Methods("DELETE").Path("/mocks/{id:[0-9a-f]+}").HandlerFunc(c.deleteMock)
+	router.Methods("POST").Path("/reset").HandlerFunc(c.reset)
+	router.Methods("POST").Path("/reload").HandlerFunc(c.reload)
+	router.Methods("GET").Path("/journal").HandlerFunc(c.getJournal)
+	router.Methods("DELETE").Path("/journal").HandlerFunc(c.clearJournal)
+	router.Methods("POST").Path("/verify").HandlerFunc(c.verify)
+	router.Methods("GET").Path("/callbacks").HandlerFunc(c.getCallbacks)
+	router.Methods("GET").Path("/chaos").HandlerFunc(c.getChaos)
+	router.Methods("PUT").Path("/chaos").HandlerFunc(c.setChaos)
+
+	return router
+}
+
+func (c *ControlService) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, spec.JSON{
+		"status": "ok",
+	})
+}
+
+func (c *ControlService) listMocks(w http.ResponseWriter, r *http.Request) {
+	list := []spec.MockInfo{}
+	for _, m := range c.mocks.Mocks() {
+		list = append(list, spec.MockInfo{
+			ID:   mockID(m),
+			Mock: m.String(),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Mock < list[j].Mock
+	})
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (c *ControlService) addMock(w http.ResponseWriter, m Mock) {
+	c.mocks.Add(m)
+
+	writeJSON(w, http.StatusCreated, spec.MockInfo{
+		ID:   mockID(m),
+		Mock: m.String(),
+	})
+}
+
+func (c *ControlService) addHTTPMock(w http.ResponseWriter, r *http.Request) {
+	m := new(spec.HTTPMock)
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid http mock: %s", err))
+		return
+	}
+
+	config := c.currentSpec().Config
+
+	m.SetDefaults()
+	m.Inherit(config)
+	if err := m.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid http mock: %s", err))
+		return
+	}
+
+	if !config.HasListener(m.HTTPExpect.Listener) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid http mock: unknown listener %s", m.HTTPExpect.Listener))
+		return
+	}
+
+	c.addMock(w, m)
+}
+
+func (c *ControlService) addRESTMock(w http.ResponseWriter, r *http.Request) {
+	m := new(spec.RESTMock)
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rest mock: %s", err))
+		return
+	}
+
+	config := c.currentSpec().Config
+
+	m.SetDefaults()
+	m.Inherit(config)
+	m.RESTStore.Index()
+	if err := m.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rest mock: %s", err))
+		return
+	}
+
+	if !config.HasListener(m.RESTExpect.Listener) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rest mock: unknown listener %s", m.RESTExpect.Listener))
+		return
+	}
+
+	c.addMock(w, m)
+}
+
+func (c *ControlService) deleteMock(w http.ResponseWriter, r *http.Request) {
+	key, err := strconv.ParseUint(mux.Vars(r)["id"], 16, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid mock id: %s", err))
+		return
+	}
+
+	if !c.mocks.DeleteByHash(key) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("mock %x not found", key))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ControlService) reset(w http.ResponseWriter, r *http.Request) {
+	c.mocks.Reset()
+	c.mocks.Load(c.currentSpec())
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ControlService) reload(w http.ResponseWriter, r *http.Request) {
+	s, err := c.read()
+	c.mocks.Metrics().ObserveSpecReload(err)
+
+	if err != nil {
+		c.logger.Error("spec reload failed", "error", err)
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("cannot reload spec: %s", err))
+		return
+	}
+
+	c.mutex.Lock()
+	c.spec = s
+	c.mutex.Unlock()
+
+	c.mocks.Replace(s)
+	c.logger.Info("spec reloaded")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ControlService) getJournal(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.mocks.Journal().Requests())
+}
+
+func (c *ControlService) clearJournal(w http.ResponseWriter, r *http.Request) {
+	c.mocks.Journal().Clear()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ControlService) getCallbacks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.mocks.Journal().Deliveries())
+}
+
+func (c *ControlService) verify(w http.ResponseWriter, r *http.Request) {
+	v := new(spec.Verification)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid verification: %s", err))
+		return
+	}
+
+	if err := v.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid verification: %s", err))
+		return
+	}
+
+	v.SetDefaults()
+	result := v.Verify(c.mocks.Journal().Requests())
+
+	// In strict mode, requests not matching any mock fail every verification.
+	if failures := c.mocks.Journal().Failures(); len(failures) > 0 {
+		result.Verified = false
+		result.Failures = failures
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (c *ControlService) getChaos(w http.ResponseWriter, r *http.Request) {
+	chaos := c.currentSpec().Config.Chaos
+	if chaos == nil {
+		writeError(w, http.StatusNotFound, errors.New("chaos is not configured"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, spec.ChaosStatus{
+		Enabled: chaos.Enabled(),
+	})
+}
+
+func (c *ControlService) setChaos(w http.ResponseWriter, r *http.Request) {
+	chaos := c.currentSpec().Config.Chaos
+	if chaos == nil {
+		writeError(w, http.StatusNotFound, errors.New("chaos is not configured"))
+		return
+	}
+
+	status := new(spec.ChaosStatus)
+	if err := json.NewDecoder(r.Body).Decode(status); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid chaos status: %s", err))
+		return
+	}
+
+	chaos.SetEnabled(status.Enabled)
+	c.logger.Info("chaos toggled", "enabled", status.Enabled)
+
+	writeJSON(w, http.StatusOK, spec.ChaosStatus{
+		Enabled: chaos.Enabled(),
+	})
+}
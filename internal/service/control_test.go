@@ -0,0 +1,418 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moorara/flax/spec"
+	"github.com/moorara/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewControlService(t *testing.T) {
+	tests := []struct {
+		name   string
+		logger log.Logger
+		read   SpecReader
+		spec   *spec.Spec
+		mocks  *MockService
+	}{
+		{
+			name:   "OK",
+			logger: log.NewNopLogger(),
+			read:   func() (*spec.Spec, error) { return spec.DefaultSpec(), nil },
+			spec:   spec.DefaultSpec(),
+			mocks:  NewMockService(log.NewNopLogger()),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewControlService(tc.logger, tc.read, tc.spec, tc.mocks)
+
+			assert.NotNil(t, service)
+			assert.Equal(t, tc.logger, service.logger)
+			assert.NotNil(t, service.read)
+			assert.Equal(t, tc.spec, service.spec)
+			assert.Equal(t, tc.mocks, service.mocks)
+		})
+	}
+}
+
+func TestControlServiceRouter(t *testing.T) {
+	tests := []struct {
+		name               string
+		reqMethod          string
+		reqPath            string
+		reqBody            string
+		expectedStatusCode int
+		expectedBody       string
+		expectedMocks      int
+	}{
+		{
+			name:               "Health",
+			reqMethod:          "GET",
+			reqPath:            "/health",
+			expectedStatusCode: 200,
+			expectedBody:       `{"status":"ok"}`,
+			expectedMocks:      1,
+		},
+		{
+			name:               "ListMocks",
+			reqMethod:          "GET",
+			reqPath:            "/mocks",
+			expectedStatusCode: 200,
+			expectedBody:       `[{"id":"` + mockID(httpMock) + `","mock":"GET /health"}]`,
+			expectedMocks:      1,
+		},
+		{
+			name:               "AddHTTPMock",
+			reqMethod:          "POST",
+			reqPath:            "/mocks/http",
+			reqBody:            `{"methods":["POST"],"path":"/api/v1/sendMessage","response":{"status":201}}`,
+			expectedStatusCode: 201,
+			expectedMocks:      2,
+		},
+		{
+			name:               "AddInvalidHTTPMock",
+			reqMethod:          "POST",
+			reqPath:            "/mocks/http",
+			reqBody:            `[]`,
+			expectedStatusCode: 400,
+			expectedMocks:      1,
+		},
+		{
+			name:               "AddRESTMock",
+			reqMethod:          "POST",
+			reqPath:            "/mocks/rest",
+			reqBody:            `{"basePath":"/api/v1/teams"}`,
+			expectedStatusCode: 201,
+			expectedMocks:      2,
+		},
+		{
+			name:               "AddInvalidRESTMock",
+			reqMethod:          "POST",
+			reqPath:            "/mocks/rest",
+			reqBody:            `[]`,
+			expectedStatusCode: 400,
+			expectedMocks:      1,
+		},
+		{
+			name:               "DeleteMock",
+			reqMethod:          "DELETE",
+			reqPath:            "/mocks/" + mockID(httpMock),
+			expectedStatusCode: 204,
+			expectedMocks:      0,
+		},
+		{
+			name:               "DeleteMissingMock",
+			reqMethod:          "DELETE",
+			reqPath:            "/mocks/abcdef",
+			expectedStatusCode: 404,
+			expectedMocks:      1,
+		},
+		{
+			name:               "Reset",
+			reqMethod:          "POST",
+			reqPath:            "/reset",
+			expectedStatusCode: 204,
+			expectedMocks:      1,
+		},
+		{
+			name:               "GetJournal",
+			reqMethod:          "GET",
+			reqPath:            "/journal",
+			expectedStatusCode: 200,
+			expectedBody:       `[]`,
+			expectedMocks:      1,
+		},
+		{
+			name:               "ClearJournal",
+			reqMethod:          "DELETE",
+			reqPath:            "/journal",
+			expectedStatusCode: 204,
+			expectedMocks:      1,
+		},
+		{
+			name:               "GetCallbacks",
+			reqMethod:          "GET",
+			reqPath:            "/callbacks",
+			expectedStatusCode: 200,
+			expectedBody:       `[]`,
+			expectedMocks:      1,
+		},
+		{
+			name:               "Verify",
+			reqMethod:          "POST",
+			reqPath:            "/verify",
+			reqBody:            `{"path":"/health"}`,
+			expectedStatusCode: 200,
+			expectedBody:       `{"verified":false,"count":0,"requests":[]}`,
+			expectedMocks:      1,
+		},
+		{
+			name:               "InvalidVerify",
+			reqMethod:          "POST",
+			reqPath:            "/verify",
+			reqBody:            `[]`,
+			expectedStatusCode: 400,
+			expectedMocks:      1,
+		},
+		{
+			name:               "InvalidVerifyPattern",
+			reqMethod:          "POST",
+			reqPath:            "/verify",
+			reqBody:            `{"path":"/health","headers":{"Accept":"("}}`,
+			expectedStatusCode: 400,
+			expectedMocks:      1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec.Spec{
+				HTTPMocks: []spec.HTTPMock{*httpMock},
+			}
+
+			mocks := NewMockService(log.NewNopLogger())
+			mocks.Load(s)
+
+			read := func() (*spec.Spec, error) { return s, nil }
+			service := NewControlService(log.NewNopLogger(), read, s, mocks)
+			router := service.Router()
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, strings.NewReader(tc.reqBody))
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Len(t, mocks.Mocks(), tc.expectedMocks)
+
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, res.Body.String())
+			} else if res.Body.Len() > 0 {
+				assert.True(t, json.Valid(res.Body.Bytes()))
+			}
+		})
+	}
+}
+
+func TestControlServiceReload(t *testing.T) {
+	tests := []struct {
+		name               string
+		read               SpecReader
+		expectedStatusCode int
+		expectedMocks      int
+		expectedMetric     string
+	}{
+		{
+			name: "Success",
+			read: func() (*spec.Spec, error) {
+				return &spec.Spec{
+					HTTPMocks: []spec.HTTPMock{*httpMock},
+					RESTMocks: []spec.RESTMock{*restMock},
+				}, nil
+			},
+			expectedStatusCode: 204,
+			expectedMocks:      2,
+			expectedMetric:     `flax_spec_reloads_total{result="success"} 1`,
+		},
+		{
+			name: "Failure",
+			read: func() (*spec.Spec, error) {
+				return nil, errors.New("invalid spec")
+			},
+			expectedStatusCode: 422,
+			expectedMocks:      1,
+			expectedMetric:     `flax_spec_reloads_total{result="failure"} 1`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec.Spec{
+				HTTPMocks: []spec.HTTPMock{*httpMock},
+			}
+
+			mocks := NewMockService(log.NewNopLogger())
+			mocks.Load(s)
+
+			service := NewControlService(log.NewNopLogger(), tc.read, s, mocks)
+			router := service.Router()
+
+			req := httptest.NewRequest("POST", "/reload", nil)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Len(t, mocks.Mocks(), tc.expectedMocks)
+
+			req = httptest.NewRequest("GET", "/metrics", nil)
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, 200, res.Result().StatusCode)
+			assert.Contains(t, res.Body.String(), tc.expectedMetric)
+		})
+	}
+}
+
+func TestControlServiceVerifyStrict(t *testing.T) {
+	s := &spec.Spec{
+		Config: spec.Config{
+			Fallback: &spec.Fallback{Strict: true},
+		},
+		HTTPMocks: []spec.HTTPMock{*httpMock},
+	}
+
+	mocks := NewMockService(log.NewNopLogger())
+	mocks.Load(s)
+
+	read := func() (*spec.Spec, error) { return s, nil }
+	router := NewControlService(log.NewNopLogger(), read, s, mocks).Router()
+
+	verify := func() spec.VerificationResult {
+		req := httptest.NewRequest("POST", "/verify", strings.NewReader(`{"path":"/health"}`))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Result().StatusCode)
+
+		result := spec.VerificationResult{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		return result
+	}
+
+	mocks.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+	result := verify()
+	assert.True(t, result.Verified)
+	assert.Empty(t, result.Failures)
+
+	mocks.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	result = verify()
+	assert.False(t, result.Verified)
+	assert.Equal(t, 1, result.Count)
+	assert.Len(t, result.Failures, 1)
+	assert.Equal(t, "/healthz", result.Failures[0].Path)
+}
+
+func TestControlServiceAddMockListener(t *testing.T) {
+	s := &spec.Spec{
+		Config: spec.Config{
+			Listeners: []spec.Listener{
+				{Name: "payments", Port: 8081},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		path               string
+		body               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "HTTPMock",
+			path:               "/mocks/http",
+			body:               `{"listener":"payments","path":"/health"}`,
+			expectedStatusCode: 201,
+			expectedBody:       `"mock":"payments: GET /health"`,
+		},
+		{
+			name:               "HTTPMockUnknownListener",
+			path:               "/mocks/http",
+			body:               `{"listener":"identity","path":"/health"}`,
+			expectedStatusCode: 400,
+			expectedBody:       `"message":"invalid http mock: unknown listener identity"`,
+		},
+		{
+			name:               "RESTMockUnknownListener",
+			path:               "/mocks/rest",
+			body:               `{"listener":"identity","basePath":"/api/v1/teams"}`,
+			expectedStatusCode: 400,
+			expectedBody:       `"message":"invalid rest mock: unknown listener identity"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mocks := NewMockService(log.NewNopLogger())
+			read := func() (*spec.Spec, error) { return s, nil }
+			router := NewControlService(log.NewNopLogger(), read, s, mocks).Router()
+
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Contains(t, res.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestControlServiceChaos(t *testing.T) {
+	chaos := &spec.Chaos{
+		Errors: &spec.ChaosErrors{Percent: 100, StatusCodes: []int{503}},
+	}
+	chaos.SetDefaults()
+
+	mock := *httpMock
+	mock.Inherit(spec.Config{Chaos: chaos})
+
+	s := &spec.Spec{
+		Config: spec.Config{
+			Chaos: chaos,
+		},
+		HTTPMocks: []spec.HTTPMock{mock},
+	}
+
+	mocks := NewMockService(log.NewNopLogger())
+	mocks.Load(s)
+
+	read := func() (*spec.Spec, error) { return s, nil }
+	router := NewControlService(log.NewNopLogger(), read, s, mocks).Router()
+
+	call := func(method, body string) string {
+		req := httptest.NewRequest(method, "/chaos", strings.NewReader(body))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Result().StatusCode)
+		return res.Body.String()
+	}
+
+	serve := func() int {
+		res := httptest.NewRecorder()
+		mocks.ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+		return res.Result().StatusCode
+	}
+
+	assert.Contains(t, call("GET", ""), `"enabled":true`)
+	assert.Equal(t, 503, serve())
+
+	assert.Contains(t, call("PUT", `{"enabled":false}`), `"enabled":false`)
+	assert.Equal(t, 200, serve())
+
+	assert.Contains(t, call("PUT", `{"enabled":true}`), `"enabled":true`)
+	assert.Equal(t, 503, serve())
+}
+
+func TestControlServiceChaosNotConfigured(t *testing.T) {
+	s := &spec.Spec{}
+	mocks := NewMockService(log.NewNopLogger())
+	read := func() (*spec.Spec, error) { return s, nil }
+	router := NewControlService(log.NewNopLogger(), read, s, mocks).Router()
+
+	for _, method := range []string{"GET", "PUT"} {
+		req := httptest.NewRequest(method, "/chaos", strings.NewReader(`{"enabled":true}`))
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 404, res.Result().StatusCode)
+		assert.Contains(t, res.Body.String(), `"message":"chaos is not configured"`)
+	}
+}
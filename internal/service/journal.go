@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/moorara/flax/spec"
+)
+
+// Journal keeps a record of all requests received by the mock server.
+// It also keeps a record of requests failing verifications regardless of expectations,
+// and a record of callbacks sent by mocks.
+type Journal struct {
+	mutex      sync.Mutex
+	requests   []spec.Request
+	failures   []spec.Request
+	deliveries []spec.CallbackDelivery
+}
+
+// NewJournal creates a new instance of Journal.
+func NewJournal() *Journal {
+	return &Journal{
+		requests:   []spec.Request{},
+		failures:   []spec.Request{},
+		deliveries: []spec.CallbackDelivery{},
+	}
+}
+
+// Record appends a request to the journal.
+func (j *Journal) Record(r spec.Request) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.requests = append(j.requests, r)
+}
+
+// Requests returns a copy of all recorded requests.
+func (j *Journal) Requests() []spec.Request {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	requests := make([]spec.Request, len(j.requests))
+	copy(requests, j.requests)
+
+	return requests
+}
+
+// RecordFailure records a request failing all verifications.
+func (j *Journal) RecordFailure(r spec.Request) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.failures = append(j.failures, r)
+}
+
+// Failures returns a copy of all requests failing verifications.
+func (j *Journal) Failures() []spec.Request {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	failures := make([]spec.Request, len(j.failures))
+	copy(failures, j.failures)
+
+	return failures
+}
+
+// RecordDelivery records a callback sent by a mock.
+func (j *Journal) RecordDelivery(d spec.CallbackDelivery) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.deliveries = append(j.deliveries, d)
+}
+
+// Deliveries returns a copy of all recorded callbacks.
+func (j *Journal) Deliveries() []spec.CallbackDelivery {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	deliveries := make([]spec.CallbackDelivery, len(j.deliveries))
+	copy(deliveries, j.deliveries)
+
+	return deliveries
+}
+
+// Clear removes all recorded requests, failures, and callbacks.
+func (j *Journal) Clear() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.requests = []spec.Request{}
+	j.failures = []spec.Request{}
+	j.deliveries = []spec.CallbackDelivery{}
+}
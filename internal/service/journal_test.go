@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/moorara/flax/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	tests := []struct {
+		name       string
+		requests   []spec.Request
+		failures   []spec.Request
+		deliveries []spec.CallbackDelivery
+	}{
+		{
+			name:       "Empty",
+			requests:   []spec.Request{},
+			failures:   []spec.Request{},
+			deliveries: []spec.CallbackDelivery{},
+		},
+		{
+			name: "OK",
+			requests: []spec.Request{
+				{Method: "GET", Path: "/health"},
+				{Method: "POST", Path: "/api/v1/sendMessage", Body: `{"message":"hello"}`},
+			},
+			failures: []spec.Request{
+				{Method: "POST", Path: "/api/v1/sendMessage", Body: `{"message":"hello"}`},
+			},
+			deliveries: []spec.CallbackDelivery{
+				{Method: "POST", URL: "http://localhost:9000/webhooks", Attempts: 1, StatusCode: 200, Delivered: true},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			journal := NewJournal()
+
+			for _, r := range tc.requests {
+				journal.Record(r)
+			}
+			assert.Equal(t, tc.requests, journal.Requests())
+
+			for _, r := range tc.failures {
+				journal.RecordFailure(r)
+			}
+			assert.Equal(t, tc.failures, journal.Failures())
+
+			for _, d := range tc.deliveries {
+				journal.RecordDelivery(d)
+			}
+			assert.Equal(t, tc.deliveries, journal.Deliveries())
+
+			journal.Clear()
+			assert.Empty(t, journal.Requests())
+			assert.Empty(t, journal.Failures())
+			assert.Empty(t, journal.Deliveries())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics has the Prometheus metrics for the mock server.
+type Metrics struct {
+	registry          *prometheus.Registry
+	requestsTotal     *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	unmatchedRequests *prometheus.CounterVec
+	proxyErrors       *prometheus.CounterVec
+	specReloads       *prometheus.CounterVec
+}
+
+// NewMetrics creates a new instance of Metrics with its own registry.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "flax",
+				Name:      "requests_total",
+				Help:      "The total number of requests received by the mock server.",
+			},
+			[]string{"mock", "method", "status"},
+		),
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "flax",
+				Name:      "request_duration_seconds",
+				Help:      "The duration of requests served by the mock server.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"mock", "method", "status"},
+		),
+		unmatchedRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "flax",
+				Name:      "unmatched_requests_total",
+				Help:      "The total number of requests not matching any mock.",
+			},
+			[]string{"method"},
+		),
+		proxyErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "flax",
+				Name:      "proxy_errors_total",
+				Help:      "The total number of errors from upstream servers for forwarded requests.",
+			},
+			[]string{"mock"},
+		),
+		specReloads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "flax",
+				Name:      "spec_reloads_total",
+				Help:      "The total number of spec reloads.",
+			},
+			[]string{"result"},
+		),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requestsTotal,
+		m.requestDuration,
+		m.unmatchedRequests,
+		m.proxyErrors,
+		m.specReloads,
+	)
+
+	return m
+}
+
+// Handler returns an http handler for exposing the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a request served by the mock server.
+// mock is empty if the request did not match any mock.
+func (m *Metrics) ObserveRequest(mock, method string, statusCode int, seconds float64) {
+	status := strconv.Itoa(statusCode)
+	m.requestsTotal.WithLabelValues(mock, method, status).Inc()
+	m.requestDuration.WithLabelValues(mock, method, status).Observe(seconds)
+
+	if mock == "" {
+		m.unmatchedRequests.WithLabelValues(method).Inc()
+	}
+}
+
+// ObserveProxyError records an error from an upstream server.
+func (m *Metrics) ObserveProxyError(mock string) {
+	m.proxyErrors.WithLabelValues(mock).Inc()
+}
+
+// ObserveSpecReload records a spec reload.
+func (m *Metrics) ObserveSpecReload(err error) {
+	if err != nil {
+		m.specReloads.WithLabelValues("failure").Inc()
+	} else {
+		m.specReloads.WithLabelValues("success").Inc()
+	}
+}
+
+// responseWriter wraps an http.ResponseWriter for capturing the status code and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements the http.Flusher interface.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements the http.Hijacker interface.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking is not supported")
+	}
+
+	return hj.Hijack()
+}
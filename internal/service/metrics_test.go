@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name            string
+		observe         func(*Metrics)
+		expectedMetrics []string
+	}{
+		{
+			name: "MatchedRequest",
+			observe: func(m *Metrics) {
+				m.ObserveRequest("GET /health", "GET", 200, 0.01)
+			},
+			expectedMetrics: []string{
+				`flax_requests_total{method="GET",mock="GET /health",status="200"} 1`,
+				`flax_request_duration_seconds_count{method="GET",mock="GET /health",status="200"} 1`,
+			},
+		},
+		{
+			name: "UnmatchedRequest",
+			observe: func(m *Metrics) {
+				m.ObserveRequest("", "POST", 404, 0.01)
+			},
+			expectedMetrics: []string{
+				`flax_requests_total{method="POST",mock="",status="404"} 1`,
+				`flax_unmatched_requests_total{method="POST"} 1`,
+			},
+		},
+		{
+			name: "ProxyError",
+			observe: func(m *Metrics) {
+				m.ObserveProxyError("GET /api")
+				m.ObserveProxyError("GET /api")
+			},
+			expectedMetrics: []string{
+				`flax_proxy_errors_total{mock="GET /api"} 2`,
+			},
+		},
+		{
+			name: "SpecReload",
+			observe: func(m *Metrics) {
+				m.ObserveSpecReload(nil)
+				m.ObserveSpecReload(errors.New("invalid spec"))
+			},
+			expectedMetrics: []string{
+				`flax_spec_reloads_total{result="success"} 1`,
+				`flax_spec_reloads_total{result="failure"} 1`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMetrics()
+			tc.observe(m)
+
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			res := httptest.NewRecorder()
+			m.Handler().ServeHTTP(res, req)
+
+			assert.Equal(t, 200, res.Result().StatusCode)
+			for _, metric := range tc.expectedMetrics {
+				assert.Contains(t, res.Body.String(), metric)
+			}
+		})
+	}
+}
+
+func TestResponseWriter(t *testing.T) {
+	res := httptest.NewRecorder()
+	w := newResponseWriter(res)
+
+	w.WriteHeader(201)
+	n, err := w.Write([]byte("created"))
+	w.Flush()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+	assert.Equal(t, 201, w.statusCode)
+	assert.Equal(t, 7, w.bytes)
+	assert.True(t, res.Flushed)
+}
@@ -1,7 +1,17 @@
 package service
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/gorilla/mux"
+	"github.com/moorara/flax/spec"
 	"github.com/moorara/log"
 )
 
@@ -10,45 +20,341 @@ type Mock interface {
 	String() string
 	Hash() uint64
 	RegisterRoutes(*mux.Router)
+	Diagnose(spec.Request) spec.Diagnosis
+	Rank() spec.Rank
 }
 
 // MockService provides functionalities to manage mocks.
 type MockService struct {
-	logger log.Logger
-	mocks  map[uint64]Mock
+	mutex     sync.Mutex
+	logger    log.Logger
+	mocks     map[uint64]Mock
+	order     map[uint64]int
+	next      int
+	journal   *Journal
+	metrics   *Metrics
+	accessLog *AccessLogger
+	config    spec.Config
+
+	// The router is rebuilt lazily whenever the set of mocks changes.
+	router *mux.Router
+	routes map[*mux.Route]Mock
 }
 
 // NewMockService creates a new instance of MockService.
 func NewMockService(logger log.Logger) *MockService {
+	accessLog, _ := NewAccessLogger(logger, AccessLogOptions{})
+
 	return &MockService{
-		logger: logger,
-		mocks:  map[uint64]Mock{},
+		logger:    logger,
+		mocks:     map[uint64]Mock{},
+		order:     map[uint64]int{},
+		journal:   NewJournal(),
+		metrics:   NewMetrics(),
+		accessLog: accessLog,
+	}
+}
+
+// SetAccessLogger replaces the access logger for requests served by the mock server.
+func (s *MockService) SetAccessLogger(a *AccessLogger) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.accessLog = a
+}
+
+// Load registers all mocks from a spec and uses its configurations for unmatched requests.
+func (s *MockService) Load(sp *spec.Spec) {
+	s.mutex.Lock()
+	s.config = sp.Config
+	s.router = nil
+	s.mutex.Unlock()
+
+	for i := range sp.HTTPMocks {
+		s.Add(&sp.HTTPMocks[i])
+	}
+	for i := range sp.RESTMocks {
+		s.Add(&sp.RESTMocks[i])
+	}
+	for i := range sp.OIDCMocks {
+		s.Add(&sp.OIDCMocks[i])
+	}
+	for i := range sp.GraphQLMocks {
+		s.Add(&sp.GraphQLMocks[i])
+	}
+	for i := range sp.GRPCMocks {
+		s.Add(&sp.GRPCMocks[i])
+	}
+	for i := range sp.WebSocketMocks {
+		s.Add(&sp.WebSocketMocks[i])
 	}
 }
 
 // Add registers a new mock.
-// If a mock already exists, it will be replaced.
+// If a mock already exists, it will be replaced and keeps its order.
 func (s *MockService) Add(m Mock) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	key := m.Hash()
+	if _, ok := s.mocks[key]; !ok {
+		s.order[key] = s.next
+		s.next++
+	}
+
 	s.mocks[key] = m
+	s.router = nil
 
 	s.logger.Debug("message", "mock added", "mock", m.String())
 }
 
 // Delete deregisters an existing mock.
 func (s *MockService) Delete(m Mock) {
-	key := m.Hash()
+	s.DeleteByHash(m.Hash())
+}
+
+// DeleteByHash deregisters an existing mock by its hash.
+// It returns false if no such mock exists.
+func (s *MockService) DeleteByHash(key uint64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m, ok := s.mocks[key]
+	if !ok {
+		return false
+	}
+
 	delete(s.mocks, key)
+	delete(s.order, key)
+	s.router = nil
 
 	s.logger.Debug("message", "mock deleted", "mock", m.String())
+
+	return true
+}
+
+// Mocks returns all registered mocks keyed by their hashes.
+func (s *MockService) Mocks() map[uint64]Mock {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	mocks := make(map[uint64]Mock, len(s.mocks))
+	for key, m := range s.mocks {
+		mocks[key] = m
+	}
+
+	return mocks
+}
+
+// Replace deregisters all mocks and registers all mocks from a spec instead.
+// The journal is kept.
+func (s *MockService) Replace(sp *spec.Spec) {
+	s.mutex.Lock()
+	s.mocks = map[uint64]Mock{}
+	s.order = map[uint64]int{}
+	s.router = nil
+	s.mutex.Unlock()
+
+	s.Load(sp)
+}
+
+// Reset deregisters all mocks and clears the journal.
+func (s *MockService) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.mocks = map[uint64]Mock{}
+	s.order = map[uint64]int{}
+	s.router = nil
+	s.journal.Clear()
+
+	s.logger.Debug("mocks reset")
+}
+
+// Journal returns the journal of received requests.
+func (s *MockService) Journal() *Journal {
+	return s.journal
+}
+
+// Metrics returns the metrics of the mock server.
+func (s *MockService) Metrics() *Metrics {
+	return s.metrics
 }
 
 // Router creates a new router for mocks.
 func (s *MockService) Router() *mux.Router {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	router, _ := s.build()
+	return router
+}
+
+// sorted returns all mocks in the order their routes should be registered.
+// Mocks are sorted by priority, then by specificity, and then by the order they were added.
+func (s *MockService) sorted() []Mock {
+	keys := make([]uint64, 0, len(s.mocks))
+	for key := range s.mocks {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := s.mocks[keys[i]].Rank(), s.mocks[keys[j]].Rank()
+		if ri != rj {
+			return rj.Less(ri)
+		}
+		return s.order[keys[i]] < s.order[keys[j]]
+	})
+
+	mocks := make([]Mock, len(keys))
+	for i, key := range keys {
+		mocks[i] = s.mocks[key]
+	}
+
+	return mocks
+}
+
+// build creates a new router and a map of its routes to their mocks.
+// Routes are registered in the order of mocks, since the first matching route wins.
+func (s *MockService) build() (*mux.Router, map[*mux.Route]Mock) {
 	router := mux.NewRouter()
-	for _, m := range s.mocks {
+	routes := map[*mux.Route]Mock{}
+
+	mocks := s.sorted()
+
+	router.NotFoundHandler = s.unmatchedHandler(mocks, s.config, http.StatusNotFound)
+	router.MethodNotAllowedHandler = s.unmatchedHandler(mocks, s.config, http.StatusMethodNotAllowed)
+
+	for _, m := range mocks {
 		m.RegisterRoutes(router)
+
+		// Every route not seen yet belongs to the mock just registered.
+		_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			if _, ok := routes[route]; !ok {
+				routes[route] = m
+			}
+			return nil
+		})
 	}
 
-	return router
+	return router, routes
+}
+
+// unmatchedHandler creates an http handler for requests not matching any mock.
+// If a fallback response or forwarder is configured, it is used.
+// Otherwise, it responds with the closest mocks to the request and the criteria not satisfied by the request.
+// In strict mode, unmatched requests are also recorded as verification failures.
+func (s *MockService) unmatchedHandler(mocks []Mock, config spec.Config, statusCode int) http.Handler {
+	if handler := config.Fallback.Handler(); handler != nil {
+		return handler
+	}
+
+	strict := config.Fallback != nil && config.Fallback.Strict
+	if strict {
+		statusCode = http.StatusInternalServerError
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := newRequest(r)
+		if strict {
+			s.journal.RecordFailure(req)
+		}
+
+		diagnoses := make([]spec.Diagnosis, len(mocks))
+		for i, m := range mocks {
+			diagnoses[i] = m.Diagnose(req)
+		}
+
+		res := config.Unmatched.Respond(w, req, statusCode, diagnoses)
+
+		closest := make([]string, len(res.Closest))
+		for i, d := range res.Closest {
+			mismatches := make([]string, len(d.Mismatches))
+			for j, m := range d.Mismatches {
+				mismatches[j] = m.String()
+			}
+			closest[i] = fmt.Sprintf("%s (score %.2f): %s", d.Mock, d.Score, strings.Join(mismatches, "; "))
+		}
+
+		s.logger.Warn(res.Message, "method", req.Method, "path", req.Path, "closest", closest)
+	})
+}
+
+// newRequest creates a journal entry for an http request.
+// The request body is read and replaced, so it can be read again.
+// The bodies of gRPC calls are streams and not recorded.
+func newRequest(r *http.Request) spec.Request {
+	var body []byte
+	if r.Body != nil && !spec.IsGRPC(r) {
+		body, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	form, files := spec.ParseForm(r.Header, body)
+
+	return spec.Request{
+		Time:     time.Now(),
+		Listener: spec.ListenerFromContext(r.Context()),
+		Host:     r.Host,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Queries:  r.URL.Query(),
+		Headers:  r.Header.Clone(),
+		Body:     string(body),
+		Form:     form,
+		Files:    files,
+	}
+}
+
+// Listener returns an http handler for serving the mocks of a listener.
+func (s *MockService) Listener(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ServeHTTP(w, r.WithContext(spec.WithListener(r.Context(), name)))
+	})
+}
+
+// ServeHTTP records an incoming request in the journal and serves it using the current mocks.
+// Requests are served by the mocks with no listener unless received through the handler of a listener.
+func (s *MockService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	s.mutex.Lock()
+	if s.router == nil {
+		s.router, s.routes = s.build()
+	}
+	router, routes, accessLog := s.router, s.routes, s.accessLog
+	s.mutex.Unlock()
+
+	entry := newRequest(r)
+	entry.Time = start
+
+	var match mux.RouteMatch
+	if router.Match(r, &match) && match.MatchErr == nil {
+		if m, ok := routes[match.Route]; ok {
+			entry.Mock = m.String()
+		}
+	}
+
+	s.journal.Record(entry)
+
+	ctx, span := startSpan(r, entry.Mock)
+	ctx = spec.WithProxyErrorHandler(ctx, func(err error) {
+		span.RecordError(err)
+		s.metrics.ObserveProxyError(entry.Mock)
+		s.logger.Warn("upstream error", "mock", entry.Mock, "error", err)
+	})
+
+	ctx = spec.WithRecorder(ctx, s.journal.Record)
+	ctx = spec.WithDeliveryRecorder(ctx, s.journal.RecordDelivery)
+	ctx = withMock(ctx, entry.Mock)
+
+	rw := newResponseWriter(w)
+
+	// The span and the metrics are also finished for requests aborted by panicking (i.e. dropped by chaos).
+	defer func() {
+		endSpan(span, rw.statusCode)
+		s.metrics.ObserveRequest(entry.Mock, r.Method, rw.statusCode, time.Since(start).Seconds())
+	}()
+
+	accessLog.Handler(router).ServeHTTP(rw, r.WithContext(ctx))
 }
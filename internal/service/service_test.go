@@ -1,10 +1,27 @@
 package service
 
 import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/moorara/flax/spec"
 	"github.com/moorara/log"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func TestNewMockService(t *testing.T) {
@@ -25,6 +42,59 @@ func TestNewMockService(t *testing.T) {
 			assert.NotNil(t, service)
 			assert.NotNil(t, service.logger)
 			assert.NotNil(t, service.mocks)
+			assert.NotNil(t, service.journal)
+		})
+	}
+}
+
+var (
+	httpMock = &spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/health",
+		},
+		HTTPResponse: &spec.HTTPResponse{
+			StatusCode: 200,
+		},
+	}
+
+	restMock = &spec.RESTMock{
+		RESTExpect: spec.RESTExpect{
+			BasePath: "/api/v1/teams",
+		},
+		RESTResponse: spec.RESTResponse{
+			GetStatusCode: 200,
+		},
+	}
+)
+
+func TestMockServiceLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          *spec.Spec
+		expectedMocks int
+	}{
+		{
+			name:          "DefaultSpec",
+			spec:          spec.DefaultSpec(),
+			expectedMocks: 1,
+		},
+		{
+			name: "OK",
+			spec: &spec.Spec{
+				HTTPMocks: []spec.HTTPMock{*httpMock},
+				RESTMocks: []spec.RESTMock{*restMock},
+			},
+			expectedMocks: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewMockService(log.NewNopLogger())
+			service.Load(tc.spec)
+
+			assert.Len(t, service.mocks, tc.expectedMocks)
 		})
 	}
 }
@@ -34,13 +104,18 @@ func TestMockServiceAdd(t *testing.T) {
 		name          string
 		service       *MockService
 		mock          Mock
-		expectedMocks []Mock
+		expectedMocks map[uint64]Mock
 	}{
 		{
 			name: "HTTPMock",
 			service: &MockService{
 				logger: log.NewNopLogger(),
 				mocks:  map[uint64]Mock{},
+				order:  map[uint64]int{},
+			},
+			mock: httpMock,
+			expectedMocks: map[uint64]Mock{
+				httpMock.Hash(): httpMock,
 			},
 		},
 		{
@@ -48,13 +123,19 @@ func TestMockServiceAdd(t *testing.T) {
 			service: &MockService{
 				logger: log.NewNopLogger(),
 				mocks:  map[uint64]Mock{},
+				order:  map[uint64]int{},
+			},
+			mock: restMock,
+			expectedMocks: map[uint64]Mock{
+				restMock.Hash(): restMock,
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			assert.NotNil(t, tc)
+			tc.service.Add(tc.mock)
+			assert.Equal(t, tc.expectedMocks, tc.service.Mocks())
 		})
 	}
 }
@@ -64,40 +145,594 @@ func TestMockServiceDelete(t *testing.T) {
 		name          string
 		service       *MockService
 		mock          Mock
-		expectedMocks []Mock
+		expectedMocks map[uint64]Mock
 	}{
 		{
 			name: "HTTPMock",
 			service: &MockService{
 				logger: log.NewNopLogger(),
-				mocks:  map[uint64]Mock{},
+				mocks: map[uint64]Mock{
+					httpMock.Hash(): httpMock,
+					restMock.Hash(): restMock,
+				},
+			},
+			mock: httpMock,
+			expectedMocks: map[uint64]Mock{
+				restMock.Hash(): restMock,
 			},
 		},
 		{
 			name: "RESTMock",
 			service: &MockService{
 				logger: log.NewNopLogger(),
-				mocks:  map[uint64]Mock{},
+				mocks: map[uint64]Mock{
+					httpMock.Hash(): httpMock,
+					restMock.Hash(): restMock,
+				},
+			},
+			mock: restMock,
+			expectedMocks: map[uint64]Mock{
+				httpMock.Hash(): httpMock,
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			assert.NotNil(t, tc)
+			tc.service.Delete(tc.mock)
+			assert.Equal(t, tc.expectedMocks, tc.service.Mocks())
 		})
 	}
 }
 
+func TestMockServiceReset(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Add(httpMock)
+	service.Journal().Record(spec.Request{Method: "GET", Path: "/health"})
+
+	service.Reset()
+
+	assert.Empty(t, service.Mocks())
+	assert.Empty(t, service.Journal().Requests())
+}
+
 func TestMockServiceRouter(t *testing.T) {
 	tests := []struct {
-		name    string
-		service *MockService
-	}{}
+		name           string
+		mocks          []Mock
+		expectedRoutes int
+	}{
+		{
+			name:           "Empty",
+			mocks:          []Mock{},
+			expectedRoutes: 0,
+		},
+		{
+			name:           "OK",
+			mocks:          []Mock{httpMock, restMock},
+			expectedRoutes: 7,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewMockService(log.NewNopLogger())
+			for _, m := range tc.mocks {
+				service.Add(m)
+			}
+
+			routes := 0
+			router := service.Router()
+			_ = router.Walk(func(*mux.Route, *mux.Router, []*mux.Route) error {
+				routes++
+				return nil
+			})
+
+			assert.Equal(t, tc.expectedRoutes, routes)
+		})
+	}
+}
+
+func TestMockServiceServeHTTP(t *testing.T) {
+	tests := []struct {
+		name               string
+		mocks              []Mock
+		reqMethod          string
+		reqPath            string
+		reqBody            string
+		expectedStatusCode int
+		expectedMock       string
+	}{
+		{
+			name:               "Matched",
+			mocks:              []Mock{httpMock, restMock},
+			reqMethod:          "GET",
+			reqPath:            "/health",
+			reqBody:            "",
+			expectedStatusCode: 200,
+			expectedMock:       "GET /health",
+		},
+		{
+			name:               "NotMatched",
+			mocks:              []Mock{httpMock, restMock},
+			reqMethod:          "POST",
+			reqPath:            "/api/v1/sendMessage",
+			reqBody:            `{"message":"hello"}`,
+			expectedStatusCode: 404,
+			expectedMock:       "",
+		},
+	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			assert.NotNil(t, tc)
+			logger := &mockLogger{Logger: log.NewNopLogger()}
+			service := NewMockService(logger)
+			for _, m := range tc.mocks {
+				service.Add(m)
+			}
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, strings.NewReader(tc.reqBody))
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+
+			requests := service.Journal().Requests()
+			assert.Len(t, requests, 1)
+			assert.Equal(t, tc.reqMethod, requests[0].Method)
+			assert.Equal(t, tc.reqPath, requests[0].Path)
+			assert.Equal(t, tc.reqBody, requests[0].Body)
+			assert.Equal(t, tc.expectedMock, requests[0].Mock)
+
+			assert.Len(t, logger.entries, 1)
+			assert.Equal(t, tc.expectedMock, logger.entries[0].kv["mock"])
+			assert.Equal(t, tc.expectedStatusCode, logger.entries[0].kv["status"])
 		})
 	}
 }
+
+func TestMockServiceServeHTTPAborted(t *testing.T) {
+	chaos := &spec.Chaos{
+		Drops: &spec.ChaosDrops{Percent: 100},
+	}
+	chaos.SetDefaults()
+
+	mock := *httpMock
+	mock.Inherit(spec.Config{Chaos: chaos})
+
+	logger := &mockLogger{Logger: log.NewNopLogger()}
+	accessLogger, err := NewAccessLogger(logger, AccessLogOptions{})
+	assert.NoError(t, err)
+
+	service := NewMockService(log.NewNopLogger())
+	service.SetAccessLogger(accessLogger)
+	service.Add(&mock)
+
+	// A recorder cannot be hijacked, so the dropped request is aborted by panicking.
+	req := httptest.NewRequest("GET", "/health", nil)
+	res := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		service.ServeHTTP(res, req)
+	})
+
+	assert.Len(t, logger.entries, 1)
+	assert.Equal(t, "GET /health", logger.entries[0].kv["mock"])
+	assert.Equal(t, true, logger.entries[0].kv["aborted"])
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	res = httptest.NewRecorder()
+	service.Metrics().Handler().ServeHTTP(res, req)
+	assert.Contains(t, res.Body.String(), `flax_requests_total{method="GET",mock="GET /health",status="200"} 1`)
+}
+
+func TestMockServiceUnmatched(t *testing.T) {
+	tests := []struct {
+		name               string
+		unmatched          *spec.Unmatched
+		reqMethod          string
+		reqPath            string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "NotFound",
+			unmatched:          nil,
+			reqMethod:          "GET",
+			reqPath:            "/healthz",
+			expectedStatusCode: 404,
+			expectedBody:       `"mock":"GET /health","score":0.4`,
+		},
+		{
+			name:               "MethodNotAllowed",
+			unmatched:          nil,
+			reqMethod:          "POST",
+			reqPath:            "/health",
+			expectedStatusCode: 405,
+			expectedBody:       `"mock":"GET /health","score":0.6`,
+		},
+		{
+			name:               "Configured",
+			unmatched:          &spec.Unmatched{StatusCode: 599, Format: "text", Closest: 1},
+			reqMethod:          "GET",
+			reqPath:            "/healthz",
+			expectedStatusCode: 599,
+			expectedBody:       "GET /health (score 0.40)\n  path: expected \"/health\", got \"/healthz\"\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewMockService(log.NewNopLogger())
+			service.Load(&spec.Spec{
+				Config: spec.Config{
+					Unmatched: tc.unmatched,
+				},
+				HTTPMocks: []spec.HTTPMock{*httpMock},
+			})
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Contains(t, res.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestMockServiceFallback(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name               string
+		fallback           *spec.Fallback
+		reqMethod          string
+		reqPath            string
+		expectedStatusCode int
+		expectedBody       string
+		expectedFailures   int
+	}{
+		{
+			name: "Response",
+			fallback: &spec.Fallback{
+				HTTPResponse: &spec.HTTPResponse{StatusCode: 503, BodyText: "unavailable"},
+			},
+			reqMethod:          "GET",
+			reqPath:            "/api/v1/messages",
+			expectedStatusCode: 503,
+			expectedBody:       "unavailable",
+			expectedFailures:   0,
+		},
+		{
+			name: "Forward",
+			fallback: &spec.Fallback{
+				HTTPForward: &spec.HTTPForward{To: upstream.URL},
+			},
+			reqMethod:          "POST",
+			reqPath:            "/health",
+			expectedStatusCode: 202,
+			expectedBody:       "/health",
+			expectedFailures:   0,
+		},
+		{
+			name: "Strict",
+			fallback: &spec.Fallback{
+				Strict: true,
+			},
+			reqMethod:          "GET",
+			reqPath:            "/api/v1/messages",
+			expectedStatusCode: 500,
+			expectedBody:       `"message":"no mock matched the request"`,
+			expectedFailures:   1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewMockService(log.NewNopLogger())
+			service.Load(&spec.Spec{
+				Config: spec.Config{
+					Fallback: tc.fallback,
+				},
+				HTTPMocks: []spec.HTTPMock{*httpMock},
+			})
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, nil)
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Contains(t, res.Body.String(), tc.expectedBody)
+			assert.Len(t, service.Journal().Failures(), tc.expectedFailures)
+		})
+	}
+}
+
+func TestMockServicePriority(t *testing.T) {
+	catchAll := &spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/api",
+			Prefix:  true,
+		},
+		HTTPResponse: &spec.HTTPResponse{StatusCode: 200, BodyText: "catch-all"},
+	}
+
+	specific := &spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/api/v1/teams",
+		},
+		HTTPResponse: &spec.HTTPResponse{StatusCode: 200, BodyText: "specific"},
+	}
+
+	prioritized := *catchAll
+	prioritized.Priority = 10
+
+	first := &spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/api/v1/teams",
+			Queries: map[string]string{"id": ".*"},
+		},
+		HTTPResponse: &spec.HTTPResponse{StatusCode: 200, BodyText: "first"},
+	}
+
+	second := &spec.HTTPMock{
+		HTTPExpect: spec.HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/api/v1/teams",
+			Headers: map[string]string{"Accept": ".*"},
+		},
+		HTTPResponse: &spec.HTTPResponse{StatusCode: 200, BodyText: "second"},
+	}
+
+	tests := []struct {
+		name         string
+		mocks        []Mock
+		expectedBody string
+	}{
+		{"Specificity", []Mock{catchAll, specific}, "specific"},
+		{"SpecificityReversed", []Mock{specific, catchAll}, "specific"},
+		{"Priority", []Mock{specific, &prioritized}, "catch-all"},
+		{"SpecOrder", []Mock{first, second}, "first"},
+		{"SpecOrderReversed", []Mock{second, first}, "second"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Map iteration order is random, so the router is built many times.
+			for i := 0; i < 20; i++ {
+				service := NewMockService(log.NewNopLogger())
+				for _, m := range tc.mocks {
+					service.Add(m)
+				}
+
+				req := httptest.NewRequest("GET", "/api/v1/teams?id=1", nil)
+				req.Header.Set("Accept", "text/plain")
+				res := httptest.NewRecorder()
+				service.ServeHTTP(res, req)
+
+				assert.Equal(t, tc.expectedBody, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestMockServiceListener(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		Config: spec.Config{
+			Listeners: []spec.Listener{
+				{Name: "payments", Port: 8081},
+			},
+		},
+		HTTPMocks: []spec.HTTPMock{
+			{
+				HTTPExpect:   spec.HTTPExpect{Methods: []string{"GET"}, Path: "/health"},
+				HTTPResponse: &spec.HTTPResponse{StatusCode: 200},
+			},
+			{
+				HTTPExpect:   spec.HTTPExpect{Listener: "payments", Methods: []string{"GET"}, Path: "/health"},
+				HTTPResponse: &spec.HTTPResponse{StatusCode: 204},
+			},
+			{
+				HTTPExpect:   spec.HTTPExpect{Listener: "payments", Methods: []string{"POST"}, Path: "/api/v1/payments"},
+				HTTPResponse: &spec.HTTPResponse{StatusCode: 201},
+			},
+		},
+	})
+
+	tests := []struct {
+		name               string
+		handler            http.Handler
+		reqMethod          string
+		reqPath            string
+		expectedStatusCode int
+		expectedListener   string
+	}{
+		{"Default", service, "GET", "/health", 200, ""},
+		{"DefaultOnly", service, "POST", "/api/v1/payments", 404, ""},
+		{"Listener", service.Listener("payments"), "GET", "/health", 204, "payments"},
+		{"ListenerOnly", service.Listener("payments"), "POST", "/api/v1/payments", 201, "payments"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service.Journal().Clear()
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, nil)
+			res := httptest.NewRecorder()
+			tc.handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+
+			requests := service.Journal().Requests()
+			assert.Len(t, requests, 1)
+			assert.Equal(t, tc.expectedListener, requests[0].Listener)
+		})
+	}
+}
+
+func TestMockServiceOIDC(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		OIDCMocks: []spec.OIDCMock{
+			{BasePath: "/auth", TokenTTL: "1h"},
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/auth/.well-known/openid-configuration", nil)
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `"issuer":"http://example.com/auth"`)
+
+	requests := service.Journal().Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "OIDC /auth", requests[0].Mock)
+}
+
+func TestMockServiceGraphQL(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		GraphQLMocks: []spec.GraphQLMock{
+			{
+				Path:   "/graphql",
+				Schema: "type Query { hello(name: String = \"World\"): String! }",
+				Resolvers: []spec.GraphQLResolver{
+					{Field: "Query.hello", Args: map[string]string{"name": "Jane"}, Data: "Hello, Jane!"},
+				},
+			},
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ hello(name: \"Jane\") }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, `{"data":{"hello":"Hello, Jane!"}}`, strings.TrimSpace(res.Body.String()))
+
+	requests := service.Journal().Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "GraphQL /graphql", requests[0].Mock)
+}
+
+func TestMockServiceGRPC(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		GRPCMocks: []spec.GRPCMock{
+			{
+				DescriptorSet: "../../spec/test/greeter.pb",
+				Methods: []spec.GRPCMethod{
+					{Name: "greeter.v1.Greeter/Chat", Response: spec.JSON{"message": "Hi!"}},
+				},
+			},
+		},
+	})
+
+	ts := httptest.NewServer(h2c.NewHandler(service, &http2.Server{}))
+	defer ts.Close()
+
+	b, err := ioutil.ReadFile("../../spec/test/greeter.pb")
+	assert.NoError(t, err)
+	set := new(descriptorpb.FileDescriptorSet)
+	assert.NoError(t, proto.Unmarshal(b, set))
+	files, err := protodesc.NewFiles(set)
+	assert.NoError(t, err)
+	d, err := files.FindDescriptorByName("greeter.v1.Greeter.Chat")
+	assert.NoError(t, err)
+	md := d.(protoreflect.MethodDescriptor)
+
+	conn, err := grpc.Dial(strings.TrimPrefix(ts.URL, "http://"), grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, "/greeter.v1.Greeter/Chat")
+	assert.NoError(t, err)
+
+	// The response is received before the stream is closed, so the request body is not read ahead.
+	assert.NoError(t, stream.SendMsg(dynamicpb.NewMessage(md.Input())))
+	res := dynamicpb.NewMessage(md.Output())
+	assert.NoError(t, stream.RecvMsg(res))
+	assert.Equal(t, "Hi!", res.Get(md.Output().Fields().ByName("message")).String())
+
+	assert.NoError(t, stream.CloseSend())
+	assert.Equal(t, io.EOF, stream.RecvMsg(res))
+
+	requests := service.Journal().Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "gRPC greeter.v1.Greeter", requests[0].Mock)
+	assert.Equal(t, "/greeter.v1.Greeter/Chat", requests[0].Path)
+	assert.Empty(t, requests[0].Body)
+}
+
+func TestMockServiceWebSocket(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		WebSocketMocks: []spec.WebSocketMock{
+			{
+				HTTPExpect: spec.HTTPExpect{Methods: []string{"GET"}, Path: "/feed"},
+				Script: []spec.WebSocketStep{
+					{On: "^bye$", Close: &spec.WebSocketClose{Code: 1000}},
+				},
+			},
+		},
+	})
+
+	ts := httptest.NewServer(service)
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/feed", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, 1000))
+
+	requests := service.Journal().Requests()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "GET", requests[0].Method)
+	assert.Equal(t, "WebSocket /feed", requests[0].Mock)
+	assert.Equal(t, spec.WebSocketMethod, requests[1].Method)
+	assert.Equal(t, "bye", requests[1].Body)
+	assert.Equal(t, "WebSocket /feed", requests[1].Mock)
+}
+
+func TestMockServiceForm(t *testing.T) {
+	service := NewMockService(log.NewNopLogger())
+	service.Load(&spec.Spec{
+		HTTPMocks: []spec.HTTPMock{
+			{
+				HTTPExpect: spec.HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/login",
+					Form:    map[string]string{"username": "^admin$"},
+				},
+				HTTPResponse: &spec.HTTPResponse{StatusCode: 204},
+			},
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader("username=admin&password=secret"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res := httptest.NewRecorder()
+	service.ServeHTTP(res, req)
+
+	assert.Equal(t, 204, res.Result().StatusCode)
+
+	requests := service.Journal().Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "POST /login", requests[0].Mock)
+	assert.Equal(t, map[string][]string{"username": {"admin"}, "password": {"secret"}}, requests[0].Form)
+	assert.Nil(t, requests[0].Files)
+}
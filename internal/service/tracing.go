@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/moorara/flax/spec"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serverName = "flax"
+
+	// mockKey is the span attribute for the mock matching a request.
+	mockKey = attribute.Key("flax.mock")
+)
+
+// startSpan creates a server span for an incoming request.
+// The trace context is extracted from the request headers, so the span continues the caller's trace.
+func startSpan(r *http.Request, mock string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	name := "HTTP " + r.Method
+	if mock != "" {
+		name = mock
+	}
+
+	attrs := semconv.HTTPServerAttributesFromHTTPRequest(serverName, "", r)
+	attrs = append(attrs, semconv.NetAttributesFromHTTPRequest("tcp", r)...)
+	attrs = append(attrs, mockKey.String(mock))
+
+	return otel.Tracer(spec.TracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records the response status code on a server span and ends it.
+func endSpan(span trace.Span, statusCode int) {
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(statusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(statusCode))
+	span.End()
+}
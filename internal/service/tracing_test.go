@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moorara/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMockServiceTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID := "00f067aa0ba902b7"
+
+	tests := []struct {
+		name               string
+		reqMethod          string
+		reqPath            string
+		expectedSpanName   string
+		expectedMock       string
+		expectedStatusCode int64
+		expectedStatus     codes.Code
+	}{
+		{
+			name:               "Matched",
+			reqMethod:          "GET",
+			reqPath:            "/health",
+			expectedSpanName:   "GET /health",
+			expectedMock:       "GET /health",
+			expectedStatusCode: 200,
+			expectedStatus:     codes.Unset,
+		},
+		{
+			name:               "NotMatched",
+			reqMethod:          "POST",
+			reqPath:            "/api/v1/sendMessage",
+			expectedSpanName:   "HTTP POST",
+			expectedMock:       "",
+			expectedStatusCode: 404,
+			expectedStatus:     codes.Error,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewMockService(log.NewNopLogger())
+			service.Add(httpMock)
+
+			req := httptest.NewRequest(tc.reqMethod, tc.reqPath, nil)
+			req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+			res := httptest.NewRecorder()
+			service.ServeHTTP(res, req)
+
+			spans := recorder.Ended()
+			assert.NotEmpty(t, spans)
+			span := spans[len(spans)-1]
+
+			assert.Equal(t, tc.expectedSpanName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+			assert.Equal(t, parentID, span.Parent().SpanID().String())
+			assert.True(t, span.Parent().IsRemote())
+			assert.Equal(t, tc.expectedStatus, span.Status().Code)
+			assert.Contains(t, span.Attributes(), mockKey.String(tc.expectedMock))
+			assert.Contains(t, span.Attributes(), attribute.Int64("http.status_code", tc.expectedStatusCode))
+		})
+	}
+}
@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"sync"
 
 	"github.com/moorara/flax/cmd/config"
 	"github.com/moorara/flax/cmd/server"
+	"github.com/moorara/flax/cmd/tracing"
 	"github.com/moorara/flax/internal/service"
-	"github.com/moorara/flax/internal/spec"
+	"github.com/moorara/flax/spec"
 	"github.com/moorara/flax/version"
 	"github.com/moorara/konfig"
 	"github.com/moorara/log"
 )
 
 const (
-	specErr = 10
+	specErr    = 10
+	tracingErr = 11
+	configErr  = 12
 )
 
 func main() {
@@ -40,24 +45,76 @@ func main() {
 		"bin.buildTime", version.BuildTime,
 	)
 
+	// Set up tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), config.Global.Name, version.Version, config.Global.TraceEndpoint)
+	if err != nil {
+		logger.Errorf("error while setting up tracing: %s", err)
+		os.Exit(tracingErr)
+	}
+
+	// Reading spec variables
+	vars, err := spec.ParseVars(config.Global.SpecVars)
+	if err != nil {
+		logger.Errorf("error while reading spec variables: %s", err)
+		os.Exit(specErr)
+	}
+
 	// Reading spec file
-	s, err := spec.ReadSpec(config.Global.SpecFile)
+	readSpec := func() (*spec.Spec, error) {
+		return spec.ReadSpec(config.Global.SpecFile, vars)
+	}
+
+	s, err := readSpec()
 	if err != nil {
 		logger.Errorf("error while reading spec file: %s", err)
 		os.Exit(specErr)
 	}
 
 	// Set up mock service
+	accessLogger, err := service.NewAccessLogger(logger, service.AccessLogOptions{
+		Bodies:      config.Global.LogBodies,
+		MaxBodySize: config.Global.LogBodyLimit,
+		Redact:      config.Global.LogRedact,
+	})
+	if err != nil {
+		logger.Errorf("error while setting up access log: %s", err)
+		os.Exit(configErr)
+	}
+
 	mockService := service.NewMockService(logger)
-	for i := range s.HTTPMocks {
-		mockService.Add(&s.HTTPMocks[i])
+	mockService.SetAccessLogger(accessLogger)
+	mockService.Load(s)
+
+	// Set up control service
+	controlService := service.NewControlService(logger, readSpec, s, mockService)
+
+	// Set up servers
+	servers := []*server.APIServer{
+		server.NewAPIServer(logger, s.Config.HTTPPort, mockService),
+		server.NewControlServer(logger, config.Global.ControlPort, controlService.Router()),
+	}
+
+	// Listeners are only read at startup
+	for _, l := range s.Config.Listeners {
+		servers = append(servers, server.NewListenerServer(logger, l.Name, l.Port, mockService.Listener(l.Name)))
 	}
-	for i := range s.RESTMocks {
-		mockService.Add(&s.RESTMocks[i])
+
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv *server.APIServer) {
+			defer wg.Done()
+			srv.Start()
+		}(srv)
 	}
 
-	// Set up api server
-	router := mockService.Router()
-	apiServer := server.NewAPIServer(logger, s.Config.HTTPPort, router)
-	apiServer.Start()
+	wg.Wait()
+
+	// Flush the remaining spans
+	ctx, cancel := context.WithTimeout(context.Background(), config.Global.GracePeriod)
+	defer cancel()
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Errorf("error while shutting down tracing: %s", err)
+	}
 }
@@ -0,0 +1,358 @@
+package spec
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultRealm = "flax"
+
+// BasicAuth represents a set of credentials for HTTP Basic authentication.
+type BasicAuth struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// APIKeyAuth represents the settings for authentication using API keys.
+// A key is read from either a header or a query parameter.
+type APIKeyAuth struct {
+	Header string   `json:"header" yaml:"header"`
+	Query  string   `json:"query" yaml:"query"`
+	Keys   []string `json:"keys" yaml:"keys"`
+}
+
+// JWTAuth represents the settings for authentication using JSON web tokens.
+// Tokens are verified using either an HMAC secret or the public keys in a JWKS file.
+// Claims are regular expressions matched against the string values of token claims.
+type JWTAuth struct {
+	Secret   string            `json:"secret" yaml:"secret"`
+	JWKSFile string            `json:"jwksFile" yaml:"jwks_file"`
+	Issuer   string            `json:"issuer" yaml:"issuer"`
+	Audience string            `json:"audience" yaml:"audience"`
+	Scopes   []string          `json:"scopes" yaml:"scopes"`
+	Claims   map[string]string `json:"claims" yaml:"claims"`
+}
+
+// Validate checks whether or not the jwt settings are valid.
+func (j *JWTAuth) Validate() error {
+	if (j.Secret == "") == (j.JWKSFile == "") {
+		return errors.New("exactly one of jwt secret or jwks file should be set")
+	}
+
+	if j.JWKSFile != "" {
+		if _, err := readJWKS(j.JWKSFile); err != nil {
+			return err
+		}
+	}
+
+	for claim, pattern := range j.Claims {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern for claim %s: %s", claim, err)
+		}
+	}
+
+	return nil
+}
+
+// Auth represents the authentication settings of a mock.
+// A request is authenticated if it has valid credentials for any of the configured schemes.
+type Auth struct {
+	Realm  string      `json:"realm" yaml:"realm"`
+	Basic  []BasicAuth `json:"basic" yaml:"basic"`
+	Bearer []string    `json:"bearer" yaml:"bearer"`
+	APIKey *APIKeyAuth `json:"apiKey" yaml:"api_key"`
+	JWT    *JWTAuth    `json:"jwt" yaml:"jwt"`
+}
+
+// Validate checks whether or not the authentication settings are valid.
+// If a is nil, it is valid.
+func (a *Auth) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if len(a.Basic) == 0 && len(a.Bearer) == 0 && a.APIKey == nil && a.JWT == nil {
+		return errors.New("no authentication scheme is set")
+	}
+
+	if a.APIKey != nil && (a.APIKey.Header == "") == (a.APIKey.Query == "") {
+		return errors.New("exactly one of api key header or query should be set")
+	}
+
+	if a.JWT != nil {
+		if err := a.JWT.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// authError is an authentication or authorization failure.
+type authError struct {
+	statusCode  int
+	scheme      string
+	code        string
+	description string
+	scope       []string
+}
+
+func (e *authError) Error() string {
+	return e.description
+}
+
+func unauthorized(scheme, code, description string) *authError {
+	return &authError{
+		statusCode:  http.StatusUnauthorized,
+		scheme:      scheme,
+		code:        code,
+		description: description,
+	}
+}
+
+// authenticate checks the credentials of a request.
+// If credentials are presented for more than one scheme, the request is authenticated by any of them.
+// Otherwise, the failure of the last presented credentials is returned.
+func (a *Auth) authenticate(r *http.Request, keys jwks) *authError {
+	var failure *authError
+
+	authorization := r.Header.Get("Authorization")
+
+	if len(a.Basic) > 0 {
+		if username, password, ok := r.BasicAuth(); ok {
+			if a.checkBasic(username, password) {
+				return nil
+			}
+			failure = unauthorized("Basic", "", "invalid credentials")
+		}
+	}
+
+	if len(a.Bearer) > 0 || a.JWT != nil {
+		if token, ok := bearerToken(authorization); ok {
+			if containsSecret(a.Bearer, token) {
+				return nil
+			}
+
+			if a.JWT == nil {
+				failure = unauthorized("Bearer", "invalid_token", "invalid token")
+			} else if err := a.JWT.check(token, keys); err != nil {
+				failure = err
+			} else {
+				return nil
+			}
+		}
+	}
+
+	if a.APIKey != nil {
+		var key string
+		if a.APIKey.Header != "" {
+			key = r.Header.Get(a.APIKey.Header)
+		} else {
+			key = r.URL.Query().Get(a.APIKey.Query)
+		}
+
+		if key != "" {
+			if containsSecret(a.APIKey.Keys, key) {
+				return nil
+			}
+			failure = unauthorized("APIKey", "", "invalid api key")
+		}
+	}
+
+	if failure == nil {
+		failure = unauthorized("", "", "missing credentials")
+	}
+
+	return failure
+}
+
+func (a *Auth) checkBasic(username, password string) bool {
+	for _, c := range a.Basic {
+		if subtle.ConstantTimeCompare([]byte(c.Username), []byte(username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(c.Password), []byte(password)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// check verifies a token and its claims.
+// Invalid tokens are unauthorized (401) while valid tokens with insufficient claims are forbidden (403).
+func (j *JWTAuth) check(token string, keys jwks) *authError {
+	claims, err := parseJWT(token, []byte(j.Secret), keys, time.Now())
+	if err != nil {
+		return unauthorized("Bearer", "invalid_token", err.Error())
+	}
+
+	if j.Issuer != "" && claims["iss"] != j.Issuer {
+		return unauthorized("Bearer", "invalid_token", "invalid issuer")
+	}
+
+	if j.Audience != "" && !containsString(claimValues(claims["aud"]), j.Audience) {
+		return unauthorized("Bearer", "invalid_token", "invalid audience")
+	}
+
+	scopes := claimValues(claims["scope"])
+	if len(scopes) == 0 {
+		scopes = claimValues(claims["scp"])
+	}
+
+	for _, scope := range j.Scopes {
+		if !containsString(scopes, scope) {
+			return &authError{
+				statusCode:  http.StatusForbidden,
+				scheme:      "Bearer",
+				code:        "insufficient_scope",
+				description: fmt.Sprintf("missing scope %s", scope),
+				scope:       j.Scopes,
+			}
+		}
+	}
+
+	for _, claim := range sortedKeys(j.Claims) {
+		val, ok := claims[claim]
+		re, err := regexp.Compile(j.Claims[claim])
+		if !ok || err != nil || !re.MatchString(fmt.Sprint(val)) {
+			return &authError{
+				statusCode:  http.StatusForbidden,
+				scheme:      "Bearer",
+				code:        "insufficient_scope",
+				description: fmt.Sprintf("claim %s does not match", claim),
+			}
+		}
+	}
+
+	return nil
+}
+
+// claimValues returns the values of a claim that is either a space-delimited string or an array of strings.
+func claimValues(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func bearerToken(authorization string) (string, bool) {
+	const prefix = "Bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
+
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSecret(secrets []string, secret string) bool {
+	for _, s := range secrets {
+		if subtle.ConstantTimeCompare([]byte(s), []byte(secret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// challenges returns the WWW-Authenticate challenges for an authentication failure.
+// A forbidden request only gets the challenge of its scheme.
+func (a *Auth) challenges(e *authError) []string {
+	realm := a.Realm
+	if realm == "" {
+		realm = defaultRealm
+	}
+
+	challenge := func(scheme string, params ...string) string {
+		c := fmt.Sprintf("%s realm=%q", scheme, realm)
+		for i := 0; i+1 < len(params); i += 2 {
+			if params[i+1] != "" {
+				c += fmt.Sprintf(", %s=%q", params[i], params[i+1])
+			}
+		}
+		return c
+	}
+
+	bearer := func() string {
+		if e.scheme != "Bearer" {
+			return challenge("Bearer")
+		}
+		return challenge("Bearer", "error", e.code, "error_description", e.description, "scope", strings.Join(e.scope, " "))
+	}
+
+	if e.statusCode == http.StatusForbidden {
+		return []string{bearer()}
+	}
+
+	challenges := []string{}
+
+	if len(a.Basic) > 0 {
+		challenges = append(challenges, challenge("Basic"))
+	}
+
+	if len(a.Bearer) > 0 || a.JWT != nil {
+		challenges = append(challenges, bearer())
+	}
+
+	if a.APIKey != nil {
+		challenges = append(challenges, challenge("APIKey", "header", a.APIKey.Header, "query", a.APIKey.Query))
+	}
+
+	return challenges
+}
+
+// Handler wraps an http handler for rejecting requests with no valid credentials.
+// Unauthenticated requests receive a 401 and unauthorized requests receive a 403, both with WWW-Authenticate headers.
+// If a is nil, the handler is returned unchanged.
+func (a *Auth) Handler(h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+
+	var keys jwks
+	var keysErr error
+	if a.JWT != nil && a.JWT.JWKSFile != "" {
+		keys, keysErr = readJWKS(a.JWT.JWKSFile)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keysErr != nil {
+			writeJSON(w, http.StatusInternalServerError, JSON{
+				"message": keysErr.Error(),
+			})
+			return
+		}
+
+		if err := a.authenticate(r, keys); err != nil {
+			for _, challenge := range a.challenges(err) {
+				w.Header().Add("WWW-Authenticate", challenge)
+			}
+
+			writeJSON(w, err.statusCode, JSON{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,257 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          *Auth
+		expectedError string
+	}{
+		{
+			name:          "Nil",
+			auth:          nil,
+			expectedError: "",
+		},
+		{
+			name:          "NoScheme",
+			auth:          &Auth{Realm: "api"},
+			expectedError: "no authentication scheme is set",
+		},
+		{
+			name:          "APIKeyNoSource",
+			auth:          &Auth{APIKey: &APIKeyAuth{Keys: []string{"key"}}},
+			expectedError: "exactly one of api key header or query should be set",
+		},
+		{
+			name:          "JWTNoKey",
+			auth:          &Auth{JWT: &JWTAuth{Issuer: "https://issuer"}},
+			expectedError: "exactly one of jwt secret or jwks file should be set",
+		},
+		{
+			name:          "JWTMissingJWKSFile",
+			auth:          &Auth{JWT: &JWTAuth{JWKSFile: "missing.json"}},
+			expectedError: "open missing.json: no such file or directory",
+		},
+		{
+			name:          "JWTInvalidClaim",
+			auth:          &Auth{JWT: &JWTAuth{Secret: "secret", Claims: map[string]string{"sub": "["}}},
+			expectedError: "invalid pattern for claim sub: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name: "OK",
+			auth: &Auth{
+				Basic:  []BasicAuth{{Username: "jane", Password: "secret"}},
+				Bearer: []string{"token"},
+				APIKey: &APIKeyAuth{Header: "X-API-Key", Keys: []string{"key"}},
+				JWT:    &JWTAuth{Secret: "secret"},
+			},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.auth.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestAuthHandler(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	jwksFile := writeJWKS(t, map[string]crypto.PrivateKey{"rsa": rsaKey})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name                    string
+		auth                    *Auth
+		headers                 map[string]string
+		query                   string
+		expectedStatusCode      int
+		expectedWWWAuthenticate []string
+	}{
+		{
+			name:               "Nil",
+			auth:               nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:                    "BasicMissing",
+			auth:                    &Auth{Basic: []BasicAuth{{Username: "jane", Password: "secret"}}},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Basic realm="flax"`},
+		},
+		{
+			name:                    "BasicInvalid",
+			auth:                    &Auth{Realm: "api", Basic: []BasicAuth{{Username: "jane", Password: "secret"}}},
+			headers:                 map[string]string{"Authorization": "Basic amFuZTp3cm9uZw=="},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Basic realm="api"`},
+		},
+		{
+			name:               "BasicValid",
+			auth:               &Auth{Basic: []BasicAuth{{Username: "jane", Password: "secret"}}},
+			headers:            map[string]string{"Authorization": "Basic amFuZTpzZWNyZXQ="},
+			expectedStatusCode: 200,
+		},
+		{
+			name:                    "BearerInvalid",
+			auth:                    &Auth{Bearer: []string{"token"}},
+			headers:                 map[string]string{"Authorization": "Bearer wrong"},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="invalid_token", error_description="invalid token"`},
+		},
+		{
+			name:               "BearerValid",
+			auth:               &Auth{Bearer: []string{"token"}},
+			headers:            map[string]string{"Authorization": "Bearer token"},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "APIKeyHeader",
+			auth:               &Auth{APIKey: &APIKeyAuth{Header: "X-API-Key", Keys: []string{"key"}}},
+			headers:            map[string]string{"X-API-Key": "key"},
+			expectedStatusCode: 200,
+		},
+		{
+			name:                    "APIKeyQueryInvalid",
+			auth:                    &Auth{APIKey: &APIKeyAuth{Query: "api_key", Keys: []string{"key"}}},
+			query:                   "?api_key=wrong",
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`APIKey realm="flax", query="api_key"`},
+		},
+		{
+			name: "AnyScheme",
+			auth: &Auth{
+				Basic:  []BasicAuth{{Username: "jane", Password: "secret"}},
+				APIKey: &APIKeyAuth{Header: "X-API-Key", Keys: []string{"key"}},
+			},
+			headers:            map[string]string{"X-API-Key": "key"},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "AllChallenges",
+			auth: &Auth{
+				Basic:  []BasicAuth{{Username: "jane", Password: "secret"}},
+				Bearer: []string{"token"},
+				APIKey: &APIKeyAuth{Header: "X-API-Key", Keys: []string{"key"}},
+			},
+			expectedStatusCode: 401,
+			expectedWWWAuthenticate: []string{
+				`Basic realm="flax"`,
+				`Bearer realm="flax"`,
+				`APIKey realm="flax", header="X-API-Key"`,
+			},
+		},
+		{
+			name: "JWTSecret",
+			auth: &Auth{JWT: &JWTAuth{Secret: "secret"}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "HS256", "", []byte("secret"), JSON{"exp": exp}),
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "JWTInvalidSignature",
+			auth: &Auth{JWT: &JWTAuth{Secret: "secret"}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "HS256", "", []byte("wrong"), JSON{"exp": exp}),
+			},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="invalid_token", error_description="invalid signature"`},
+		},
+		{
+			name: "JWTInvalidIssuer",
+			auth: &Auth{JWT: &JWTAuth{JWKSFile: jwksFile, Issuer: "https://issuer"}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "RS256", "rsa", rsaKey, JSON{"iss": "https://other"}),
+			},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="invalid_token", error_description="invalid issuer"`},
+		},
+		{
+			name: "JWTInvalidAudience",
+			auth: &Auth{JWT: &JWTAuth{JWKSFile: jwksFile, Audience: "api"}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "RS256", "rsa", rsaKey, JSON{"aud": []string{"web"}}),
+			},
+			expectedStatusCode:      401,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="invalid_token", error_description="invalid audience"`},
+		},
+		{
+			name: "JWTInsufficientScope",
+			auth: &Auth{JWT: &JWTAuth{JWKSFile: jwksFile, Scopes: []string{"read", "write"}}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "RS256", "rsa", rsaKey, JSON{"scope": "read"}),
+			},
+			expectedStatusCode:      403,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="insufficient_scope", error_description="missing scope write", scope="read write"`},
+		},
+		{
+			name: "JWTClaimMismatch",
+			auth: &Auth{JWT: &JWTAuth{JWKSFile: jwksFile, Claims: map[string]string{"role": "^admin$"}}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "RS256", "rsa", rsaKey, JSON{"role": "user"}),
+			},
+			expectedStatusCode:      403,
+			expectedWWWAuthenticate: []string{`Bearer realm="flax", error="insufficient_scope", error_description="claim role does not match"`},
+		},
+		{
+			name: "JWTValid",
+			auth: &Auth{JWT: &JWTAuth{
+				JWKSFile: jwksFile,
+				Issuer:   "https://issuer",
+				Audience: "api",
+				Scopes:   []string{"read"},
+				Claims:   map[string]string{"role": "^admin$"},
+			}},
+			headers: map[string]string{
+				"Authorization": "Bearer " + signToken(t, "RS256", "rsa", rsaKey, JSON{
+					"iss":  "https://issuer",
+					"aud":  "api",
+					"scp":  []string{"read", "write"},
+					"role": "admin",
+					"exp":  exp,
+				}),
+			},
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.auth.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/v1/teams"+tc.query, nil)
+			for key, val := range tc.headers {
+				req.Header.Set(key, val)
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Equal(t, tc.expectedWWWAuthenticate, res.Result().Header["Www-Authenticate"])
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// callbackTimeout is how long a callback waits for a response on every attempt.
+	callbackTimeout = 10 * time.Second
+
+	// defaultCallbackBackoff is the wait before the first retry of a callback if no backoff is set.
+	defaultCallbackBackoff = time.Second
+)
+
+// WithDeliveryRecorder returns a new context which records deliveries of callbacks.
+func WithDeliveryRecorder(ctx context.Context, f func(CallbackDelivery)) context.Context {
+	return context.WithValue(ctx, deliveryRecorderKey, f)
+}
+
+func deliveryRecorder(ctx context.Context) func(CallbackDelivery) {
+	if f, ok := ctx.Value(deliveryRecorderKey).(func(CallbackDelivery)); ok {
+		return f
+	}
+
+	return func(CallbackDelivery) {}
+}
+
+// CallbackDelivery is the record of a callback sent by a mock.
+// A callback is delivered if it is responded with a 2xx status code in any attempt.
+type CallbackDelivery struct {
+	Time       time.Time           `json:"time" yaml:"time"`
+	Mock       string              `json:"mock" yaml:"mock"`
+	Method     string              `json:"method" yaml:"method"`
+	URL        string              `json:"url" yaml:"url"`
+	Headers    map[string][]string `json:"headers" yaml:"headers"`
+	Body       string              `json:"body" yaml:"body"`
+	Attempts   int                 `json:"attempts" yaml:"attempts"`
+	StatusCode int                 `json:"statusCode" yaml:"status_code"`
+	Error      string              `json:"error,omitempty" yaml:"error,omitempty"`
+	Delivered  bool                `json:"delivered" yaml:"delivered"`
+}
+
+// CallbackSignature represents signing the body of a callback with HMAC.
+// The signature is sent in Header as the algorithm and the hex-encoded HMAC (i.e. sha256=...).
+type CallbackSignature struct {
+	Secret    string `json:"secret" yaml:"secret"`
+	Header    string `json:"header" yaml:"header"`
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+}
+
+// SetDefaults set default values for empty fields.
+func (s *CallbackSignature) SetDefaults() {
+	if s == nil {
+		return
+	}
+
+	if s.Header == "" {
+		s.Header = "X-Signature"
+	}
+
+	if s.Algorithm == "" {
+		s.Algorithm = "sha256"
+	}
+}
+
+// Validate checks whether or not the signature is valid.
+func (s *CallbackSignature) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Secret == "" {
+		return errors.New("secret is required")
+	}
+
+	if s.hash() == nil {
+		return fmt.Errorf("unknown algorithm %s", s.Algorithm)
+	}
+
+	return nil
+}
+
+func (s *CallbackSignature) hash() func() hash.Hash {
+	switch s.Algorithm {
+	case "sha1":
+		return sha1.New
+	case "sha256":
+		return sha256.New
+	case "sha512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+// sign returns the signature of a body.
+func (s *CallbackSignature) sign(body []byte) string {
+	mac := hmac.New(s.hash(), []byte(s.Secret))
+	mac.Write(body)
+
+	return s.Algorithm + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HTTPCallback represents an http request sent by a mock after responding (i.e. a webhook).
+// URL, header values, and strings in Body are Go templates of the incoming request (see callbackRequest).
+// A string Body is sent as is, and any other Body is sent as JSON.
+// A callback failing with an error or a non-2xx status code is retried, and the wait doubles after every retry.
+type HTTPCallback struct {
+	Method    string             `json:"method" yaml:"method"`
+	URL       string             `json:"url" yaml:"url"`
+	Headers   map[string]string  `json:"headers" yaml:"headers"`
+	Body      interface{}        `json:"body" yaml:"body"`
+	Delay     string             `json:"delay" yaml:"delay"`
+	Retries   int                `json:"retries" yaml:"retries"`
+	Backoff   string             `json:"backoff" yaml:"backoff"`
+	Signature *CallbackSignature `json:"signature" yaml:"signature"`
+}
+
+// SetDefaults set default values for empty fields.
+func (c *HTTPCallback) SetDefaults() {
+	if c.Method == "" {
+		c.Method = "POST"
+	}
+
+	c.Signature.SetDefaults()
+}
+
+// Validate checks whether or not the callback is valid.
+func (c *HTTPCallback) Validate() error {
+	if c.URL == "" {
+		return errors.New("url is required")
+	}
+
+	if _, err := parseCallbackTemplate(c.URL); err != nil {
+		return fmt.Errorf("invalid url: %s", err)
+	}
+
+	for header, value := range c.Headers {
+		if _, err := parseCallbackTemplate(value); err != nil {
+			return fmt.Errorf("invalid header %s: %s", header, err)
+		}
+	}
+
+	if err := validateCallbackBody(c.Body); err != nil {
+		return fmt.Errorf("invalid body: %s", err)
+	}
+
+	if c.Delay != "" {
+		if _, err := time.ParseDuration(c.Delay); err != nil {
+			return fmt.Errorf("invalid delay: %s", err)
+		}
+	}
+
+	if c.Retries < 0 {
+		return errors.New("retries cannot be negative")
+	}
+
+	if c.Backoff != "" {
+		if _, err := time.ParseDuration(c.Backoff); err != nil {
+			return fmt.Errorf("invalid backoff: %s", err)
+		}
+	}
+
+	if err := c.Signature.Validate(); err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+
+	return nil
+}
+
+// request renders the callback for an incoming request.
+func (c *HTTPCallback) request(in *callbackRequest) (*CallbackDelivery, []byte, error) {
+	d := &CallbackDelivery{
+		Method:  c.Method,
+		Headers: map[string][]string{},
+	}
+
+	var err error
+	if d.URL, err = renderCallbackTemplate(c.URL, in); err != nil {
+		return d, nil, fmt.Errorf("invalid url: %s", err)
+	}
+
+	if u, err := url.Parse(d.URL); err != nil {
+		return d, nil, fmt.Errorf("invalid url: %s", err)
+	} else if !u.IsAbs() {
+		return d, nil, fmt.Errorf("invalid url: %s is not absolute", d.URL)
+	}
+
+	var body []byte
+	switch v := c.Body.(type) {
+	case nil:
+	case string:
+		s, err := renderCallbackTemplate(v, in)
+		if err != nil {
+			return d, nil, fmt.Errorf("invalid body: %s", err)
+		}
+		body = []byte(s)
+	default:
+		rendered, err := renderCallbackBody(v, in)
+		if err != nil {
+			return d, nil, fmt.Errorf("invalid body: %s", err)
+		}
+		if body, err = json.Marshal(rendered); err != nil {
+			return d, nil, fmt.Errorf("invalid body: %s", err)
+		}
+		d.Headers["Content-Type"] = []string{"application/json"}
+	}
+
+	d.Body = string(body)
+
+	for header, value := range c.Headers {
+		v, err := renderCallbackTemplate(value, in)
+		if err != nil {
+			return d, nil, fmt.Errorf("invalid header %s: %s", header, err)
+		}
+		d.Headers[http.CanonicalHeaderKey(header)] = []string{v}
+	}
+
+	if c.Signature != nil {
+		d.Headers[http.CanonicalHeaderKey(c.Signature.Header)] = []string{c.Signature.sign(body)}
+	}
+
+	return d, body, nil
+}
+
+// send delivers the callback for an incoming request, and retries until it is delivered or out of retries.
+func (c *HTTPCallback) send(client *http.Client, mock string, in *callbackRequest, record func(CallbackDelivery)) {
+	delay, _ := time.ParseDuration(c.Delay)
+	time.Sleep(delay)
+
+	backoff := defaultCallbackBackoff
+	if c.Backoff != "" {
+		backoff, _ = time.ParseDuration(c.Backoff)
+	}
+
+	d, body, err := c.request(in)
+	d.Time = time.Now()
+	d.Mock = mock
+
+	if err != nil {
+		d.Error = err.Error()
+		record(*d)
+		return
+	}
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		d.Attempts++
+
+		statusCode, err := deliver(client, d, body)
+		d.StatusCode = statusCode
+
+		if err != nil {
+			d.Error = err.Error()
+			continue
+		}
+
+		if statusCode >= 200 && statusCode < 300 {
+			d.Error = ""
+			d.Delivered = true
+			break
+		}
+
+		d.Error = fmt.Sprintf("unexpected status code %d", statusCode)
+	}
+
+	record(*d)
+}
+
+// deliver sends a single attempt of a callback and returns the status code of the response.
+func deliver(client *http.Client, d *CallbackDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(d.Method, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	for header, values := range d.Headers {
+		req.Header[header] = values
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// The response is drained, so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	return res.StatusCode, nil
+}
+
+// withCallbacks returns an http handler which sends callbacks after the next handler responds.
+// Callbacks are sent in the background, so they do not delay responses.
+func withCallbacks(mock string, callbacks []HTTPCallback, next http.Handler) http.Handler {
+	if len(callbacks) == 0 {
+		return next
+	}
+
+	client := &http.Client{
+		Timeout: callbackTimeout,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body is read before the next handler may consume it.
+		body, _ := readBody(r)
+		in := newCallbackRequest(r, body)
+		record := deliveryRecorder(r.Context())
+
+		next.ServeHTTP(w, r)
+
+		for i := range callbacks {
+			go callbacks[i].send(client, mock, in, record)
+		}
+	})
+}
+
+// callbackRequest is the data of callback templates, which is the incoming request.
+// For example, {{ .Method }}, {{ .Path }}, {{ .Query "id" }}, {{ .Header "X-Request-ID" }}, {{ .Var "id" }}, or {{ .JSON "order.id" }}.
+type callbackRequest struct {
+	Method string
+	Host   string
+	Path   string
+	Body   string
+
+	queries url.Values
+	headers http.Header
+	vars    map[string]string
+}
+
+func newCallbackRequest(r *http.Request, body []byte) *callbackRequest {
+	return &callbackRequest{
+		Method:  r.Method,
+		Host:    r.Host,
+		Path:    r.URL.Path,
+		Body:    string(body),
+		queries: r.URL.Query(),
+		headers: r.Header.Clone(),
+		vars:    mux.Vars(r),
+	}
+}
+
+// Query returns the first value of a query parameter.
+func (r *callbackRequest) Query(name string) string {
+	return r.queries.Get(name)
+}
+
+// Header returns the first value of a header.
+func (r *callbackRequest) Header(name string) string {
+	return r.headers.Get(name)
+}
+
+// Var returns a variable of the mock path, which is matched as a route pattern
+// (i.e. {{ .Var "id" }} for /orders/{id}). A path without the variable returns empty.
+func (r *callbackRequest) Var(name string) string {
+	return r.vars[name]
+}
+
+// JSON returns a field of a JSON body by a dotted path (i.e. items.0.id).
+// Objects and arrays are returned as JSON, and missing fields are returned empty.
+func (r *callbackRequest) JSON(path string) string {
+	decoder := json.NewDecoder(strings.NewReader(r.Body))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return ""
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = t[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return ""
+			}
+			v = t[i]
+		default:
+			return ""
+		}
+	}
+
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
+
+func parseCallbackTemplate(text string) (*template.Template, error) {
+	return template.New("callback").Parse(text)
+}
+
+func renderCallbackTemplate(text string, in *callbackRequest) (string, error) {
+	tmpl, err := parseCallbackTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, in); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// validateCallbackBody parses all templates in a body, and checks a non-string body can be sent as JSON.
+func validateCallbackBody(body interface{}) error {
+	if _, ok := body.(string); !ok && body != nil {
+		if _, err := json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	return walkCallbackBody(body)
+}
+
+func walkCallbackBody(body interface{}) error {
+	switch v := body.(type) {
+	case string:
+		_, err := parseCallbackTemplate(v)
+		return err
+	case JSON:
+		return walkCallbackBody(map[string]interface{}(v))
+	case map[string]interface{}:
+		for _, val := range v {
+			if err := walkCallbackBody(val); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if err := walkCallbackBody(val); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderCallbackBody renders all strings in a body as templates.
+func renderCallbackBody(body interface{}, in *callbackRequest) (interface{}, error) {
+	switch v := body.(type) {
+	case string:
+		return renderCallbackTemplate(v, in)
+	case JSON:
+		return renderCallbackBody(map[string]interface{}(v), in)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			r, err := renderCallbackBody(val, in)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = r
+		}
+		return m, nil
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, val := range v {
+			r, err := renderCallbackBody(val, in)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = r
+		}
+		return a, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,292 @@
+package spec
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPCallbackSetDefaults(t *testing.T) {
+	c := HTTPCallback{
+		URL:       "http://localhost:9000/webhooks",
+		Signature: &CallbackSignature{Secret: "secret"},
+	}
+
+	c.SetDefaults()
+
+	assert.Equal(t, "POST", c.Method)
+	assert.Equal(t, "X-Signature", c.Signature.Header)
+	assert.Equal(t, "sha256", c.Signature.Algorithm)
+}
+
+func TestHTTPCallbackValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		callback      HTTPCallback
+		expectedError string
+	}{
+		{
+			name:          "NoURL",
+			callback:      HTTPCallback{},
+			expectedError: "url is required",
+		},
+		{
+			name:          "InvalidURL",
+			callback:      HTTPCallback{URL: "http://localhost/{{ .Query }"},
+			expectedError: `invalid url: template: callback:1: unexpected "}" in operand`,
+		},
+		{
+			name:          "InvalidHeader",
+			callback:      HTTPCallback{URL: "http://localhost", Headers: map[string]string{"X-ID": "{{ .Var"}},
+			expectedError: "invalid header X-ID: template: callback:1: unclosed action",
+		},
+		{
+			name:          "InvalidBody",
+			callback:      HTTPCallback{URL: "http://localhost", Body: JSON{"items": []interface{}{"{{ end }}"}}},
+			expectedError: "invalid body: template: callback:1: unexpected {{end}}",
+		},
+		{
+			name:          "InvalidJSONBody",
+			callback:      HTTPCallback{URL: "http://localhost", Body: JSON{"f": func() {}}},
+			expectedError: "invalid body: json: unsupported type: func()",
+		},
+		{
+			name:          "InvalidDelay",
+			callback:      HTTPCallback{URL: "http://localhost", Delay: "soon"},
+			expectedError: `invalid delay: time: invalid duration "soon"`,
+		},
+		{
+			name:          "NegativeRetries",
+			callback:      HTTPCallback{URL: "http://localhost", Retries: -1},
+			expectedError: "retries cannot be negative",
+		},
+		{
+			name:          "InvalidBackoff",
+			callback:      HTTPCallback{URL: "http://localhost", Backoff: "later"},
+			expectedError: `invalid backoff: time: invalid duration "later"`,
+		},
+		{
+			name:          "NoSecret",
+			callback:      HTTPCallback{URL: "http://localhost", Signature: &CallbackSignature{Algorithm: "sha256"}},
+			expectedError: "invalid signature: secret is required",
+		},
+		{
+			name:          "UnknownAlgorithm",
+			callback:      HTTPCallback{URL: "http://localhost", Signature: &CallbackSignature{Secret: "secret", Algorithm: "md5"}},
+			expectedError: "invalid signature: unknown algorithm md5",
+		},
+		{
+			name: "Valid",
+			callback: HTTPCallback{
+				URL:       "http://localhost/orders/{{ .Var \"id\" }}",
+				Headers:   map[string]string{"X-Request-ID": `{{ .Header "X-Request-ID" }}`},
+				Body:      JSON{"id": `{{ .JSON "id" }}`, "status": "shipped"},
+				Delay:     "1s",
+				Retries:   3,
+				Backoff:   "100ms",
+				Signature: &CallbackSignature{Secret: "secret", Algorithm: "sha512"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.callback.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCallbackRequestJSON(t *testing.T) {
+	r := &callbackRequest{
+		Body: `{"id":"ord_1","amount":1250000,"paid":true,"items":[{"sku":"A1"}],"customer":{"name":"Jane"}}`,
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"id", "ord_1"},
+		{"amount", "1250000"},
+		{"paid", "true"},
+		{"items.0.sku", "A1"},
+		{"items.1.sku", ""},
+		{"items.first", ""},
+		{"customer", `{"name":"Jane"}`},
+		{"missing", ""},
+		{"id.nested", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, r.JSON(tc.path))
+		})
+	}
+
+	assert.Equal(t, "", (&callbackRequest{Body: "not json"}).JSON("id"))
+}
+
+func TestHTTPMockCallbacks(t *testing.T) {
+	var mutex sync.Mutex
+	received := []*http.Request{}
+	bodies := []string{}
+	failures := 2
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+
+		switch {
+		case r.URL.Path == "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case r.URL.Path == "/flaky" && failures > 0:
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	m := HTTPMock{
+		HTTPExpect: HTTPExpect{
+			Methods: []string{"POST"},
+			Path:    "/orders/{id}",
+		},
+		HTTPResponse: &HTTPResponse{
+			StatusCode: 202,
+		},
+		Callbacks: []HTTPCallback{
+			{
+				URL:     receiver.URL + `/orders/{{ .Var "id" }}?ref={{ .Query "ref" }}`,
+				Headers: map[string]string{"X-Request-ID": `{{ .Header "X-Request-ID" }}`},
+				Body: JSON{
+					"order":  `{{ .JSON "order.id" }}`,
+					"status": "shipped",
+					"tags":   []interface{}{"{{ .Method }}", 1},
+				},
+				Delay:     "10ms",
+				Signature: &CallbackSignature{Secret: "secret"},
+			},
+			{
+				Method:  "PUT",
+				URL:     receiver.URL + "/flaky",
+				Body:    "{{ .Body }}",
+				Retries: 2,
+				Backoff: "1ms",
+			},
+			{
+				URL:     receiver.URL + "/down",
+				Retries: 1,
+				Backoff: "1ms",
+			},
+			{
+				URL: "/relative",
+			},
+		},
+	}
+	m.SetDefaults()
+
+	var deliveries []CallbackDelivery
+	record := func(d CallbackDelivery) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		deliveries = append(deliveries, d)
+	}
+
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r.WithContext(WithDeliveryRecorder(r.Context(), record)))
+	}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest("POST", ts.URL+"/orders/42?ref=web", strings.NewReader(`{"order":{"id":"ord_42"}}`))
+	req.Header.Set("X-Request-ID", "abc")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, 202, res.StatusCode)
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(deliveries) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	byURL := map[string]CallbackDelivery{}
+	for _, d := range deliveries {
+		assert.Equal(t, "POST /orders/{id}", d.Mock)
+		byURL[d.Method+" "+d.URL] = d
+	}
+
+	// The templated and signed callback
+	d := byURL["POST "+receiver.URL+"/orders/42?ref=web"]
+	assert.True(t, d.Delivered)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, 200, d.StatusCode)
+	assert.Equal(t, `{"order":"ord_42","status":"shipped","tags":["POST",1]}`, d.Body)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(d.Body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	assert.Equal(t, []string{signature}, d.Headers["X-Signature"])
+
+	for i, r := range received {
+		if r.URL.Path == "/orders/42" {
+			assert.Equal(t, "abc", r.Header.Get("X-Request-ID"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, signature, r.Header.Get("X-Signature"))
+			assert.Equal(t, d.Body, bodies[i])
+		}
+	}
+
+	// The retried callback
+	d = byURL["PUT "+receiver.URL+"/flaky"]
+	assert.True(t, d.Delivered)
+	assert.Equal(t, 200, d.StatusCode)
+	assert.Equal(t, `{"order":{"id":"ord_42"}}`, d.Body)
+	assert.Empty(t, d.Error)
+
+	assert.Equal(t, 3, d.Attempts)
+
+	// The undelivered callback
+	d = byURL["POST "+receiver.URL+"/down"]
+	assert.False(t, d.Delivered)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Equal(t, 503, d.StatusCode)
+	assert.Equal(t, "unexpected status code 503", d.Error)
+
+	// A path variable not in the mock path
+	assert.Equal(t, "", (&callbackRequest{}).Var("id"))
+
+	// The callback with an invalid url
+	d = byURL["POST /relative"]
+	assert.False(t, d.Delivered)
+	assert.Equal(t, 0, d.Attempts)
+	assert.Equal(t, "invalid url: /relative is not absolute", d.Error)
+}
@@ -0,0 +1,265 @@
+package spec
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ChaosErrors represents the injection of error responses.
+// If StatusCodes is empty, errors are responded with 500.
+type ChaosErrors struct {
+	Percent     float64 `json:"percent" yaml:"percent"`
+	StatusCodes []int   `json:"status" yaml:"status"`
+}
+
+// ChaosLatency represents the injection of latency before responses.
+// The latency is a random duration between Min and Max.
+type ChaosLatency struct {
+	Percent float64 `json:"percent" yaml:"percent"`
+	Min     string  `json:"min" yaml:"min"`
+	Max     string  `json:"max" yaml:"max"`
+}
+
+// ChaosDrops represents the injection of dropped connections with no response.
+type ChaosDrops struct {
+	Percent float64 `json:"percent" yaml:"percent"`
+}
+
+// chaosState keeps the random source and whether or not chaos is enabled at runtime.
+type chaosState struct {
+	mutex   sync.Mutex
+	enabled bool
+	rand    *rand.Rand
+}
+
+// Chaos represents the random injection of failures into the responses of mocks.
+// If Tags is empty, all mocks are affected. Otherwise, only mocks with any of the tags are affected.
+// If Seed is zero, a random seed is used.
+type Chaos struct {
+	Disabled bool          `json:"disabled" yaml:"disabled"`
+	Seed     int64         `json:"seed" yaml:"seed"`
+	Tags     []string      `json:"tags" yaml:"tags"`
+	Errors   *ChaosErrors  `json:"errors" yaml:"errors"`
+	Latency  *ChaosLatency `json:"latency" yaml:"latency"`
+	Drops    *ChaosDrops   `json:"drops" yaml:"drops"`
+
+	state *chaosState
+}
+
+// SetDefaults set default values for empty fields.
+// It also creates the state shared by all mocks affected by chaos.
+// If c is nil, nothing is set.
+func (c *Chaos) SetDefaults() {
+	if c == nil {
+		return
+	}
+
+	if c.Errors != nil && len(c.Errors.StatusCodes) == 0 {
+		c.Errors.StatusCodes = []int{http.StatusInternalServerError}
+	}
+
+	if c.Latency != nil && c.Latency.Max == "" {
+		c.Latency.Max = c.Latency.Min
+	}
+
+	if c.state == nil {
+		seed := c.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		c.state = &chaosState{
+			enabled: !c.Disabled,
+			rand:    rand.New(rand.NewSource(seed)),
+		}
+	}
+}
+
+func validatePercent(name string, percent float64) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("%s percent should be between 0 and 100", name)
+	}
+	return nil
+}
+
+// Validate checks whether or not the chaos configuration is valid.
+// If c is nil, it is valid.
+func (c *Chaos) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Errors != nil {
+		if err := validatePercent("error", c.Errors.Percent); err != nil {
+			return err
+		}
+
+		for _, statusCode := range c.Errors.StatusCodes {
+			if statusCode < 100 || statusCode > 599 {
+				return fmt.Errorf("invalid error status code: %d", statusCode)
+			}
+		}
+	}
+
+	if c.Latency != nil {
+		if err := validatePercent("latency", c.Latency.Percent); err != nil {
+			return err
+		}
+
+		min, err := time.ParseDuration(c.Latency.Min)
+		if err != nil {
+			return fmt.Errorf("invalid min latency: %s", c.Latency.Min)
+		}
+
+		max, err := time.ParseDuration(c.Latency.Max)
+		if err != nil || max < min {
+			return fmt.Errorf("invalid max latency: %s", c.Latency.Max)
+		}
+	}
+
+	if c.Drops != nil {
+		if err := validatePercent("drop", c.Drops.Percent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Selects determines whether or not chaos affects a mock with the given tags.
+// If c is nil, no mock is affected.
+func (c *Chaos) Selects(tags []string) bool {
+	if c == nil {
+		return false
+	}
+
+	if len(c.Tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		if containsString(c.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Enabled determines whether or not chaos is currently enabled.
+// If c is nil, it is not enabled.
+func (c *Chaos) Enabled() bool {
+	if c == nil || c.state == nil {
+		return false
+	}
+
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	return c.state.enabled
+}
+
+// SetEnabled enables or disables chaos at runtime.
+func (c *Chaos) SetEnabled(enabled bool) {
+	c.SetDefaults()
+
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	c.state.enabled = enabled
+}
+
+// chaosActions are the failures decided for a request.
+type chaosActions struct {
+	drop       bool
+	latency    time.Duration
+	statusCode int
+}
+
+// decide rolls the dice for each kind of failure.
+func (c *Chaos) decide() (chaosActions, bool) {
+	s := c.state
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var a chaosActions
+
+	if !s.enabled {
+		return a, false
+	}
+
+	roll := func(percent float64) bool {
+		return s.rand.Float64()*100 < percent
+	}
+
+	if c.Drops != nil && roll(c.Drops.Percent) {
+		a.drop = true
+		return a, true
+	}
+
+	if c.Latency != nil && roll(c.Latency.Percent) {
+		min, _ := time.ParseDuration(c.Latency.Min)
+		max, _ := time.ParseDuration(c.Latency.Max)
+		a.latency = min
+		if max > min {
+			a.latency += time.Duration(s.rand.Int63n(int64(max - min)))
+		}
+	}
+
+	if c.Errors != nil && roll(c.Errors.Percent) {
+		a.statusCode = c.Errors.StatusCodes[s.rand.Intn(len(c.Errors.StatusCodes))]
+	}
+
+	return a, true
+}
+
+// drop closes the connection of a request with no response.
+func drop(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+
+	// The server aborts the response and closes the connection.
+	panic(http.ErrAbortHandler)
+}
+
+// Handler wraps an http handler for injecting failures while chaos is enabled.
+// If c is nil, the handler is returned unchanged.
+func (c *Chaos) Handler(h http.Handler) http.Handler {
+	if c == nil {
+		return h
+	}
+
+	// Chaos configurations not read from a spec have no state yet.
+	c.SetDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, enabled := c.decide()
+		if !enabled {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if a.drop {
+			drop(w)
+			return
+		}
+
+		time.Sleep(a.latency)
+
+		if a.statusCode != 0 {
+			writeJSON(w, a.statusCode, JSON{
+				"message": "chaos: injected failure",
+			})
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,187 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaosSetDefaults(t *testing.T) {
+	var nilChaos *Chaos
+	nilChaos.SetDefaults()
+	assert.Nil(t, nilChaos)
+
+	c := &Chaos{
+		Disabled: true,
+		Errors:   &ChaosErrors{Percent: 10},
+		Latency:  &ChaosLatency{Percent: 10, Min: "1s"},
+	}
+	c.SetDefaults()
+
+	assert.Equal(t, []int{500}, c.Errors.StatusCodes)
+	assert.Equal(t, "1s", c.Latency.Max)
+	assert.NotNil(t, c.state)
+	assert.False(t, c.Enabled())
+}
+
+func TestChaosValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		chaos         *Chaos
+		expectedError string
+	}{
+		{
+			name:          "Nil",
+			chaos:         nil,
+			expectedError: "",
+		},
+		{
+			name:          "InvalidErrorPercent",
+			chaos:         &Chaos{Errors: &ChaosErrors{Percent: 120, StatusCodes: []int{500}}},
+			expectedError: "error percent should be between 0 and 100",
+		},
+		{
+			name:          "InvalidStatusCode",
+			chaos:         &Chaos{Errors: &ChaosErrors{Percent: 10, StatusCodes: []int{5000}}},
+			expectedError: "invalid error status code: 5000",
+		},
+		{
+			name:          "InvalidLatencyPercent",
+			chaos:         &Chaos{Latency: &ChaosLatency{Percent: -1, Min: "1s", Max: "1s"}},
+			expectedError: "latency percent should be between 0 and 100",
+		},
+		{
+			name:          "InvalidMinLatency",
+			chaos:         &Chaos{Latency: &ChaosLatency{Percent: 10, Min: "1", Max: "1s"}},
+			expectedError: "invalid min latency: 1",
+		},
+		{
+			name:          "InvalidMaxLatency",
+			chaos:         &Chaos{Latency: &ChaosLatency{Percent: 10, Min: "2s", Max: "1s"}},
+			expectedError: "invalid max latency: 1s",
+		},
+		{
+			name:          "InvalidDropPercent",
+			chaos:         &Chaos{Drops: &ChaosDrops{Percent: 101}},
+			expectedError: "drop percent should be between 0 and 100",
+		},
+		{
+			name: "OK",
+			chaos: &Chaos{
+				Errors:  &ChaosErrors{Percent: 10, StatusCodes: []int{500, 503}},
+				Latency: &ChaosLatency{Percent: 10, Min: "100ms", Max: "1s"},
+				Drops:   &ChaosDrops{Percent: 1},
+			},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.chaos.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestChaosSelects(t *testing.T) {
+	tests := []struct {
+		name           string
+		chaos          *Chaos
+		tags           []string
+		expectedResult bool
+	}{
+		{"Nil", nil, []string{"flaky"}, false},
+		{"NoTags", &Chaos{}, nil, true},
+		{"Tagged", &Chaos{Tags: []string{"flaky", "slow"}}, []string{"slow"}, true},
+		{"NotTagged", &Chaos{Tags: []string{"flaky"}}, []string{"stable"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResult, tc.chaos.Selects(tc.tags))
+		})
+	}
+}
+
+func TestChaosSetEnabled(t *testing.T) {
+	c := &Chaos{}
+	assert.False(t, c.Enabled())
+
+	c.SetEnabled(true)
+	assert.True(t, c.Enabled())
+
+	c.SetEnabled(false)
+	assert.False(t, c.Enabled())
+}
+
+func TestChaosHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		chaos              *Chaos
+		expectedStatusCode int
+		expectedMinLatency time.Duration
+	}{
+		{
+			name:               "Nil",
+			chaos:              nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Disabled",
+			chaos:              &Chaos{Disabled: true, Errors: &ChaosErrors{Percent: 100}},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "NoFailure",
+			chaos:              &Chaos{Seed: 1, Errors: &ChaosErrors{Percent: 0}},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Errors",
+			chaos:              &Chaos{Seed: 1, Errors: &ChaosErrors{Percent: 100, StatusCodes: []int{503}}},
+			expectedStatusCode: 503,
+		},
+		{
+			name:               "Latency",
+			chaos:              &Chaos{Seed: 1, Latency: &ChaosLatency{Percent: 100, Min: "10ms", Max: "20ms"}},
+			expectedStatusCode: 200,
+			expectedMinLatency: 10 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.chaos.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			res := httptest.NewRecorder()
+			start := time.Now()
+			handler.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.True(t, time.Since(start) >= tc.expectedMinLatency)
+		})
+	}
+
+	t.Run("Drops", func(t *testing.T) {
+		c := &Chaos{Seed: 1, Drops: &ChaosDrops{Percent: 100}}
+		handler := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		// The recorder cannot be hijacked, so the response is aborted.
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+	})
+}
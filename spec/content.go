@@ -0,0 +1,171 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HTTPContent represents an http response body for a media type.
+// Only one of Body, BodyFile, BodyText, BodyBase64, or BodyXML can be set.
+// Body is encoded as JSON and BodyFile is relative to the spec file defining it.
+type HTTPContent struct {
+	Type       string      `json:"type" yaml:"type"`
+	Body       interface{} `json:"body" yaml:"body"`
+	BodyFile   string      `json:"bodyFile" yaml:"body_file"`
+	BodyText   string      `json:"bodyText" yaml:"body_text"`
+	BodyBase64 string      `json:"bodyBase64" yaml:"body_base64"`
+	BodyXML    string      `json:"bodyXML" yaml:"body_xml"`
+}
+
+func (c *HTTPContent) empty() bool {
+	return c.Body == nil && c.BodyFile == "" && c.BodyText == "" && c.BodyBase64 == "" && c.BodyXML == ""
+}
+
+// Validate checks whether or not the content is valid.
+func (c *HTTPContent) Validate() error {
+	count := 0
+	for _, set := range []bool{c.Body != nil, c.BodyFile != "", c.BodyText != "", c.BodyBase64 != "", c.BodyXML != ""} {
+		if set {
+			count++
+		}
+	}
+
+	if count > 1 {
+		return errors.New("only one of body, body_file, body_text, body_base64, or body_xml can be set")
+	}
+
+	if c.BodyFile != "" {
+		if _, err := os.Stat(c.BodyFile); err != nil {
+			return fmt.Errorf("invalid body_file: %s", err)
+		}
+	}
+
+	if c.BodyBase64 != "" {
+		if _, err := base64.StdEncoding.DecodeString(c.BodyBase64); err != nil {
+			return fmt.Errorf("invalid body_base64: %s", err)
+		}
+	}
+
+	if c.BodyXML != "" {
+		if err := validXML(c.BodyXML); err != nil {
+			return fmt.Errorf("invalid body_xml: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// read returns the body and the content type inferred for it if any.
+func (c *HTTPContent) read() ([]byte, string, error) {
+	switch {
+	case c.BodyFile != "":
+		b, err := ioutil.ReadFile(c.BodyFile)
+		return b, mime.TypeByExtension(filepath.Ext(c.BodyFile)), err
+
+	case c.BodyText != "":
+		return []byte(c.BodyText), "", nil
+
+	case c.BodyBase64 != "":
+		b, err := base64.StdEncoding.DecodeString(c.BodyBase64)
+		return b, "", err
+
+	case c.BodyXML != "":
+		return []byte(c.BodyXML), "application/xml", nil
+
+	default:
+		buf := new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(c.Body)
+		return buf.Bytes(), "", err
+	}
+}
+
+// mediaRange is a media range in an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses the media ranges in an Accept header.
+func parseAccept(accept string) []mediaRange {
+	ranges := []mediaRange{}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType, q})
+	}
+
+	return ranges
+}
+
+// quality returns the quality of a media type for a list of media ranges.
+// The most specific media range matching the media type determines the quality.
+func quality(mediaType string, ranges []mediaRange) float64 {
+	q, specificity := 0.0, -1
+	slash := strings.Index(mediaType, "/")
+
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mediaType == mediaType:
+			s = 2
+		case slash > 0 && r.mediaType == mediaType[:slash]+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
+
+// negotiate returns the index of the best content type for an Accept header.
+// If multiple content types are equally acceptable, the first one is chosen.
+// If none of the content types is acceptable, it returns -1.
+func negotiate(accept string, types []string) int {
+	if strings.TrimSpace(accept) == "" {
+		return 0
+	}
+
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return 0
+	}
+
+	best, bestQ := -1, 0.0
+	for i, t := range types {
+		mediaType, _, err := mime.ParseMediaType(t)
+		if err != nil {
+			mediaType = strings.ToLower(t)
+		}
+
+		if q := quality(mediaType, ranges); q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,70 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		expectedRanges []mediaRange
+	}{
+		{
+			name:           "Empty",
+			accept:         "",
+			expectedRanges: []mediaRange{},
+		},
+		{
+			name:   "OK",
+			accept: "text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8",
+			expectedRanges: []mediaRange{
+				{"text/html", 1},
+				{"application/xhtml+xml", 1},
+				{"application/xml", 0.9},
+				{"*/*", 0.8},
+			},
+		},
+		{
+			name:   "Invalid",
+			accept: "text/html;q=high, application/json",
+			expectedRanges: []mediaRange{
+				{"application/json", 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRanges, parseAccept(tc.accept))
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	types := []string{"application/json", "application/xml", "text/plain; charset=utf-8"}
+
+	tests := []struct {
+		name          string
+		accept        string
+		types         []string
+		expectedIndex int
+	}{
+		{"Empty", "", types, 0},
+		{"Any", "*/*", types, 0},
+		{"Exact", "text/plain", types, 2},
+		{"Wildcard", "text/*", types, 2},
+		{"Quality", "application/json;q=0.2, application/xml;q=0.4", types, 1},
+		{"Specificity", "application/*;q=0.5, application/xml;q=0, */*;q=0.1", types, 0},
+		{"NotAcceptable", "image/png", types, -1},
+		{"Excluded", "application/json;q=0, */*;q=0", types, -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedIndex, negotiate(tc.accept, tc.types))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// MockInfo represents a registered mock.
+type MockInfo struct {
+	ID   string `json:"id" yaml:"id"`
+	Mock string `json:"mock" yaml:"mock"`
+}
+
+// ChaosStatus represents whether or not chaos is enabled at runtime.
+type ChaosStatus struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+// Request represents a recorded http request.
+type Request struct {
+	Time     time.Time           `json:"time" yaml:"time"`
+	Listener string              `json:"listener" yaml:"listener"`
+	Host     string              `json:"host" yaml:"host"`
+	Method   string              `json:"method" yaml:"method"`
+	Path     string              `json:"path" yaml:"path"`
+	Queries  map[string][]string `json:"queries" yaml:"queries"`
+	Headers  map[string][]string `json:"headers" yaml:"headers"`
+	Body     string              `json:"body" yaml:"body"`
+	Form     map[string][]string `json:"form,omitempty" yaml:"form,omitempty"`
+	Files    []FormFile          `json:"files,omitempty" yaml:"files,omitempty"`
+	Mock     string              `json:"mock" yaml:"mock"`
+}
+
+// Match determines whether or not a recorded request satisfies an http expectation.
+// Empty fields in the expectation match any value.
+func (e HTTPExpect) Match(r Request) bool {
+	return len(e.Diagnose(r).Mismatches) == 0
+}
+
+// patterns caches the compiled regular expressions of the expectations.
+var patterns sync.Map
+
+// compilePattern compiles a regular expression once and caches it for matching.
+// Expectations compile their patterns when validated, so invalid patterns are rejected before matching.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+// compilePatterns compiles the regular expressions of a map of patterns by name.
+// If anchor is true, the patterns are compiled to match whole values.
+func compilePatterns(kind string, exprs map[string]string, anchor bool) error {
+	for _, name := range sortedKeys(exprs) {
+		pattern := exprs[name]
+		if anchor {
+			pattern = "^" + pattern + "$"
+		}
+
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid %s %s: %s", kind, name, err)
+		}
+	}
+
+	return nil
+}
+
+func matchAny(pattern string, values []string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+
+	for _, val := range values {
+		if re.MatchString(val) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Verification represents an expectation about the requests received by the mock server.
+type Verification struct {
+	HTTPExpect `json:",inline" yaml:",inline"`
+	AtLeast    int  `json:"atLeast" yaml:"at_least"`
+	AtMost     *int `json:"atMost" yaml:"at_most"`
+}
+
+// SetDefaults set default values for empty fields.
+func (v *Verification) SetDefaults() {
+	if v.AtLeast == 0 && v.AtMost == nil {
+		v.AtLeast = 1
+	}
+}
+
+// VerificationResult is the outcome of a verification.
+// Failures are the requests not matching any mock in strict mode.
+type VerificationResult struct {
+	Verified bool      `json:"verified" yaml:"verified"`
+	Count    int       `json:"count" yaml:"count"`
+	Requests []Request `json:"requests" yaml:"requests"`
+	Failures []Request `json:"failures,omitempty" yaml:"failures,omitempty"`
+}
+
+// Verify checks a verification against a list of recorded requests.
+func (v Verification) Verify(requests []Request) VerificationResult {
+	matched := []Request{}
+	for _, r := range requests {
+		if v.HTTPExpect.Match(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	count := len(matched)
+	verified := count >= v.AtLeast && (v.AtMost == nil || count <= *v.AtMost)
+
+	return VerificationResult{
+		Verified: verified,
+		Count:    count,
+		Requests: matched,
+	}
+}
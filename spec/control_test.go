@@ -0,0 +1,202 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPExpectMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		expect        HTTPExpect
+		request       Request
+		expectedMatch bool
+	}{
+		{
+			name:          "Empty",
+			expect:        HTTPExpect{},
+			request:       Request{Method: "GET", Path: "/health"},
+			expectedMatch: true,
+		},
+		{
+			name: "MethodMismatch",
+			expect: HTTPExpect{
+				Methods: []string{"POST", "PUT"},
+			},
+			request:       Request{Method: "GET", Path: "/health"},
+			expectedMatch: false,
+		},
+		{
+			name: "PathMismatch",
+			expect: HTTPExpect{
+				Path: "/api/v1/sendMessage",
+			},
+			request:       Request{Method: "GET", Path: "/health"},
+			expectedMatch: false,
+		},
+		{
+			name: "PrefixMatch",
+			expect: HTTPExpect{
+				Path:   "/api",
+				Prefix: true,
+			},
+			request:       Request{Method: "GET", Path: "/api/v1/sendMessage"},
+			expectedMatch: true,
+		},
+		{
+			name: "QueryMismatch",
+			expect: HTTPExpect{
+				Queries: map[string]string{
+					"tenantId": "[0-9A-Fa-f-]+",
+				},
+			},
+			request: Request{
+				Method: "GET",
+				Path:   "/api/v1/sendMessage",
+				Queries: map[string][]string{
+					"tenantId": {"tenant!"},
+				},
+			},
+			expectedMatch: false,
+		},
+		{
+			name: "HeaderMismatch",
+			expect: HTTPExpect{
+				Headers: map[string]string{
+					"Authorization": "Bearer .*",
+				},
+			},
+			request: Request{
+				Method: "GET",
+				Path:   "/api/v1/sendMessage",
+				Headers: map[string][]string{
+					"Authorization": {"Basic dXNlcjpwYXNz"},
+				},
+			},
+			expectedMatch: false,
+		},
+		{
+			name: "FullMatch",
+			expect: HTTPExpect{
+				Methods: []string{"POST", "PUT"},
+				Path:    "/api/v1/sendMessage",
+				Queries: map[string]string{
+					"tenantId": "[0-9A-Fa-f-]+",
+				},
+				Headers: map[string]string{
+					"Authorization": "Bearer .*",
+				},
+			},
+			request: Request{
+				Method: "POST",
+				Path:   "/api/v1/sendMessage",
+				Queries: map[string][]string{
+					"tenantId": {"11111111-1111-1111-1111-111111111111"},
+				},
+				Headers: map[string][]string{
+					"Authorization": {"Bearer token"},
+				},
+			},
+			expectedMatch: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMatch, tc.expect.Match(tc.request))
+		})
+	}
+}
+
+func TestVerificationSetDefaults(t *testing.T) {
+	zero := 0
+
+	tests := []struct {
+		name                 string
+		verification         Verification
+		expectedVerification Verification
+	}{
+		{
+			"Empty",
+			Verification{},
+			Verification{AtLeast: 1},
+		},
+		{
+			"WithAtMost",
+			Verification{AtMost: &zero},
+			Verification{AtMost: &zero},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.verification.SetDefaults()
+			assert.Equal(t, tc.expectedVerification, tc.verification)
+		})
+	}
+}
+
+func TestVerificationVerify(t *testing.T) {
+	zero, one := 0, 1
+
+	requests := []Request{
+		{Method: "GET", Path: "/health"},
+		{Method: "POST", Path: "/api/v1/sendMessage"},
+		{Method: "POST", Path: "/api/v1/sendMessage"},
+	}
+
+	tests := []struct {
+		name           string
+		verification   Verification
+		requests       []Request
+		expectedResult VerificationResult
+	}{
+		{
+			name: "AtLeast",
+			verification: Verification{
+				HTTPExpect: HTTPExpect{Path: "/api/v1/sendMessage"},
+				AtLeast:    1,
+			},
+			requests: requests,
+			expectedResult: VerificationResult{
+				Verified: true,
+				Count:    2,
+				Requests: requests[1:],
+			},
+		},
+		{
+			name: "AtMost",
+			verification: Verification{
+				HTTPExpect: HTTPExpect{Path: "/api/v1/sendMessage"},
+				AtMost:     &one,
+			},
+			requests: requests,
+			expectedResult: VerificationResult{
+				Verified: false,
+				Count:    2,
+				Requests: requests[1:],
+			},
+		},
+		{
+			name: "Never",
+			verification: Verification{
+				HTTPExpect: HTTPExpect{Path: "/api/v1/teams"},
+				AtMost:     &zero,
+			},
+			requests: requests,
+			expectedResult: VerificationResult{
+				Verified: true,
+				Count:    0,
+				Requests: []Request{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.verification.Verify(tc.requests)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
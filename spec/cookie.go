@@ -0,0 +1,108 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// HTTPCookie represents a cookie set by a mock http response.
+// Expires is either an RFC 3339 time or a duration from the time of the response (i.e. 1h).
+// SameSite is one of lax, strict, or none.
+type HTTPCookie struct {
+	Name     string `json:"name" yaml:"name"`
+	Value    string `json:"value" yaml:"value"`
+	Path     string `json:"path" yaml:"path"`
+	Domain   string `json:"domain" yaml:"domain"`
+	Expires  string `json:"expires" yaml:"expires"`
+	MaxAge   int    `json:"maxAge" yaml:"max_age"`
+	HTTPOnly bool   `json:"httpOnly" yaml:"http_only"`
+	Secure   bool   `json:"secure" yaml:"secure"`
+	SameSite string `json:"sameSite" yaml:"same_site"`
+}
+
+var sameSiteModes = map[string]http.SameSite{
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+// expires returns the expiry time of the cookie for a response at a given time.
+func (c HTTPCookie) expires(now time.Time) (time.Time, error) {
+	if c.Expires == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(c.Expires); err == nil {
+		return now.Add(d), nil
+	}
+
+	return time.Parse(time.RFC3339, c.Expires)
+}
+
+// Validate checks whether or not the cookie is valid.
+func (c HTTPCookie) Validate() error {
+	if c.Name == "" {
+		return errors.New("cookie name is required")
+	}
+
+	if _, err := c.expires(time.Now()); err != nil {
+		return fmt.Errorf("invalid cookie expires: %s", c.Expires)
+	}
+
+	if _, ok := sameSiteModes[strings.ToLower(c.SameSite)]; c.SameSite != "" && !ok {
+		return fmt.Errorf("invalid cookie same_site: %s", c.SameSite)
+	}
+
+	return nil
+}
+
+// cookie creates an http cookie for a response at a given time.
+func (c HTTPCookie) cookie(now time.Time) *http.Cookie {
+	expires, _ := c.expires(now)
+
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Expires:  expires,
+		MaxAge:   c.MaxAge,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: sameSiteModes[strings.ToLower(c.SameSite)],
+	}
+}
+
+// cookieValues returns the values of a cookie in a set of request headers.
+func cookieValues(headers http.Header, name string) []string {
+	req := &http.Request{Header: headers}
+
+	values := []string{}
+	for _, c := range req.Cookies() {
+		if c.Name == name {
+			values = append(values, c.Value)
+		}
+	}
+
+	return values
+}
+
+// cookieMatcher creates a route matcher for a request cookie matching a regular expression.
+func cookieMatcher(name, pattern string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return matchAny(pattern, cookieValues(r.Header, name))
+	}
+}
+
+// noCookieMatcher creates a route matcher for a request without a cookie.
+func noCookieMatcher(name string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		_, err := r.Cookie(name)
+		return err == http.ErrNoCookie
+	}
+}
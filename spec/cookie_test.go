@@ -0,0 +1,176 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPCookieValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		cookie        HTTPCookie
+		expectedError string
+	}{
+		{
+			name:          "NoName",
+			cookie:        HTTPCookie{Value: "aaaa"},
+			expectedError: "cookie name is required",
+		},
+		{
+			name:          "InvalidExpires",
+			cookie:        HTTPCookie{Name: "session", Expires: "tomorrow"},
+			expectedError: "invalid cookie expires: tomorrow",
+		},
+		{
+			name:          "InvalidSameSite",
+			cookie:        HTTPCookie{Name: "session", SameSite: "loose"},
+			expectedError: "invalid cookie same_site: loose",
+		},
+		{
+			name:          "WithDuration",
+			cookie:        HTTPCookie{Name: "session", Expires: "1h", SameSite: "Lax"},
+			expectedError: "",
+		},
+		{
+			name:          "WithTime",
+			cookie:        HTTPCookie{Name: "session", Expires: "2030-01-01T00:00:00Z", SameSite: "strict"},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cookie.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestHTTPCookieCookie(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		cookie         HTTPCookie
+		expectedCookie *http.Cookie
+	}{
+		{
+			name:   "Session",
+			cookie: HTTPCookie{Name: "session", Value: "aaaa"},
+			expectedCookie: &http.Cookie{
+				Name:  "session",
+				Value: "aaaa",
+			},
+		},
+		{
+			name: "WithAttributes",
+			cookie: HTTPCookie{
+				Name:     "session",
+				Value:    "aaaa",
+				Path:     "/app",
+				Domain:   "example.com",
+				Expires:  "1h",
+				MaxAge:   3600,
+				HTTPOnly: true,
+				Secure:   true,
+				SameSite: "none",
+			},
+			expectedCookie: &http.Cookie{
+				Name:     "session",
+				Value:    "aaaa",
+				Path:     "/app",
+				Domain:   "example.com",
+				Expires:  now.Add(time.Hour),
+				MaxAge:   3600,
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteNoneMode,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCookie, tc.cookie.cookie(now))
+		})
+	}
+}
+
+func TestHTTPMockRouteCookies(t *testing.T) {
+	mock := HTTPMock{
+		HTTPExpect: HTTPExpect{
+			Methods: []string{"GET"},
+			Path:    "/account",
+			Cookies: map[string]string{
+				"session": "^[0-9a-f]+$",
+			},
+			NoCookies: []string{"impersonate"},
+		},
+		HTTPResponse: &HTTPResponse{
+			StatusCode: 200,
+			Cookies: []HTTPCookie{
+				{Name: "last_seen", Value: "now", Path: "/", HTTPOnly: true, SameSite: "lax"},
+			},
+		},
+	}
+
+	router := mux.NewRouter()
+	mock.RegisterRoutes(router)
+
+	tests := []struct {
+		name               string
+		cookies            []*http.Cookie
+		expectedStatusCode int
+		expectedSetCookie  string
+	}{
+		{
+			name:               "NoCookie",
+			cookies:            nil,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Mismatch",
+			cookies:            []*http.Cookie{{Name: "session", Value: "not-hex"}},
+			expectedStatusCode: 404,
+		},
+		{
+			name: "Present",
+			cookies: []*http.Cookie{
+				{Name: "session", Value: "abcd"},
+				{Name: "impersonate", Value: "admin"},
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Match",
+			cookies:            []*http.Cookie{{Name: "session", Value: "abcd"}},
+			expectedStatusCode: 200,
+			expectedSetCookie:  "last_seen=now; Path=/; HttpOnly; SameSite=Lax",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/account", nil)
+			for _, c := range tc.cookies {
+				req.AddCookie(c)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Equal(t, tc.expectedSetCookie, res.Result().Header.Get("Set-Cookie"))
+		})
+	}
+}
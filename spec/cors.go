@@ -0,0 +1,164 @@
+package spec
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CORS represents the cross-origin resource sharing settings.
+// Origins can be exact origins, * for any origin, or patterns with * wildcards (i.e. https://*.example.com).
+// If Methods is empty, the methods of the mock are allowed.
+// If Headers is empty, any request header is allowed.
+type CORS struct {
+	Origins        []string `json:"origins" yaml:"origins"`
+	Methods        []string `json:"methods" yaml:"methods"`
+	Headers        []string `json:"headers" yaml:"headers"`
+	ExposedHeaders []string `json:"exposedHeaders" yaml:"exposed_headers"`
+	Credentials    bool     `json:"credentials" yaml:"credentials"`
+	MaxAge         int      `json:"maxAge" yaml:"max_age"`
+}
+
+// allowsOrigin determines whether or not an origin is allowed.
+func (c *CORS) allowsOrigin(origin string) bool {
+	if len(c.Origins) == 0 {
+		return true
+	}
+
+	for _, pattern := range c.Origins {
+		if matchGlob(pattern, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyOrigin determines whether or not any origin is allowed with no credentials.
+func (c *CORS) anyOrigin() bool {
+	if c.Credentials {
+		return false
+	}
+
+	if len(c.Origins) == 0 {
+		return true
+	}
+
+	for _, pattern := range c.Origins {
+		if pattern == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsHeaders determines whether or not all headers requested in a preflight request are allowed.
+func (c *CORS) allowsHeaders(headers []string) bool {
+	if len(c.Headers) == 0 {
+		return true
+	}
+
+	for _, header := range headers {
+		if !containsFold(c.Headers, header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setOrigin sets the common cors headers for an allowed origin.
+func (c *CORS) setOrigin(w http.ResponseWriter, origin string) {
+	if c.anyOrigin() {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	if c.Credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// Handler wraps an http handler for actual cross-origin requests.
+// If c is nil, the handler is returned unchanged.
+func (c *CORS) Handler(h http.Handler) http.Handler {
+	if c == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && c.allowsOrigin(origin) {
+			c.setOrigin(w, origin)
+			if len(c.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RegisterPreflight configures a route for answering preflight requests.
+// A preflight route is only matched if the requested method is one of the given methods.
+// If c is nil, no route is configured.
+func (c *CORS) RegisterPreflight(router *mux.Router, methods []string) *mux.Route {
+	if c == nil {
+		return nil
+	}
+
+	allowed := c.Methods
+	if len(allowed) == 0 {
+		allowed = methods
+	}
+
+	quoted := make([]string, len(methods))
+	for i, method := range methods {
+		quoted[i] = regexp.QuoteMeta(method)
+	}
+
+	route := router.NewRoute()
+	route.Methods("OPTIONS")
+	route.HeadersRegexp("Origin", ".+", "Access-Control-Request-Method", "^(?:"+strings.Join(quoted, "|")+")$")
+	route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		method := r.Header.Get("Access-Control-Request-Method")
+
+		requested := []string{}
+		for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				requested = append(requested, header)
+			}
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+		if !c.allowsOrigin(origin) || !containsFold(allowed, method) || !c.allowsHeaders(requested) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		c.setOrigin(w, origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+
+		if len(c.Headers) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.Headers, ", "))
+		} else if len(requested) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
+
+		if c.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return route
+}
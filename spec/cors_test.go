@@ -0,0 +1,253 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSAllowsOrigin(t *testing.T) {
+	tests := []struct {
+		name            string
+		cors            CORS
+		origin          string
+		expectedAllowed bool
+	}{
+		{"Empty", CORS{}, "http://localhost:3000", true},
+		{"Any", CORS{Origins: []string{"*"}}, "http://localhost:3000", true},
+		{"Exact", CORS{Origins: []string{"http://localhost:3000"}}, "http://localhost:3000", true},
+		{"Pattern", CORS{Origins: []string{"https://*.example.com"}}, "https://app.example.com", true},
+		{"PatternMismatch", CORS{Origins: []string{"https://*.example.com"}}, "https://example.org", false},
+		{"Mismatch", CORS{Origins: []string{"http://localhost:3000"}}, "http://localhost:4000", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAllowed, tc.cors.allowsOrigin(tc.origin))
+		})
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		cors            *CORS
+		origin          string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:   "Nil",
+			cors:   nil,
+			origin: "http://localhost:3000",
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "AnyOrigin",
+			cors:   &CORS{ExposedHeaders: []string{"Location", "X-Request-Id"}},
+			origin: "http://localhost:3000",
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "*",
+				"Access-Control-Expose-Headers": "Location, X-Request-Id",
+			},
+		},
+		{
+			name:   "WithCredentials",
+			cors:   &CORS{Origins: []string{"*"}, Credentials: true},
+			origin: "http://localhost:3000",
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "http://localhost:3000",
+				"Access-Control-Allow-Credentials": "true",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:   "NotAllowed",
+			cors:   &CORS{Origins: []string{"http://localhost:4000"}},
+			origin: "http://localhost:3000",
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Origin", tc.origin)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, http.StatusOK, res.Result().StatusCode)
+			for key, val := range tc.expectedHeaders {
+				assert.Equal(t, val, res.Result().Header.Get(key))
+			}
+		})
+	}
+}
+
+func TestHTTPMockRoutePreflight(t *testing.T) {
+	mocks := []HTTPMock{
+		{
+			HTTPExpect: HTTPExpect{
+				Methods: []string{"GET"},
+				Path:    "/api/v1/messages",
+				Headers: map[string]string{
+					"Authorization": "Bearer .*",
+				},
+			},
+			HTTPResponse: &HTTPResponse{StatusCode: 200},
+			CORS: &CORS{
+				Origins: []string{"https://*.example.com"},
+				MaxAge:  600,
+			},
+		},
+		{
+			HTTPExpect: HTTPExpect{
+				Methods: []string{"POST"},
+				Path:    "/api/v1/messages",
+			},
+			HTTPResponse: &HTTPResponse{StatusCode: 201},
+			CORS: &CORS{
+				Origins:     []string{"https://*.example.com"},
+				Headers:     []string{"Content-Type", "Authorization"},
+				Credentials: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		origin             string
+		method             string
+		headers            string
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+	}{
+		{
+			name:               "GET",
+			origin:             "https://app.example.com",
+			method:             "GET",
+			headers:            "Authorization",
+			expectedStatusCode: 204,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET",
+				"Access-Control-Allow-Headers": "Authorization",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:               "POST",
+			origin:             "https://app.example.com",
+			method:             "POST",
+			headers:            "content-type",
+			expectedStatusCode: 204,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "POST",
+				"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			name:               "HeaderNotAllowed",
+			origin:             "https://app.example.com",
+			method:             "POST",
+			headers:            "X-Custom",
+			expectedStatusCode: 403,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:               "OriginNotAllowed",
+			origin:             "https://example.org",
+			method:             "GET",
+			expectedStatusCode: 403,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:               "MethodNotMocked",
+			origin:             "https://app.example.com",
+			method:             "DELETE",
+			expectedStatusCode: 405,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			for _, m := range mocks {
+				m.RegisterRoutes(router)
+			}
+
+			req := httptest.NewRequest("OPTIONS", "/api/v1/messages", nil)
+			req.Header.Set("Origin", tc.origin)
+			req.Header.Set("Access-Control-Request-Method", tc.method)
+			if tc.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tc.headers)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			for key, val := range tc.expectedHeaders {
+				assert.Equal(t, val, res.Result().Header.Get(key))
+			}
+		})
+	}
+}
+
+func TestRESTMockRoutePreflight(t *testing.T) {
+	mock := RESTMock{
+		RESTExpect: RESTExpect{
+			BasePath: "/api/v1/teams",
+		},
+		CORS: &CORS{},
+	}
+	mock.SetDefaults()
+
+	tests := []struct {
+		name                 string
+		path                 string
+		method               string
+		expectedStatusCode   int
+		expectedAllowMethods string
+	}{
+		{"Collection", "/api/v1/teams", "POST", 204, "GET, POST"},
+		{"Resource", "/api/v1/teams/aaaa", "PATCH", 204, "GET, PUT, PATCH, DELETE"},
+		{"NotAllowed", "/api/v1/teams", "DELETE", 405, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			mock.RegisterRoutes(router)
+
+			req := httptest.NewRequest("OPTIONS", tc.path, nil)
+			req.Header.Set("Origin", "http://localhost:3000")
+			req.Header.Set("Access-Control-Request-Method", tc.method)
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Equal(t, tc.expectedAllowMethods, res.Result().Header.Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
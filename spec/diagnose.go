@@ -0,0 +1,263 @@
+package spec
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Weights of matching criteria for scoring how closely a request matches a mock.
+const (
+	pathWeight   = 3
+	hostWeight   = 2
+	methodWeight = 2
+	fieldWeight  = 1
+)
+
+// Mismatch represents a criterion of a mock not satisfied by a request.
+type Mismatch struct {
+	Criterion string `json:"criterion" yaml:"criterion"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Expected  string `json:"expected" yaml:"expected"`
+	Actual    string `json:"actual" yaml:"actual"`
+}
+
+func (m Mismatch) String() string {
+	criterion := m.Criterion
+	if m.Name != "" {
+		criterion = fmt.Sprintf("%s %s", m.Criterion, m.Name)
+	}
+
+	return fmt.Sprintf("%s: expected %q, got %q", criterion, m.Expected, m.Actual)
+}
+
+// Diagnosis represents how closely a request matches a mock.
+// Score is between 0 and 1, and 1 means the request satisfies all criteria of the mock.
+type Diagnosis struct {
+	Mock       string     `json:"mock" yaml:"mock"`
+	Score      float64    `json:"score" yaml:"score"`
+	Mismatches []Mismatch `json:"mismatches" yaml:"mismatches"`
+}
+
+// Closest sorts a list of diagnoses by their scores and returns at most n of them.
+// Diagnoses with the same score are sorted by their mocks, and diagnoses with zero score are dropped.
+func Closest(diagnoses []Diagnosis, n int) []Diagnosis {
+	nonzero := []Diagnosis{}
+	for _, d := range diagnoses {
+		if d.Score > 0 {
+			nonzero = append(nonzero, d)
+		}
+	}
+	diagnoses = nonzero
+
+	sort.Slice(diagnoses, func(i, j int) bool {
+		if diagnoses[i].Score != diagnoses[j].Score {
+			return diagnoses[i].Score > diagnoses[j].Score
+		}
+		return diagnoses[i].Mock < diagnoses[j].Mock
+	})
+
+	if n >= 0 && len(diagnoses) > n {
+		diagnoses = diagnoses[:n]
+	}
+
+	return diagnoses
+}
+
+// Diagnose scores a recorded request against an http expectation and lists the criteria it does not satisfy.
+// Empty fields in the expectation match any value.
+func (e HTTPExpect) Diagnose(r Request) Diagnosis {
+	var total, satisfied int
+	mismatches := []Mismatch{}
+
+	check := func(weight int, ok bool, m Mismatch) {
+		total += weight
+		if ok {
+			satisfied += weight
+		} else {
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	if e.Listener != "" {
+		check(pathWeight, e.Listener == r.Listener, Mismatch{
+			Criterion: "listener",
+			Expected:  e.Listener,
+			Actual:    r.Listener,
+		})
+	}
+
+	if e.Host != "" {
+		check(hostWeight, matchHost(e.Host, r.Host), Mismatch{
+			Criterion: "host",
+			Expected:  e.Host,
+			Actual:    r.Host,
+		})
+	}
+
+	if len(e.Methods) > 0 {
+		found := false
+		for _, method := range e.Methods {
+			if strings.EqualFold(method, r.Method) {
+				found = true
+				break
+			}
+		}
+
+		check(methodWeight, found, Mismatch{
+			Criterion: "method",
+			Expected:  strings.Join(e.Methods, ", "),
+			Actual:    r.Method,
+		})
+	}
+
+	if e.Path != "" {
+		ok := r.Path == e.Path
+		if e.Prefix {
+			ok = strings.HasPrefix(r.Path, e.Path)
+		}
+
+		expected := e.Path
+		if e.Prefix {
+			expected += "*"
+		}
+
+		check(pathWeight, ok, Mismatch{
+			Criterion: "path",
+			Expected:  expected,
+			Actual:    r.Path,
+		})
+	}
+
+	for _, query := range sortedKeys(e.Queries) {
+		pattern := e.Queries[query]
+		check(fieldWeight, matchAny(fmt.Sprintf("^%s$", pattern), r.Queries[query]), Mismatch{
+			Criterion: "query",
+			Name:      query,
+			Expected:  pattern,
+			Actual:    strings.Join(r.Queries[query], ", "),
+		})
+	}
+
+	for _, header := range sortedKeys(e.Headers) {
+		pattern := e.Headers[header]
+		values := http.Header(r.Headers).Values(header)
+		check(fieldWeight, matchAny(pattern, values), Mismatch{
+			Criterion: "header",
+			Name:      header,
+			Expected:  pattern,
+			Actual:    strings.Join(values, ", "),
+		})
+	}
+
+	for _, name := range sortedKeys(e.Cookies) {
+		pattern := e.Cookies[name]
+		values := cookieValues(http.Header(r.Headers), name)
+		check(fieldWeight, matchAny(pattern, values), Mismatch{
+			Criterion: "cookie",
+			Name:      name,
+			Expected:  pattern,
+			Actual:    strings.Join(values, ", "),
+		})
+	}
+
+	for _, name := range e.NoCookies {
+		values := cookieValues(http.Header(r.Headers), name)
+		check(fieldWeight, len(values) == 0, Mismatch{
+			Criterion: "no cookie",
+			Name:      name,
+			Expected:  "",
+			Actual:    strings.Join(values, ", "),
+		})
+	}
+
+	for _, field := range sortedKeys(e.Form) {
+		pattern := e.Form[field]
+		check(fieldWeight, matchAny(pattern, r.Form[field]), Mismatch{
+			Criterion: "form",
+			Name:      field,
+			Expected:  pattern,
+			Actual:    strings.Join(r.Form[field], ", "),
+		})
+	}
+
+	for _, field := range sortedFileKeys(e.Files) {
+		expected := e.Files[field]
+		files := filesOf(r.Files, field)
+
+		actual := make([]string, len(files))
+		for i, f := range files {
+			actual[i] = f.String()
+		}
+
+		check(fieldWeight, matchFiles(expected, files), Mismatch{
+			Criterion: "file",
+			Name:      field,
+			Expected:  expected.String(),
+			Actual:    strings.Join(actual, ", "),
+		})
+	}
+
+	for _, expr := range sortedKeys(e.XPath) {
+		pattern := e.XPath[expr]
+		values, _ := xpathValues(expr, []byte(r.Body))
+		check(fieldWeight, matchAny(pattern, values), Mismatch{
+			Criterion: "xpath",
+			Name:      expr,
+			Expected:  pattern,
+			Actual:    strings.Join(values, ", "),
+		})
+	}
+
+	if soap := e.SOAP; soap != nil {
+		if soap.Action != "" {
+			action := soapAction(http.Header(r.Headers))
+			check(fieldWeight, action == soap.Action, Mismatch{
+				Criterion: "soap action",
+				Expected:  soap.Action,
+				Actual:    action,
+			})
+		}
+
+		if soap.Operation != "" {
+			operation := soapOperation([]byte(r.Body))
+			check(fieldWeight, operation == soap.Operation, Mismatch{
+				Criterion: "soap operation",
+				Expected:  soap.Operation,
+				Actual:    operation,
+			})
+		}
+	}
+
+	score := 1.0
+	if total > 0 {
+		score = math.Round(float64(satisfied)/float64(total)*100) / 100
+	}
+
+	return Diagnosis{
+		Score:      score,
+		Mismatches: mismatches,
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedFileKeys(m map[string]HTTPFile) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
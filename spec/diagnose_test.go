@@ -0,0 +1,274 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMismatchString(t *testing.T) {
+	tests := []struct {
+		name           string
+		mismatch       Mismatch
+		expectedString string
+	}{
+		{
+			name:           "Path",
+			mismatch:       Mismatch{Criterion: "path", Expected: "/health", Actual: "/healthz"},
+			expectedString: `path: expected "/health", got "/healthz"`,
+		},
+		{
+			name:           "Header",
+			mismatch:       Mismatch{Criterion: "header", Name: "Authorization", Expected: "Bearer .*", Actual: ""},
+			expectedString: `header Authorization: expected "Bearer .*", got ""`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedString, tc.mismatch.String())
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	diagnoses := []Diagnosis{
+		{Mock: "GET /b", Score: 0.5},
+		{Mock: "GET /c", Score: 0.9},
+		{Mock: "GET /a", Score: 0.5},
+		{Mock: "GET /d", Score: 0.1},
+	}
+
+	tests := []struct {
+		name          string
+		n             int
+		expectedMocks []string
+	}{
+		{"All", 10, []string{"GET /c", "GET /a", "GET /b", "GET /d"}},
+		{"Limited", 2, []string{"GET /c", "GET /a"}},
+		{"None", 0, []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in := make([]Diagnosis, len(diagnoses))
+			copy(in, diagnoses)
+
+			mocks := []string{}
+			for _, d := range Closest(in, tc.n) {
+				mocks = append(mocks, d.Mock)
+			}
+
+			assert.Equal(t, tc.expectedMocks, mocks)
+		})
+	}
+}
+
+func TestHTTPExpectDiagnose(t *testing.T) {
+	expect := HTTPExpect{
+		Methods: []string{"POST"},
+		Path:    "/api/v1/sendMessage",
+		Queries: map[string]string{
+			"tenantId": "[0-9a-f-]+",
+		},
+		Headers: map[string]string{
+			"Authorization": "Bearer .*",
+		},
+	}
+
+	tests := []struct {
+		name              string
+		expect            HTTPExpect
+		request           Request
+		expectedDiagnosis Diagnosis
+	}{
+		{
+			name:    "Empty",
+			expect:  HTTPExpect{},
+			request: Request{Method: "GET", Path: "/health"},
+			expectedDiagnosis: Diagnosis{
+				Score:      1,
+				Mismatches: []Mismatch{},
+			},
+		},
+		{
+			name:   "Match",
+			expect: expect,
+			request: Request{
+				Method:  "POST",
+				Path:    "/api/v1/sendMessage",
+				Queries: map[string][]string{"tenantId": {"aaaa-bbbb"}},
+				Headers: map[string][]string{"Authorization": {"Bearer token"}},
+			},
+			expectedDiagnosis: Diagnosis{
+				Score:      1,
+				Mismatches: []Mismatch{},
+			},
+		},
+		{
+			name:   "HeaderMismatch",
+			expect: expect,
+			request: Request{
+				Method:  "POST",
+				Path:    "/api/v1/sendMessage",
+				Queries: map[string][]string{"tenantId": {"aaaa-bbbb"}},
+				Headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			},
+			expectedDiagnosis: Diagnosis{
+				Score: 0.86,
+				Mismatches: []Mismatch{
+					{Criterion: "header", Name: "Authorization", Expected: "Bearer .*", Actual: "Basic dXNlcjpwYXNz"},
+				},
+			},
+		},
+		{
+			name:   "AllMismatch",
+			expect: expect,
+			request: Request{
+				Method: "GET",
+				Path:   "/api/v1/messages",
+			},
+			expectedDiagnosis: Diagnosis{
+				Score: 0,
+				Mismatches: []Mismatch{
+					{Criterion: "method", Expected: "POST", Actual: "GET"},
+					{Criterion: "path", Expected: "/api/v1/sendMessage", Actual: "/api/v1/messages"},
+					{Criterion: "query", Name: "tenantId", Expected: "[0-9a-f-]+", Actual: ""},
+					{Criterion: "header", Name: "Authorization", Expected: "Bearer .*", Actual: ""},
+				},
+			},
+		},
+		{
+			name: "CookieMismatch",
+			expect: HTTPExpect{
+				Cookies:   map[string]string{"session": "^[0-9a-f]+$"},
+				NoCookies: []string{"impersonate"},
+			},
+			request: Request{
+				Method:  "GET",
+				Path:    "/account",
+				Headers: map[string][]string{"Cookie": {"session=abcd; impersonate=admin"}},
+			},
+			expectedDiagnosis: Diagnosis{
+				Score: 0.5,
+				Mismatches: []Mismatch{
+					{Criterion: "no cookie", Name: "impersonate", Expected: "", Actual: "admin"},
+				},
+			},
+		},
+		{
+			name: "FormMismatch",
+			expect: HTTPExpect{
+				Form:  map[string]string{"title": "^Report$"},
+				Files: map[string]HTTPFile{"document": {Filename: `\.pdf$`}},
+			},
+			request: Request{
+				Method: "POST",
+				Path:   "/upload",
+				Form:   map[string][]string{"title": {"Report"}},
+				Files: []FormFile{
+					{Field: "document", Filename: "report.png", ContentType: "image/png", Size: 100},
+				},
+			},
+			expectedDiagnosis: Diagnosis{
+				Score: 0.5,
+				Mismatches: []Mismatch{
+					{Criterion: "file", Name: "document", Expected: "filename \\.pdf$", Actual: "report.png (image/png, 100 bytes)"},
+				},
+			},
+		},
+		{
+			name: "SOAPMismatch",
+			expect: HTTPExpect{
+				XPath: map[string]string{"//Account": "^1111$"},
+				SOAP:  &SOAPExpect{Action: "urn:payments/GetBalance", Operation: "GetBalance"},
+			},
+			request: Request{
+				Method:  "POST",
+				Path:    "/payments",
+				Headers: map[string][]string{"Soapaction": {"urn:payments/Transfer"}},
+				Body:    `<Envelope><Body><Transfer><Account>2222</Account></Transfer></Body></Envelope>`,
+			},
+			expectedDiagnosis: Diagnosis{
+				Score: 0,
+				Mismatches: []Mismatch{
+					{Criterion: "xpath", Name: "//Account", Expected: "^1111$", Actual: "2222"},
+					{Criterion: "soap action", Expected: "urn:payments/GetBalance", Actual: "urn:payments/Transfer"},
+					{Criterion: "soap operation", Expected: "GetBalance", Actual: "Transfer"},
+				},
+			},
+		},
+		{
+			name: "PrefixMismatch",
+			expect: HTTPExpect{
+				Path:   "/api/v2",
+				Prefix: true,
+			},
+			request: Request{Method: "GET", Path: "/api/v1/messages"},
+			expectedDiagnosis: Diagnosis{
+				Score: 0,
+				Mismatches: []Mismatch{
+					{Criterion: "path", Expected: "/api/v2*", Actual: "/api/v1/messages"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDiagnosis, tc.expect.Diagnose(tc.request))
+		})
+	}
+}
+
+func TestRESTMockDiagnose(t *testing.T) {
+	mock := RESTMock{
+		RESTExpect: RESTExpect{
+			BasePath: "/api/v1/teams",
+		},
+	}
+
+	tests := []struct {
+		name              string
+		request           Request
+		expectedDiagnosis Diagnosis
+	}{
+		{
+			name:    "Collection",
+			request: Request{Method: "POST", Path: "/api/v1/teams"},
+			expectedDiagnosis: Diagnosis{
+				Mock:       "/api/v1/teams",
+				Score:      1,
+				Mismatches: []Mismatch{},
+			},
+		},
+		{
+			name:    "Object",
+			request: Request{Method: "POST", Path: "/api/v1/teams/aaaa"},
+			expectedDiagnosis: Diagnosis{
+				Mock:  "/api/v1/teams",
+				Score: 0.6,
+				Mismatches: []Mismatch{
+					{Criterion: "method", Expected: "GET, PUT, PATCH, DELETE", Actual: "POST"},
+				},
+			},
+		},
+		{
+			name:    "PathMismatch",
+			request: Request{Method: "GET", Path: "/api/v1/users"},
+			expectedDiagnosis: Diagnosis{
+				Mock:  "/api/v1/teams",
+				Score: 0.4,
+				Mismatches: []Mismatch{
+					{Criterion: "path", Expected: "/api/v1/teams", Actual: "/api/v1/users"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDiagnosis, mock.Diagnose(tc.request))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Fallback represents what happens to requests not matching any mock.
+// Only one of Response, Forward, or Strict can be set.
+// If Response is set, it is returned for all unmatched requests.
+// If Forward is set, unmatched requests are forwarded to an upstream server.
+// If Strict is set, unmatched requests are recorded as verification failures.
+type Fallback struct {
+	*HTTPResponse `json:"response" yaml:"response"`
+	*HTTPForward  `json:"forward" yaml:"forward"`
+	Strict        bool `json:"strict" yaml:"strict"`
+}
+
+// SetDefaults set default values for empty fields.
+func (f *Fallback) SetDefaults() {
+	if f == nil {
+		return
+	}
+
+	if f.HTTPResponse != nil && f.HTTPResponse.StatusCode == 0 {
+		f.HTTPResponse.StatusCode = 200
+	}
+}
+
+// Validate checks whether or not the fallback is valid.
+func (f *Fallback) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	n := 0
+	for _, set := range []bool{f.HTTPResponse != nil, f.HTTPForward != nil, f.Strict} {
+		if set {
+			n++
+		}
+	}
+
+	if n > 1 {
+		return errors.New("only one of fallback response, forward, or strict can be set")
+	}
+
+	if f.HTTPResponse != nil {
+		if err := f.HTTPResponse.Validate(); err != nil {
+			return fmt.Errorf("invalid fallback response: %s", err)
+		}
+	}
+
+	if f.HTTPForward != nil {
+		if err := f.HTTPForward.Validate(); err != nil {
+			return fmt.Errorf("invalid fallback forward: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Handler returns an http handler for responding to or forwarding unmatched requests.
+// It returns nil if neither a response nor a forwarder is set.
+func (f *Fallback) Handler() http.Handler {
+	if f == nil {
+		return nil
+	}
+
+	return newHandler(f.HTTPResponse, f.HTTPForward)
+}
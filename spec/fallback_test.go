@@ -0,0 +1,137 @@
+package spec
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackSetDefaults(t *testing.T) {
+	tests := []struct {
+		name             string
+		fallback         *Fallback
+		expectedFallback *Fallback
+	}{
+		{
+			name:             "Nil",
+			fallback:         nil,
+			expectedFallback: nil,
+		},
+		{
+			name:             "Response",
+			fallback:         &Fallback{HTTPResponse: &HTTPResponse{}},
+			expectedFallback: &Fallback{HTTPResponse: &HTTPResponse{StatusCode: 200}},
+		},
+		{
+			name:             "Strict",
+			fallback:         &Fallback{Strict: true},
+			expectedFallback: &Fallback{Strict: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fallback.SetDefaults()
+			assert.Equal(t, tc.expectedFallback, tc.fallback)
+		})
+	}
+}
+
+func TestFallbackValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		fallback      *Fallback
+		expectedError string
+	}{
+		{
+			name:          "Nil",
+			fallback:      nil,
+			expectedError: "",
+		},
+		{
+			name:          "Response",
+			fallback:      &Fallback{HTTPResponse: &HTTPResponse{StatusCode: 503}},
+			expectedError: "",
+		},
+		{
+			name:          "Forward",
+			fallback:      &Fallback{HTTPForward: &HTTPForward{To: "http://localhost:8081"}},
+			expectedError: "",
+		},
+		{
+			name:          "Strict",
+			fallback:      &Fallback{Strict: true},
+			expectedError: "",
+		},
+		{
+			name:          "Multiple",
+			fallback:      &Fallback{HTTPResponse: &HTTPResponse{StatusCode: 503}, Strict: true},
+			expectedError: "only one of fallback response, forward, or strict can be set",
+		},
+		{
+			name:          "InvalidResponse",
+			fallback:      &Fallback{HTTPResponse: &HTTPResponse{Body: "{}", BodyText: "{}"}},
+			expectedError: "invalid fallback response: only one of body, body_file, body_text, body_base64, or body_xml can be set",
+		},
+		{
+			name:          "InvalidForward",
+			fallback:      &Fallback{HTTPForward: &HTTPForward{To: "/api"}},
+			expectedError: "invalid fallback forward: forward url must be an absolute url",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.fallback.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFallbackHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		fallback           *Fallback
+		expectedNil        bool
+		expectedStatusCode int
+	}{
+		{
+			name:        "Nil",
+			fallback:    nil,
+			expectedNil: true,
+		},
+		{
+			name:        "Strict",
+			fallback:    &Fallback{Strict: true},
+			expectedNil: true,
+		},
+		{
+			name:               "Response",
+			fallback:           &Fallback{HTTPResponse: &HTTPResponse{StatusCode: 503}},
+			expectedStatusCode: 503,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.fallback.Handler()
+
+			if tc.expectedNil {
+				assert.Nil(t, handler)
+				return
+			}
+
+			req := httptest.NewRequest("GET", "/api/v1/messages", nil)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxFormMemory is the maximum number of bytes of a multipart form kept in memory.
+const maxFormMemory = 32 << 20
+
+// FormFile represents a file uploaded in a multipart request.
+type FormFile struct {
+	Field       string `json:"field" yaml:"field"`
+	Filename    string `json:"filename" yaml:"filename"`
+	ContentType string `json:"contentType" yaml:"content_type"`
+	Size        int64  `json:"size" yaml:"size"`
+}
+
+func (f FormFile) String() string {
+	return fmt.Sprintf("%s (%s, %d bytes)", f.Filename, f.ContentType, f.Size)
+}
+
+// HTTPFile represents an expectation for a file uploaded in a multipart request.
+// Filename and ContentType are regular expressions, and a zero MaxSize means no limit.
+type HTTPFile struct {
+	Filename    string `json:"filename" yaml:"filename"`
+	ContentType string `json:"contentType" yaml:"content_type"`
+	MinSize     int64  `json:"minSize" yaml:"min_size"`
+	MaxSize     int64  `json:"maxSize" yaml:"max_size"`
+}
+
+func (e HTTPFile) String() string {
+	criteria := []string{}
+
+	if e.Filename != "" {
+		criteria = append(criteria, "filename "+e.Filename)
+	}
+
+	if e.ContentType != "" {
+		criteria = append(criteria, "content type "+e.ContentType)
+	}
+
+	if e.MinSize > 0 {
+		criteria = append(criteria, fmt.Sprintf("at least %d bytes", e.MinSize))
+	}
+
+	if e.MaxSize > 0 {
+		criteria = append(criteria, fmt.Sprintf("at most %d bytes", e.MaxSize))
+	}
+
+	if len(criteria) == 0 {
+		return "any file"
+	}
+
+	return strings.Join(criteria, ", ")
+}
+
+// Validate checks whether or not the expectation is valid.
+func (e HTTPFile) Validate() error {
+	if _, err := compilePattern(e.Filename); err != nil {
+		return fmt.Errorf("invalid filename: %s", err)
+	}
+
+	if _, err := compilePattern(e.ContentType); err != nil {
+		return fmt.Errorf("invalid content type: %s", err)
+	}
+
+	return nil
+}
+
+// Match determines whether or not an uploaded file satisfies the expectation.
+func (e HTTPFile) Match(f FormFile) bool {
+	if e.Filename != "" && !matchAny(e.Filename, []string{f.Filename}) {
+		return false
+	}
+
+	if e.ContentType != "" && !matchAny(e.ContentType, []string{f.ContentType}) {
+		return false
+	}
+
+	if f.Size < e.MinSize || (e.MaxSize > 0 && f.Size > e.MaxSize) {
+		return false
+	}
+
+	return true
+}
+
+// ParseForm parses the fields and the files of a url-encoded or a multipart form request body.
+// It returns nil for other types of request bodies.
+func ParseForm(headers http.Header, body []byte) (map[string][]string, []FormFile) {
+	mediaType, params, err := mime.ParseMediaType(headers.Get("Content-Type"))
+	if err != nil {
+		return nil, nil
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, nil
+		}
+		return values, nil
+
+	case "multipart/form-data":
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		form, err := reader.ReadForm(maxFormMemory)
+		if err != nil {
+			return nil, nil
+		}
+		defer form.RemoveAll()
+
+		files := []FormFile{}
+		for _, field := range sortedFileFields(form.File) {
+			for _, fh := range form.File[field] {
+				files = append(files, FormFile{
+					Field:       field,
+					Filename:    fh.Filename,
+					ContentType: fh.Header.Get("Content-Type"),
+					Size:        fh.Size,
+				})
+			}
+		}
+
+		return form.Value, files
+	}
+
+	return nil, nil
+}
+
+func sortedFileFields(m map[string][]*multipart.FileHeader) []string {
+	fields := make([]string, 0, len(m))
+	for field := range m {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// filesOf returns the uploaded files of a form field.
+func filesOf(files []FormFile, field string) []FormFile {
+	result := []FormFile{}
+	for _, f := range files {
+		if f.Field == field {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// matchFiles determines whether or not any of the uploaded files satisfies an expectation.
+func matchFiles(e HTTPFile, files []FormFile) bool {
+	for _, f := range files {
+		if e.Match(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formMatcher creates a route matcher for the fields and the files of a form request.
+func formMatcher(fields map[string]string, files map[string]HTTPFile) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		body, err := readBody(r)
+		if err != nil {
+			return false
+		}
+
+		values, uploaded := ParseForm(r.Header, body)
+
+		for field, pattern := range fields {
+			if !matchAny(pattern, values[field]) {
+				return false
+			}
+		}
+
+		for field, e := range files {
+			if !matchFiles(e, filesOf(uploaded, field)) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+func hashFiles(h hash.Hash, files map[string]HTTPFile) {
+	m := make(map[string]string, len(files))
+	for field, e := range files {
+		m[field] = e.String()
+	}
+
+	hashStringMap(h, true, m)
+}
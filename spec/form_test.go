@@ -0,0 +1,244 @@
+package spec
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPart struct {
+	field       string
+	filename    string
+	contentType string
+	content     string
+}
+
+func multipartBody(parts ...testPart) (string, []byte) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+
+	for _, p := range parts {
+		if p.filename == "" {
+			_ = mw.WriteField(p.field, p.content)
+			continue
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="`+p.field+`"; filename="`+p.filename+`"`)
+		h.Set("Content-Type", p.contentType)
+		w, _ := mw.CreatePart(h)
+		_, _ = w.Write([]byte(p.content))
+	}
+
+	mw.Close()
+
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+func TestParseForm(t *testing.T) {
+	multipartType, multipartData := multipartBody(
+		testPart{field: "title", content: "Report"},
+		testPart{field: "document", filename: "report.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+	)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           []byte
+		expectedValues map[string][]string
+		expectedFiles  []FormFile
+	}{
+		{
+			name:           "NoContentType",
+			contentType:    "",
+			body:           []byte("title=Report"),
+			expectedValues: nil,
+			expectedFiles:  nil,
+		},
+		{
+			name:           "JSON",
+			contentType:    "application/json",
+			body:           []byte(`{"title":"Report"}`),
+			expectedValues: nil,
+			expectedFiles:  nil,
+		},
+		{
+			name:        "URLEncoded",
+			contentType: "application/x-www-form-urlencoded",
+			body:        []byte("title=Report&tags=a&tags=b"),
+			expectedValues: map[string][]string{
+				"title": {"Report"},
+				"tags":  {"a", "b"},
+			},
+			expectedFiles: nil,
+		},
+		{
+			name:        "Multipart",
+			contentType: multipartType,
+			body:        multipartData,
+			expectedValues: map[string][]string{
+				"title": {"Report"},
+			},
+			expectedFiles: []FormFile{
+				{Field: "document", Filename: "report.pdf", ContentType: "application/pdf", Size: 8},
+			},
+		},
+		{
+			name:           "InvalidMultipart",
+			contentType:    "multipart/form-data; boundary=xxxx",
+			body:           []byte("not multipart"),
+			expectedValues: nil,
+			expectedFiles:  nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := http.Header{}
+			headers.Set("Content-Type", tc.contentType)
+
+			values, files := ParseForm(headers, tc.body)
+
+			assert.Equal(t, tc.expectedValues, values)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func TestHTTPFileString(t *testing.T) {
+	assert.Equal(t, "any file", HTTPFile{}.String())
+	assert.Equal(t, "filename \\.pdf$, content type application/pdf, at least 1 bytes, at most 1024 bytes", HTTPFile{
+		Filename:    `\.pdf$`,
+		ContentType: "application/pdf",
+		MinSize:     1,
+		MaxSize:     1024,
+	}.String())
+}
+
+func TestHTTPFileMatch(t *testing.T) {
+	file := FormFile{Field: "document", Filename: "report.pdf", ContentType: "application/pdf", Size: 100}
+
+	tests := []struct {
+		name          string
+		expect        HTTPFile
+		expectedMatch bool
+	}{
+		{"Any", HTTPFile{}, true},
+		{"Filename", HTTPFile{Filename: `\.pdf$`}, true},
+		{"FilenameMismatch", HTTPFile{Filename: `\.png$`}, false},
+		{"ContentType", HTTPFile{ContentType: "^application/pdf$"}, true},
+		{"ContentTypeMismatch", HTTPFile{ContentType: "^image/"}, false},
+		{"Size", HTTPFile{MinSize: 100, MaxSize: 100}, true},
+		{"TooSmall", HTTPFile{MinSize: 101}, false},
+		{"TooLarge", HTTPFile{MaxSize: 99}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMatch, tc.expect.Match(file))
+		})
+	}
+}
+
+func TestHTTPMockRouteForm(t *testing.T) {
+	mock := HTTPMock{
+		HTTPExpect: HTTPExpect{
+			Methods: []string{"POST"},
+			Path:    "/upload",
+			Form: map[string]string{
+				"title": "^Report$",
+			},
+			Files: map[string]HTTPFile{
+				"document": {Filename: `\.pdf$`, ContentType: "^application/pdf$", MaxSize: 1024},
+			},
+		},
+		HTTPResponse: &HTTPResponse{
+			StatusCode: 201,
+		},
+	}
+
+	router := mux.NewRouter()
+	mock.RegisterRoutes(router)
+
+	tests := []struct {
+		name               string
+		parts              []testPart
+		expectedStatusCode int
+	}{
+		{
+			name: "Match",
+			parts: []testPart{
+				{field: "title", content: "Report"},
+				{field: "document", filename: "report.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+			},
+			expectedStatusCode: 201,
+		},
+		{
+			name: "FieldMismatch",
+			parts: []testPart{
+				{field: "title", content: "Invoice"},
+				{field: "document", filename: "report.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name: "WrongField",
+			parts: []testPart{
+				{field: "title", content: "Report"},
+				{field: "attachment", filename: "report.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name: "TooLarge",
+			parts: []testPart{
+				{field: "title", content: "Report"},
+				{field: "document", filename: "report.pdf", contentType: "application/pdf", content: strings.Repeat("x", 2048)},
+			},
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, body := multipartBody(tc.parts...)
+			req := httptest.NewRequest("POST", "/upload", bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+		})
+	}
+
+	t.Run("URLEncoded", func(t *testing.T) {
+		mock := HTTPMock{
+			HTTPExpect: HTTPExpect{
+				Methods: []string{"POST"},
+				Path:    "/login",
+				Form: map[string]string{
+					"username": "^admin$",
+				},
+			},
+		}
+		mock.SetDefaults()
+
+		router := mux.NewRouter()
+		mock.RegisterRoutes(router)
+
+		req := httptest.NewRequest("POST", "/login", strings.NewReader("username=admin&password=secret"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Result().StatusCode)
+	})
+}
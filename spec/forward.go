@@ -0,0 +1,101 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer creating the spans of flax.
+const TracerName = "github.com/moorara/flax"
+
+// WithProxyErrorHandler returns a new context which reports errors from upstream servers to a function.
+func WithProxyErrorHandler(ctx context.Context, f func(error)) context.Context {
+	return context.WithValue(ctx, proxyErrorHandlerKey, f)
+}
+
+func reportProxyError(ctx context.Context, err error) {
+	if f, ok := ctx.Value(proxyErrorHandlerKey).(func(error)); ok {
+		f(err)
+	}
+}
+
+// Validate checks whether or not the forwarder is valid.
+func (f *HTTPForward) Validate() error {
+	u, err := url.Parse(f.To)
+	if err != nil {
+		return fmt.Errorf("invalid forward url: %s", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("forward url must be an absolute url")
+	}
+
+	return nil
+}
+
+// proxy creates a reverse proxy for forwarding requests to an upstream server.
+// The request path is appended to the path of the upstream url.
+func (f *HTTPForward) proxy() *httputil.ReverseProxy {
+	target, _ := url.Parse(f.To)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+		for key, val := range f.Headers {
+			req.Header.Set(key, val)
+		}
+	}
+
+	proxy.Transport = &tracingTransport{
+		transport: http.DefaultTransport,
+	}
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		reportProxyError(req.Context(), err)
+		writeJSON(w, http.StatusBadGateway, JSON{
+			"message": err.Error(),
+		})
+	}
+
+	return proxy
+}
+
+// tracingTransport creates a client span for every upstream request and propagates the trace context to the upstream server.
+type tracingTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(TracerName).Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(res.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(res.StatusCode))
+
+	return res, nil
+}
@@ -0,0 +1,88 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPForwardValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		forward       HTTPForward
+		expectedError string
+	}{
+		{"OK", HTTPForward{To: "http://localhost:8080/api"}, ""},
+		{"InvalidURL", HTTPForward{To: "http://local host"}, `invalid forward url: parse "http://local host": invalid character " " in host name`},
+		{"RelativeURL", HTTPForward{To: "/api"}, "forward url must be an absolute url"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.forward.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestWithProxyErrorHandler(t *testing.T) {
+	var reported error
+	ctx := WithProxyErrorHandler(context.Background(), func(err error) {
+		reported = err
+	})
+
+	err := errors.New("connection refused")
+	reportProxyError(ctx, err)
+	assert.Equal(t, err, reported)
+
+	// No handler in the context
+	reportProxyError(context.Background(), err)
+}
+
+func TestHTTPForwardPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+
+	f := &HTTPForward{To: upstream.URL}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "server")
+	req := httptest.NewRequest("GET", "/api/v1/messages", nil).WithContext(ctx)
+	res := httptest.NewRecorder()
+	f.proxy().ServeHTTP(res, req)
+	parent.End()
+
+	assert.Equal(t, http.StatusAccepted, res.Result().StatusCode)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	client := spans[0]
+	assert.Equal(t, "HTTP GET", client.Name())
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Equal(t, "00-"+client.SpanContext().TraceID().String()+"-"+client.SpanContext().SpanID().String()+"-01", traceparent)
+}
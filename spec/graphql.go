@@ -0,0 +1,915 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	graphqlList   = "list"
+	graphqlGet    = "get"
+	graphqlCreate = "create"
+	graphqlUpdate = "update"
+	graphqlDelete = "delete"
+)
+
+var graphqlActions = []string{graphqlList, graphqlGet, graphqlCreate, graphqlUpdate, graphqlDelete}
+
+// GraphQLLocation represents a location in a GraphQL document.
+type GraphQLLocation struct {
+	Line   int `json:"line" yaml:"line"`
+	Column int `json:"column" yaml:"column"`
+}
+
+// GraphQLError represents an error in a GraphQL response.
+type GraphQLError struct {
+	Message    string            `json:"message" yaml:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty" yaml:"locations,omitempty"`
+	Path       []interface{}     `json:"path,omitempty" yaml:"path,omitempty"`
+	Extensions JSON              `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	return e.Message
+}
+
+// graphqlError converts an error of the executor to a response error.
+// Syntax errors are reported without the excerpt of the source following their first line.
+func graphqlError(e gqlerrors.FormattedError) *GraphQLError {
+	err := &GraphQLError{
+		Message:    strings.SplitN(e.Message, "\n", 2)[0],
+		Path:       e.Path,
+		Extensions: e.Extensions,
+	}
+
+	for _, loc := range e.Locations {
+		err.Locations = append(err.Locations, GraphQLLocation{Line: loc.Line, Column: loc.Column})
+	}
+
+	return err
+}
+
+func graphqlErrors(errs []gqlerrors.FormattedError) []*GraphQLError {
+	converted := make([]*GraphQLError, len(errs))
+	for i, e := range errs {
+		converted[i] = graphqlError(e)
+	}
+
+	return converted
+}
+
+// GraphQLOperation represents a canned response for a GraphQL operation by its name.
+// Variables are regular expressions for the values of the variables of the operation.
+// The selections of the operation are executed against Data, so only the selected fields are responded.
+type GraphQLOperation struct {
+	Name      string            `json:"name" yaml:"name"`
+	Variables map[string]string `json:"variables" yaml:"variables"`
+	Data      JSON              `json:"data" yaml:"data"`
+	Errors    []GraphQLError    `json:"errors" yaml:"errors"`
+}
+
+// Match determines whether or not an operation request satisfies the canned response.
+func (o GraphQLOperation) Match(name string, variables map[string]interface{}) bool {
+	if o.Name != name {
+		return false
+	}
+
+	for key, pattern := range o.Variables {
+		value, ok := variables[key]
+		if !ok || !matchAny("^"+pattern+"$", []string{variableString(value)}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// variableString returns the string form of a variable or an argument value for matching.
+func variableString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// GraphQLResolver represents how a field of a type is resolved.
+// Field is in the form of Type.field, and Args are regular expressions for the values of the arguments of the field.
+// A resolver either responds Data or Error, or resolves the field from a store.
+// If Action is empty, it is inferred from the field:
+// list or get for queries, and create, update, or delete for mutations depending on the field name.
+type GraphQLResolver struct {
+	Field  string            `json:"field" yaml:"field"`
+	Args   map[string]string `json:"args" yaml:"args"`
+	Data   interface{}       `json:"data" yaml:"data"`
+	Error  string            `json:"error" yaml:"error"`
+	Store  string            `json:"store" yaml:"store"`
+	Action string            `json:"action" yaml:"action"`
+}
+
+// Match determines whether or not a field with arguments is resolved by the resolver.
+func (r GraphQLResolver) Match(field string, args map[string]interface{}) bool {
+	if r.Field != field {
+		return false
+	}
+
+	for key, pattern := range r.Args {
+		value, ok := args[key]
+		if !ok || !matchAny("^"+pattern+"$", []string{variableString(value)}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// action returns the store action of the resolver for a field of a type.
+func (r GraphQLResolver) action(s *graphql.Schema, t *graphql.Object, f *graphql.FieldDefinition) string {
+	if r.Action != "" {
+		return r.Action
+	}
+
+	if mutation := s.MutationType(); mutation != nil && t.Name() == mutation.Name() {
+		name := strings.ToLower(f.Name)
+		switch {
+		case strings.HasPrefix(name, "create") || strings.HasPrefix(name, "add"):
+			return graphqlCreate
+		case strings.HasPrefix(name, "update") || strings.HasPrefix(name, "edit"):
+			return graphqlUpdate
+		case strings.HasPrefix(name, "delete") || strings.HasPrefix(name, "remove"):
+			return graphqlDelete
+		}
+		return ""
+	}
+
+	typ := f.Type
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.OfType
+	}
+
+	if _, ok := typ.(*graphql.List); ok {
+		return graphqlList
+	}
+
+	return graphqlGet
+}
+
+// GraphQLMock represents a mock GraphQL server.
+// The schema is defined in the schema definition language by either Schema or SchemaFile.
+// Operations with canned responses are matched first, and other operations are executed using Resolvers.
+// Fields with no resolver are resolved from the properties of their parent objects.
+type GraphQLMock struct {
+	Listener   string               `json:"listener" yaml:"listener"`
+	Host       string               `json:"host" yaml:"host"`
+	Path       string               `json:"path" yaml:"path"`
+	Schema     string               `json:"schema" yaml:"schema"`
+	SchemaFile string               `json:"schemaFile" yaml:"schema_file"`
+	Operations []GraphQLOperation   `json:"operations" yaml:"operations"`
+	Resolvers  []GraphQLResolver    `json:"resolvers" yaml:"resolvers"`
+	Stores     map[string]RESTStore `json:"stores" yaml:"stores"`
+	CORS       *CORS                `json:"cors" yaml:"cors"`
+	Auth       *Auth                `json:"auth" yaml:"auth"`
+	RateLimit  *RateLimit           `json:"rateLimit" yaml:"rate_limit"`
+	Priority   int                  `json:"priority" yaml:"priority"`
+	Tags       []string             `json:"tags" yaml:"tags"`
+
+	chaos  *Chaos
+	server *graphqlServer
+}
+
+// SetDefaults set default values for empty fields.
+func (m *GraphQLMock) SetDefaults() {
+	if m.Path == "" {
+		m.Path = "/graphql"
+	}
+	m.Path = path.Clean("/" + m.Path)
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+func (m *GraphQLMock) Inherit(c Config) {
+	if m.CORS == nil {
+		m.CORS = c.CORS
+	}
+
+	if m.Auth == nil {
+		m.Auth = c.Auth
+	}
+
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// readSchema returns the schema definition of the mock.
+func (m *GraphQLMock) readSchema() (string, error) {
+	if m.SchemaFile == "" {
+		return m.Schema, nil
+	}
+
+	b, err := ioutil.ReadFile(m.SchemaFile)
+	if err != nil {
+		return "", fmt.Errorf("invalid schema_file: %s", err)
+	}
+
+	return string(b), nil
+}
+
+// parseSchema parses the schema definition of the mock into a schema resolving fields with a resolve function.
+func (m *GraphQLMock) parseSchema(resolve graphql.FieldResolveFn) (*graphql.Schema, error) {
+	if (m.Schema == "") == (m.SchemaFile == "") {
+		return nil, errors.New("one of schema or schema_file should be set")
+	}
+
+	sdl, err := m.readSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := parseGraphQLSchema(sdl, resolve)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %s", err)
+	}
+
+	return s, nil
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *GraphQLMock) Validate() error {
+	s, err := m.parseSchema(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range m.Operations {
+		if o.Name == "" {
+			return errors.New("operation name is required")
+		}
+
+		if err := compilePatterns("variable", o.Variables, true); err != nil {
+			return fmt.Errorf("invalid operation %s: %s", o.Name, err)
+		}
+	}
+
+	for _, r := range m.Resolvers {
+		i := strings.Index(r.Field, ".")
+		if i < 0 {
+			return fmt.Errorf("invalid resolver field %s: expected Type.field", r.Field)
+		}
+
+		t, ok := s.Type(r.Field[:i]).(*graphql.Object)
+		if !ok || t.Fields()[r.Field[i+1:]] == nil {
+			return fmt.Errorf("invalid resolver field %s: no such field", r.Field)
+		}
+
+		if err := compilePatterns("argument", r.Args, true); err != nil {
+			return fmt.Errorf("invalid resolver for %s: %s", r.Field, err)
+		}
+
+		if r.Store == "" {
+			continue
+		}
+
+		if _, ok := m.Stores[r.Store]; !ok {
+			return fmt.Errorf("invalid resolver for %s: unknown store %s", r.Field, r.Store)
+		}
+
+		if action := r.action(s, t, t.Fields()[r.Field[i+1:]]); action == "" {
+			return fmt.Errorf("invalid resolver for %s: cannot infer store action", r.Field)
+		} else if !containsString(graphqlActions, action) {
+			return fmt.Errorf("invalid resolver for %s: unknown store action %s", r.Field, action)
+		}
+	}
+
+	if err := m.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %s", err)
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	return nil
+}
+
+// String returns a string representation of the mock.
+func (m GraphQLMock) String() string {
+	return withListener(m.Listener, "GraphQL "+m.Host+m.Path)
+}
+
+// Hash calculates a hash for a graphql mock based on its location.
+func (m GraphQLMock) Hash() uint64 {
+	h := fnv.New64a()
+
+	hashString(h, "graphql", m.Listener, m.Host)
+	hashString(h, m.Path)
+
+	return h.Sum64()
+}
+
+// Rank returns the rank of the mock for ordering overlapping mocks.
+func (m GraphQLMock) Rank() Rank {
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(true, m.Path, 0),
+	}
+}
+
+// Diagnose scores a recorded request against the mock.
+// Mocks of other listeners are not considered at all.
+func (m GraphQLMock) Diagnose(r Request) Diagnosis {
+	if m.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.Listener, r)
+	}
+
+	e := HTTPExpect{
+		Host:    m.Host,
+		Methods: []string{"GET", "POST"},
+		Path:    m.Path,
+	}
+
+	d := e.Diagnose(r)
+	d.Mock = m.String()
+
+	return d
+}
+
+// match adds the listener and host matchers of the mock to a route.
+func (m *GraphQLMock) match(route *mux.Route) *mux.Route {
+	route.MatcherFunc(matchListener(m.Listener))
+
+	if m.Host != "" {
+		route.MatcherFunc(hostMatcher(m.Host))
+	}
+
+	return route.Path(m.Path)
+}
+
+// wrap wraps a handler of the mock with cors, chaos, rate limit, and auth.
+func (m *GraphQLMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(m.CORS, m.chaos, m.RateLimit, m.Auth, h)
+}
+
+// RegisterRoutes configure routes for a graphql mock.
+// The schema and the stores are set up once, so the stores keep their changes until the mock is loaded again.
+func (m *GraphQLMock) RegisterRoutes(router *mux.Router) {
+	var err error
+	if m.server == nil {
+		m.server, err = newGraphQLServer(m)
+	}
+
+	route := m.match(router.Methods("GET", "POST"))
+	route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, JSON{
+				"errors": []*GraphQLError{{Message: err.Error()}},
+			})
+			return
+		}
+
+		m.server.ServeHTTP(w, r)
+	})))
+
+	if preflight := m.CORS.RegisterPreflight(router, []string{"GET", "POST"}); preflight != nil {
+		m.match(preflight)
+	}
+}
+
+// graphqlRequest is a GraphQL request over http.
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// parseGraphQLRequest reads a GraphQL request from either the query parameters of a GET request,
+// or the JSON body or the application/graphql body of a POST request.
+func parseGraphQLRequest(r *http.Request) (*graphqlRequest, error) {
+	q := r.URL.Query()
+	req := &graphqlRequest{
+		Query:         q.Get("query"),
+		OperationName: q.Get("operationName"),
+	}
+
+	if v := q.Get("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+			return nil, errors.New("Variables are invalid JSON.")
+		}
+	}
+
+	if r.Method == "POST" {
+		body, err := readBody(r)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, req); err != nil {
+			return nil, errors.New("POST body sent invalid JSON.")
+		}
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, errors.New("Must provide query string.")
+	}
+
+	return req, nil
+}
+
+// graphqlStore is a mutable copy of a store of a graphql mock.
+type graphqlStore struct {
+	identifier string
+	objects    []JSON
+	next       int
+}
+
+func newGraphQLStore(s RESTStore) *graphqlStore {
+	store := &graphqlStore{
+		identifier: s.Identifier,
+		objects:    make([]JSON, len(s.Objects)),
+		next:       len(s.Objects) + 1,
+	}
+
+	for i, obj := range s.Objects {
+		store.objects[i] = copyJSON(obj)
+	}
+
+	return store
+}
+
+func copyJSON(obj JSON) JSON {
+	c := make(JSON, len(obj))
+	for key, val := range obj {
+		c[key] = val
+	}
+	return c
+}
+
+// key returns the identifier of objects in the store.
+func (s *graphqlStore) key() string {
+	if s.identifier != "" {
+		return s.identifier
+	}
+
+	for _, obj := range s.objects {
+		for _, key := range defaultIdentifiers {
+			if _, ok := obj[key]; ok {
+				return key
+			}
+		}
+	}
+
+	return "id"
+}
+
+// matchProps determines whether or not an object has the values of the arguments for its properties.
+// The input argument and arguments that are not properties of the object are ignored.
+func matchProps(obj JSON, args map[string]interface{}) bool {
+	for key, val := range args {
+		if prop, ok := obj[key]; ok && key != "input" && fmt.Sprint(prop) != fmt.Sprint(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// mutationInput returns the properties set by a mutation either from its input argument or from all its arguments.
+func mutationInput(args map[string]interface{}) map[string]interface{} {
+	if in, ok := args["input"].(map[string]interface{}); ok {
+		return in
+	}
+	return args
+}
+
+// find returns the index of an object identified by the arguments of a field.
+func (s *graphqlStore) find(args map[string]interface{}) int {
+	key := s.key()
+	id, ok := args[key]
+	if !ok {
+		id, ok = mutationInput(args)[key]
+	}
+
+	for i, obj := range s.objects {
+		if ok && fmt.Sprint(obj[key]) == fmt.Sprint(id) || !ok && matchProps(obj, args) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// apply performs an action on the store for a field with arguments.
+func (s *graphqlStore) apply(action string, args map[string]interface{}) interface{} {
+	switch action {
+	case graphqlList:
+		list := []interface{}{}
+		for _, obj := range s.objects {
+			if matchProps(obj, args) {
+				list = append(list, obj)
+			}
+		}
+		return list
+
+	case graphqlGet:
+		for _, obj := range s.objects {
+			if matchProps(obj, args) {
+				return obj
+			}
+		}
+		return nil
+
+	case graphqlCreate:
+		obj := JSON{}
+		for key, val := range mutationInput(args) {
+			obj[key] = val
+		}
+
+		key := s.key()
+		if _, ok := obj[key]; !ok {
+			obj[key] = strconv.Itoa(s.next)
+		}
+		s.next++
+
+		s.objects = append(s.objects, obj)
+		return obj
+
+	case graphqlUpdate:
+		i := s.find(args)
+		if i < 0 {
+			return nil
+		}
+
+		obj := copyJSON(s.objects[i])
+		for key, val := range mutationInput(args) {
+			if val != nil {
+				obj[key] = val
+			}
+		}
+
+		s.objects[i] = obj
+		return obj
+
+	case graphqlDelete:
+		i := s.find(args)
+		if i < 0 {
+			return nil
+		}
+
+		obj := s.objects[i]
+		s.objects = append(s.objects[:i:i], s.objects[i+1:]...)
+		return obj
+	}
+
+	return nil
+}
+
+// graphqlServer executes GraphQL requests for a graphql mock.
+type graphqlServer struct {
+	sync.Mutex
+	schema     *graphql.Schema
+	operations []GraphQLOperation
+	resolvers  []GraphQLResolver
+	stores     map[string]*graphqlStore
+}
+
+func newGraphQLServer(m *GraphQLMock) (*graphqlServer, error) {
+	stores := map[string]*graphqlStore{}
+	for name, s := range m.Stores {
+		stores[name] = newGraphQLStore(s)
+	}
+
+	s := &graphqlServer{
+		operations: m.Operations,
+		resolvers:  m.Resolvers,
+		stores:     stores,
+	}
+
+	schema, err := m.parseSchema(s.resolve)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// resolve resolves a field using the first resolver matching it.
+// Operations with canned data are resolved from their data only.
+func (s *graphqlServer) resolve(p graphql.ResolveParams) (interface{}, error) {
+	if canned, _ := p.Context.Value(graphqlDataKey).(bool); !canned {
+		t := p.Info.ParentType.(*graphql.Object)
+		f := t.Fields()[p.Info.FieldName]
+
+		for _, r := range s.resolvers {
+			if !r.Match(t.Name()+"."+f.Name, p.Args) {
+				continue
+			}
+
+			switch {
+			case r.Error != "":
+				return nil, errors.New(r.Error)
+			case r.Store != "":
+				return s.stores[r.Store].apply(r.action(s.schema, t, f), p.Args), nil
+			default:
+				return r.Data, nil
+			}
+		}
+	}
+
+	obj, _ := asObject(p.Source)
+
+	return obj[p.Info.FieldName], nil
+}
+
+// respond writes a GraphQL response with errors first if there is any.
+// The data of failed requests are left out of responses.
+func (s *graphqlServer) respond(w http.ResponseWriter, statusCode int, data interface{}, errs []*GraphQLError) {
+	resp := newGraphQLObject()
+
+	if len(errs) > 0 {
+		resp.set("errors", errs)
+	}
+
+	if statusCode == http.StatusOK {
+		resp.set("data", data)
+	}
+
+	writeJSON(w, statusCode, resp)
+}
+
+// execute validates and executes a GraphQL document against a root value.
+// It returns the status code, the data, and the errors of the response.
+func (s *graphqlServer) execute(ctx context.Context, doc *ast.Document, op *ast.OperationDefinition, req *graphqlRequest, root interface{}) (int, interface{}, []*GraphQLError) {
+	if result := graphql.ValidateDocument(s.schema, doc, nil); !result.IsValid {
+		return http.StatusBadRequest, nil, graphqlErrors(result.Errors)
+	}
+
+	s.Lock()
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        *s.schema,
+		Root:          root,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	s.Unlock()
+
+	errs := graphqlErrors(result.Errors)
+
+	// Errors with no path before any data is resolved are request errors, such as invalid variables.
+	if result.Data == nil && len(errs) > 0 && errs[0].Path == nil {
+		return http.StatusBadRequest, nil, errs
+	}
+
+	return http.StatusOK, orderFields(doc, op.SelectionSet, result.Data), errs
+}
+
+func (s *graphqlServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := parseGraphQLRequest(r)
+	if err != nil {
+		s.respond(w, http.StatusBadRequest, nil, []*GraphQLError{{Message: err.Error()}})
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		s.respond(w, http.StatusBadRequest, nil, []*GraphQLError{graphqlError(gqlerrors.FormatError(err))})
+		return
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		s.respond(w, http.StatusBadRequest, nil, []*GraphQLError{{Message: err.Error()}})
+		return
+	}
+
+	if r.Method == "GET" && op.Operation != ast.OperationTypeQuery {
+		w.Header().Set("Allow", "POST")
+		s.respond(w, http.StatusMethodNotAllowed, nil, []*GraphQLError{{Message: fmt.Sprintf("Can only perform a %s operation from a POST request.", op.Operation)}})
+		return
+	}
+
+	if op.Operation == ast.OperationTypeSubscription {
+		s.respond(w, http.StatusBadRequest, nil, []*GraphQLError{{Message: "Subscriptions are not supported."}})
+		return
+	}
+
+	var name string
+	if op.Name != nil {
+		name = op.Name.Value
+	}
+
+	var root interface{} = JSON{}
+	var errs []*GraphQLError
+	ctx := r.Context()
+
+	for _, o := range s.operations {
+		if o.Match(name, req.Variables) {
+			for i := range o.Errors {
+				errs = append(errs, &o.Errors[i])
+			}
+
+			if o.Data == nil {
+				s.respond(w, http.StatusOK, nil, errs)
+				return
+			}
+
+			root = o.Data
+			ctx = context.WithValue(ctx, graphqlDataKey, true)
+			break
+		}
+	}
+
+	statusCode, data, execErrs := s.execute(ctx, doc, op, req, root)
+	if statusCode != http.StatusOK {
+		errs = nil
+	}
+
+	s.respond(w, statusCode, data, append(errs, execErrs...))
+}
+
+// selectOperation returns the operation of a document to execute by its name.
+// The name can be empty if the document has only one operation.
+func selectOperation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var op *ast.OperationDefinition
+
+	for _, def := range doc.Definitions {
+		o, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "" && op != nil:
+			return nil, errors.New("Must provide operation name if query contains multiple operations.")
+		case name == "" || o.Name != nil && o.Name.Value == name:
+			op = o
+		}
+	}
+
+	switch {
+	case op == nil && name != "":
+		return nil, fmt.Errorf("Unknown operation named %q.", name)
+	case op == nil:
+		return nil, errors.New("Must provide an operation.")
+	}
+
+	return op, nil
+}
+
+// graphqlObject is a JSON object keeping the order of its keys.
+type graphqlObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newGraphQLObject() *graphqlObject {
+	return &graphqlObject{
+		values: map[string]interface{}{},
+	}
+}
+
+func (o *graphqlObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o *graphqlObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// orderFields orders the fields of a result in the order they are selected,
+// since the executor returns the fields of objects as maps.
+func orderFields(doc *ast.Document, set *ast.SelectionSet, value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = orderFields(doc, set, item)
+		}
+		return list
+
+	case map[string]interface{}:
+		keys, sets := []string{}, map[string][]*ast.SelectionSet{}
+		collectFields(doc, set, v, &keys, sets)
+
+		obj := newGraphQLObject()
+		for _, key := range keys {
+			sub := &ast.SelectionSet{}
+			for _, s := range sets[key] {
+				if s != nil {
+					sub.Selections = append(sub.Selections, s.Selections...)
+				}
+			}
+			obj.set(key, orderFields(doc, sub, v[key]))
+		}
+		return obj
+	}
+
+	return value
+}
+
+// collectFields collects the response keys of a selection set in order, and the selection sets of their fields.
+// Fragments are followed regardless of their type conditions, since the result has only the fields of its type.
+func collectFields(doc *ast.Document, set *ast.SelectionSet, result map[string]interface{}, keys *[]string, sets map[string][]*ast.SelectionSet) {
+	if set == nil {
+		return
+	}
+
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			key := s.Name.Value
+			if s.Alias != nil {
+				key = s.Alias.Value
+			}
+
+			if _, ok := result[key]; !ok {
+				continue
+			}
+
+			if _, ok := sets[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			sets[key] = append(sets[key], s.SelectionSet)
+
+		case *ast.InlineFragment:
+			collectFields(doc, s.SelectionSet, result, keys, sets)
+
+		case *ast.FragmentSpread:
+			for _, def := range doc.Definitions {
+				if f, ok := def.(*ast.FragmentDefinition); ok && f.Name.Value == s.Name.Value {
+					collectFields(doc, f.SelectionSet, result, keys, sets)
+				}
+			}
+		}
+	}
+}
+
+// asObject converts a value to an object if possible.
+func asObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case JSON:
+		return v, true
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			obj[fmt.Sprint(key)] = val
+		}
+		return obj, true
+	}
+
+	return nil, false
+}
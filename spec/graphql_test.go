@@ -0,0 +1,691 @@
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `
+"""A user of the app"""
+type User implements Node {
+  id: ID!
+  name: String!
+  email: String
+  role: Role!
+  posts(limit: Int = 10): [Post!]!
+}
+
+type Post implements Node {
+  id: ID!
+  title: String!
+}
+
+interface Node {
+  id: ID!
+}
+
+union SearchResult = User | Post
+
+enum Role {
+  ADMIN
+  MEMBER
+  GUEST @deprecated(reason: "Use MEMBER")
+}
+
+input UserInput {
+  name: String!
+  email: String
+  role: Role = MEMBER
+}
+
+type Query {
+  user(id: ID!): User
+  users(role: Role): [User!]!
+  search(text: String!): [SearchResult!]!
+  node(id: ID!): Node
+}
+
+type Mutation {
+  createUser(input: UserInput!): User!
+  deleteUser(id: ID!): User
+}
+`
+
+var testRoot = JSON{
+	"user": JSON{
+		"id":    1,
+		"name":  "Jane",
+		"email": "jane@example.com",
+		"role":  "ADMIN",
+		"posts": []interface{}{
+			JSON{"id": "p1", "title": "Hello"},
+		},
+	},
+	"users": []interface{}{
+		JSON{"id": "1", "name": "Jane", "role": "ADMIN", "posts": []interface{}{}},
+		JSON{"id": "2", "name": nil, "role": "MEMBER", "posts": []interface{}{}},
+	},
+	"search": []interface{}{
+		JSON{"__typename": "Post", "id": "p1", "title": "Hello"},
+		JSON{"id": "1", "name": "Jane", "role": "ADMIN", "posts": []interface{}{}},
+	},
+	"node": JSON{"__typename": "Post", "id": "p1", "title": "Hello"},
+}
+
+func TestParseGraphQLSchema(t *testing.T) {
+	s, err := parseGraphQLSchema(testSchema, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Query", s.QueryType().Name())
+	assert.Equal(t, "Mutation", s.MutationType().Name())
+	assert.Nil(t, s.SubscriptionType())
+
+	user := s.Type("User").(*graphql.Object)
+	assert.Equal(t, "A user of the app", user.Description())
+	assert.Equal(t, "Node", user.Interfaces()[0].Name())
+	assert.Equal(t, "[Post!]!", user.Fields()["posts"].Type.String())
+	assert.Equal(t, 10, user.Fields()["posts"].Args[0].DefaultValue)
+
+	search := s.Type("SearchResult").(*graphql.Union)
+	assert.Len(t, search.Types(), 2)
+	assert.Len(t, s.PossibleTypes(s.Type("Node").(*graphql.Interface)), 2)
+
+	for _, v := range s.Type("Role").(*graphql.Enum).Values() {
+		if v.Name == "GUEST" {
+			assert.Equal(t, "Use MEMBER", v.DeprecationReason)
+		}
+	}
+
+	assert.Equal(t, "MEMBER", s.Type("UserInput").(*graphql.InputObject).Fields()["role"].DefaultValue)
+
+	s, err = parseGraphQLSchema("schema { query: Root } type Root { a: Int } extend type Root { b: String }", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Root", s.QueryType().Name())
+	assert.NotNil(t, s.QueryType().Fields()["b"])
+
+	tests := []struct {
+		name          string
+		sdl           string
+		expectedError string
+	}{
+		{
+			name:          "NoQuery",
+			sdl:           "type User { id: ID }",
+			expectedError: "query root type must be provided",
+		},
+		{
+			name:          "UnknownType",
+			sdl:           "type Query { user: User }",
+			expectedError: "unknown type User in Query.user",
+		},
+		{
+			name:          "InputAsOutput",
+			sdl:           "input Filter { a: Int } type Query { f: Filter }",
+			expectedError: "Query.f must be an output type",
+		},
+		{
+			name:          "OutputAsInput",
+			sdl:           "type User { id: ID } type Query { f(u: User): Int }",
+			expectedError: "Query.f(u:) must be an input type",
+		},
+		{
+			name:          "DuplicateType",
+			sdl:           "type Query { a: Int } type Query { b: Int }",
+			expectedError: `There can be only one type named "Query".`,
+		},
+		{
+			name:          "InvalidUnionMember",
+			sdl:           "union U = String type Query { u: U }",
+			expectedError: "union U can only include object types, it cannot include String",
+		},
+		{
+			name:          "NoFieldType",
+			sdl:           "type Query { a: }",
+			expectedError: "no type for Query.a",
+		},
+		{
+			name:          "SyntaxError",
+			sdl:           "type Query { a: Int",
+			expectedError: "Syntax Error GraphQL (1:20) Expected Name, found EOF",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseGraphQLSchema(tc.sdl, nil)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+// executeGraphQL executes a query against the test schema and returns the response as JSON.
+func executeGraphQL(t *testing.T, query string, variables map[string]interface{}, root interface{}) string {
+	s := &graphqlServer{}
+	schema, err := parseGraphQLSchema(testSchema, s.resolve)
+	assert.NoError(t, err)
+	s.schema = schema
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	assert.NoError(t, err)
+
+	op, err := selectOperation(doc, "")
+	assert.NoError(t, err)
+
+	req := &graphqlRequest{Query: query, Variables: variables}
+	statusCode, data, errs := s.execute(context.Background(), doc, op, req, root)
+
+	resp := newGraphQLObject()
+	if len(errs) > 0 {
+		resp.set("errors", errs)
+	}
+	if statusCode == http.StatusOK {
+		resp.set("data", data)
+	}
+
+	b, err := json.Marshal(resp)
+	assert.NoError(t, err)
+
+	return string(b)
+}
+
+func TestGraphQLExecute(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		variables        map[string]interface{}
+		expectedResponse string
+	}{
+		{
+			name:             "Fields",
+			query:            `{ user(id: "1") { id name email role } }`,
+			expectedResponse: `{"data":{"user":{"id":"1","name":"Jane","email":"jane@example.com","role":"ADMIN"}}}`,
+		},
+		{
+			name:             "AliasesAndOrder",
+			query:            `{ user(id: "1") { role n: name id } me: user(id: "1") { name } }`,
+			expectedResponse: `{"data":{"user":{"role":"ADMIN","n":"Jane","id":"1"},"me":{"name":"Jane"}}}`,
+		},
+		{
+			name:             "Fragments",
+			query:            `{ user(id: "1") { ...F ... on User { email } ... { name } } } fragment F on Node { id }`,
+			expectedResponse: `{"data":{"user":{"id":"1","email":"jane@example.com","name":"Jane"}}}`,
+		},
+		{
+			name:             "Directives",
+			query:            `query ($skip: Boolean!) { user(id: "1") { id @skip(if: $skip) name @include(if: false) email } }`,
+			variables:        map[string]interface{}{"skip": true},
+			expectedResponse: `{"data":{"user":{"email":"jane@example.com"}}}`,
+		},
+		{
+			name:             "Typename",
+			query:            `{ __typename user(id: "1") { __typename } }`,
+			expectedResponse: `{"data":{"__typename":"Query","user":{"__typename":"User"}}}`,
+		},
+		{
+			name:             "Union",
+			query:            `{ search(text: "he") { __typename ... on Post { title } ... on User { name } } }`,
+			expectedResponse: `{"data":{"search":[{"__typename":"Post","title":"Hello"},{"__typename":"User","name":"Jane"}]}}`,
+		},
+		{
+			name:             "Interface",
+			query:            `{ node(id: "p1") { id ... on Post { title } } }`,
+			expectedResponse: `{"data":{"node":{"id":"p1","title":"Hello"}}}`,
+		},
+		{
+			name:             "NonNullPropagation",
+			query:            `{ users { id name } }`,
+			expectedResponse: `{"errors":[{"message":"Cannot return null for non-nullable field User.name.","locations":[{"line":1,"column":14}],"path":["users",1,"name"]}],"data":null}`,
+		},
+		{
+			name:             "SameFieldWithAlias",
+			query:            `{ user(id: "1") { id } missing: node(id: "x") { id } }`,
+			expectedResponse: `{"data":{"user":{"id":"1"},"missing":{"id":"p1"}}}`,
+		},
+		{
+			name:             "MissingVariable",
+			query:            `query ($id: ID!) { user(id: $id) { id } }`,
+			expectedResponse: `{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided.","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:             "InvalidVariable",
+			query:            `query ($role: Role) { users(role: $role) { id } }`,
+			variables:        map[string]interface{}{"role": "OWNER"},
+			expectedResponse: `{"errors":[{"message":"Variable \"$role\" got invalid value \"OWNER\".","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:             "UnknownField",
+			query:            `{ user(id: "1") { age } }`,
+			expectedResponse: `{"errors":[{"message":"Cannot query field \"age\" on type \"User\". Did you mean \"name\"?","locations":[{"line":1,"column":19}]}]}`,
+		},
+		{
+			name:             "MissingArgument",
+			query:            `{ user { id } }`,
+			expectedResponse: `{"errors":[{"message":"Field \"user\" argument \"id\" of type \"ID!\" is required but not provided.","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:             "MissingSubselection",
+			query:            `{ user(id: "1") }`,
+			expectedResponse: `{"errors":[{"message":"Field \"user\" of type \"User\" must have a sub selection.","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:             "UnknownFragment",
+			query:            `{ user(id: "1") { ...F } }`,
+			expectedResponse: `{"errors":[{"message":"Unknown fragment \"F\".","locations":[{"line":1,"column":22}]}]}`,
+		},
+		{
+			name:             "UndefinedVariable",
+			query:            `{ user(id: $id) { id } }`,
+			expectedResponse: `{"errors":[{"message":"Variable \"$id\" is not defined.","locations":[{"line":1,"column":12},{"line":1,"column":1}]}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := executeGraphQL(t, tc.query, tc.variables, testRoot)
+			assert.Equal(t, tc.expectedResponse, resp)
+		})
+	}
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	query := `{
+	  __schema {
+	    queryType { name }
+	    mutationType { name }
+	    subscriptionType { name }
+	    directives { name }
+	  }
+	  user: __type(name: "User") {
+	    kind
+	    name
+	    description
+	    interfaces { name }
+	    fields {
+	      name
+	      args { name defaultValue }
+	      type { kind name ofType { kind name ofType { name } } }
+	    }
+	  }
+	  role: __type(name: "Role") {
+	    kind
+	    enumValues(includeDeprecated: true) { name isDeprecated deprecationReason }
+	  }
+	  input: __type(name: "UserInput") {
+	    inputFields { name defaultValue }
+	  }
+	  unknown: __type(name: "Unknown") { name }
+	}`
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(executeGraphQL(t, query, nil, testRoot)), &resp))
+
+	schema := resp.Data["__schema"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Query"}, schema["queryType"])
+	assert.Equal(t, map[string]interface{}{"name": "Mutation"}, schema["mutationType"])
+	assert.Nil(t, schema["subscriptionType"])
+	assert.Len(t, schema["directives"], 3)
+
+	user := resp.Data["user"].(map[string]interface{})
+	assert.Equal(t, "OBJECT", user["kind"])
+	assert.Equal(t, "A user of the app", user["description"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "Node"}}, user["interfaces"])
+
+	// Fields are sorted by name
+	fields := user["fields"].([]interface{})
+	assert.Len(t, fields, 5)
+	assert.Equal(t, map[string]interface{}{
+		"name": "id",
+		"args": []interface{}{},
+		"type": map[string]interface{}{
+			"kind":   "NON_NULL",
+			"name":   nil,
+			"ofType": map[string]interface{}{"kind": "SCALAR", "name": "ID", "ofType": nil},
+		},
+	}, fields[1])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "limit", "defaultValue": "10"},
+	}, fields[3].(map[string]interface{})["args"])
+
+	// Enum values and input fields are not in the order of the schema
+	role := resp.Data["role"].(map[string]interface{})
+	assert.Equal(t, "ENUM", role["kind"])
+	assert.Len(t, role["enumValues"], 3)
+	assert.Contains(t, role["enumValues"], map[string]interface{}{
+		"name":              "GUEST",
+		"isDeprecated":      true,
+		"deprecationReason": "Use MEMBER",
+	})
+
+	// Enum default values of input fields are printed as strings by graphql-go
+	input := resp.Data["input"].(map[string]interface{})
+	assert.Contains(t, input["inputFields"], map[string]interface{}{"name": "role", "defaultValue": `"MEMBER"`})
+
+	assert.Nil(t, resp.Data["unknown"])
+}
+
+func TestGraphQLMockSetDefaults(t *testing.T) {
+	m := GraphQLMock{}
+	m.SetDefaults()
+	assert.Equal(t, "/graphql", m.Path)
+
+	m = GraphQLMock{Path: "api/graphql/"}
+	m.SetDefaults()
+	assert.Equal(t, "/api/graphql", m.Path)
+}
+
+func TestGraphQLMockValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		mock          GraphQLMock
+		expectedError string
+	}{
+		{
+			name:          "NoSchema",
+			mock:          GraphQLMock{},
+			expectedError: "one of schema or schema_file should be set",
+		},
+		{
+			name:          "SchemaFileNotFound",
+			mock:          GraphQLMock{SchemaFile: "test/missing.graphql"},
+			expectedError: "invalid schema_file: open test/missing.graphql: no such file or directory",
+		},
+		{
+			name:          "InvalidSchema",
+			mock:          GraphQLMock{Schema: "type Query { a: B }"},
+			expectedError: "invalid schema: unknown type B in Query.a",
+		},
+		{
+			name: "NoOperationName",
+			mock: GraphQLMock{
+				Schema:     testSchema,
+				Operations: []GraphQLOperation{{}},
+			},
+			expectedError: "operation name is required",
+		},
+		{
+			name: "InvalidVariablePattern",
+			mock: GraphQLMock{
+				Schema:     testSchema,
+				Operations: []GraphQLOperation{{Name: "GetUser", Variables: map[string]string{"id": "("}}},
+			},
+			expectedError: "invalid operation GetUser: invalid variable id: error parsing regexp: missing closing ): `^($`",
+		},
+		{
+			name: "InvalidArgumentPattern",
+			mock: GraphQLMock{
+				Schema:    testSchema,
+				Resolvers: []GraphQLResolver{{Field: "Query.users", Args: map[string]string{"role": "["}}},
+			},
+			expectedError: "invalid resolver for Query.users: invalid argument role: error parsing regexp: missing closing ]: `[$`",
+		},
+		{
+			name: "InvalidResolverField",
+			mock: GraphQLMock{
+				Schema:    testSchema,
+				Resolvers: []GraphQLResolver{{Field: "user"}},
+			},
+			expectedError: "invalid resolver field user: expected Type.field",
+		},
+		{
+			name: "UnknownResolverField",
+			mock: GraphQLMock{
+				Schema:    testSchema,
+				Resolvers: []GraphQLResolver{{Field: "Query.posts"}},
+			},
+			expectedError: "invalid resolver field Query.posts: no such field",
+		},
+		{
+			name: "UnknownStore",
+			mock: GraphQLMock{
+				Schema:    testSchema,
+				Resolvers: []GraphQLResolver{{Field: "Query.users", Store: "users"}},
+			},
+			expectedError: "invalid resolver for Query.users: unknown store users",
+		},
+		{
+			name: "UnknownAction",
+			mock: GraphQLMock{
+				Schema:    testSchema,
+				Resolvers: []GraphQLResolver{{Field: "Query.users", Store: "users", Action: "find"}},
+				Stores:    map[string]RESTStore{"users": {}},
+			},
+			expectedError: "invalid resolver for Query.users: unknown store action find",
+		},
+		{
+			name: "Valid",
+			mock: GraphQLMock{
+				SchemaFile: "test/schema.graphql",
+				Resolvers: []GraphQLResolver{
+					{Field: "Query.users", Store: "users"},
+					{Field: "Mutation.createUser", Store: "users"},
+				},
+				Stores: map[string]RESTStore{"users": {}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mock.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestGraphQLMockString(t *testing.T) {
+	m := GraphQLMock{Host: "api.example.com", Path: "/graphql"}
+	assert.Equal(t, "GraphQL api.example.com/graphql", m.String())
+
+	m.Listener = "admin"
+	assert.Equal(t, "admin: GraphQL api.example.com/graphql", m.String())
+}
+
+func TestGraphQLMockServeHTTP(t *testing.T) {
+	m := GraphQLMock{
+		Schema: testSchema,
+		Operations: []GraphQLOperation{
+			{
+				Name:      "GetUser",
+				Variables: map[string]string{"id": "4[0-9]+"},
+				Errors:    []GraphQLError{{Message: "user not found", Path: []interface{}{"user"}}},
+			},
+			{
+				Name: "GetUser",
+				Data: JSON{
+					"user": JSON{"id": "7", "name": "Canned", "role": "GUEST", "email": "canned@example.com"},
+				},
+			},
+		},
+		Resolvers: []GraphQLResolver{
+			{Field: "Query.user", Args: map[string]string{"id": "0"}, Error: "invalid id"},
+			{Field: "Query.user", Store: "users"},
+			{Field: "Query.users", Store: "users"},
+			{Field: "Query.search", Data: []interface{}{JSON{"id": "p9", "title": "Static"}}},
+			{Field: "Mutation.createUser", Store: "users"},
+			{Field: "Mutation.deleteUser", Store: "users"},
+			{Field: "User.posts", Data: []interface{}{JSON{"id": "p1", "title": "Hello"}}},
+		},
+		Stores: map[string]RESTStore{
+			"users": {
+				Objects: []JSON{
+					{"id": "1", "name": "Jane", "role": "ADMIN"},
+					{"id": "2", "name": "John", "role": "MEMBER"},
+				},
+			},
+		},
+	}
+	m.SetDefaults()
+
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+
+	post := func(body string) (int, string) {
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		contentType      string
+		query            url.Values
+		body             string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "CannedData",
+			body:             `{"query":"query GetUser($id: ID!) { user(id: $id) { name role } }","operationName":"GetUser","variables":{"id":"7"}}`,
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"user":{"name":"Canned","role":"GUEST"}}}`,
+		},
+		{
+			name:             "CannedErrors",
+			body:             `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"42"}}`,
+			expectedStatus:   200,
+			expectedResponse: `{"errors":[{"message":"user not found","path":["user"]}],"data":null}`,
+		},
+		{
+			name:             "StoreGet",
+			body:             `{"query":"{ user(id: 2) { id name posts { title } } }"}`,
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"user":{"id":"2","name":"John","posts":[{"title":"Hello"}]}}}`,
+		},
+		{
+			name:             "StoreList",
+			body:             `{"query":"{ users(role: ADMIN) { name } }"}`,
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"users":[{"name":"Jane"}]}}`,
+		},
+		{
+			name:             "ResolverError",
+			body:             `{"query":"{ user(id: 0) { name } }"}`,
+			expectedStatus:   200,
+			expectedResponse: `{"errors":[{"message":"invalid id","locations":[{"line":1,"column":3}],"path":["user"]}],"data":{"user":null}}`,
+		},
+		{
+			name:             "StaticData",
+			body:             `{"query":"{ search(text: \"x\") { ... on Post { id title } } }"}`,
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"search":[{"id":"p9","title":"Static"}]}}`,
+		},
+		{
+			name:             "GetQuery",
+			method:           "GET",
+			query:            url.Values{"query": {"query Q($id: ID!) { user(id: $id) { name } }"}, "variables": {`{"id":"1"}`}},
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"user":{"name":"Jane"}}}`,
+		},
+		{
+			name:             "GetMutation",
+			method:           "GET",
+			query:            url.Values{"query": {`mutation { deleteUser(id: "1") { id } }`}},
+			expectedStatus:   405,
+			expectedResponse: `{"errors":[{"message":"Can only perform a mutation operation from a POST request."}]}`,
+		},
+		{
+			name:             "ApplicationGraphQL",
+			contentType:      "application/graphql",
+			body:             `{ users { id } }`,
+			expectedStatus:   200,
+			expectedResponse: `{"data":{"users":[{"id":"1"},{"id":"2"}]}}`,
+		},
+		{
+			name:             "NoQuery",
+			body:             `{}`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"Must provide query string."}]}`,
+		},
+		{
+			name:             "InvalidJSON",
+			body:             `{`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"POST body sent invalid JSON."}]}`,
+		},
+		{
+			name:             "SyntaxError",
+			body:             `{"query":"{ user(id: 1) { } }"}`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"Syntax Error GraphQL (1:15) Unexpected empty IN {}","locations":[{"line":1,"column":15}]}]}`,
+		},
+		{
+			name:             "MissingVariable",
+			body:             `{"query":"query ($id: ID!) { user(id: $id) { name } }"}`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided.","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:             "MultipleOperations",
+			body:             `{"query":"query A { users { id } } query B { users { name } }"}`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"Must provide operation name if query contains multiple operations."}]}`,
+		},
+		{
+			name:             "UnknownOperation",
+			body:             `{"query":"query A { users { id } }","operationName":"B"}`,
+			expectedStatus:   400,
+			expectedResponse: `{"errors":[{"message":"Unknown operation named \"B\"."}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = "POST"
+			}
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			r := httptest.NewRequest(method, "/graphql?"+tc.query.Encode(), strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedResponse, strings.TrimSpace(w.Body.String()))
+		})
+	}
+
+	t.Run("StoreMutations", func(t *testing.T) {
+		status, resp := post(`{"query":"mutation { createUser(input: {name: \"Ann\"}) { id name role } }"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"data":{"createUser":{"id":"3","name":"Ann","role":"MEMBER"}}}`, resp)
+
+		status, resp = post(`{"query":"mutation { deleteUser(id: \"1\") { name } }"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"data":{"deleteUser":{"name":"Jane"}}}`, resp)
+
+		_, resp = post(`{"query":"{ users { id } }"}`)
+		assert.Equal(t, `{"data":{"users":[{"id":"2"},{"id":"3"}]}}`, resp)
+
+		_, resp = post(`{"query":"mutation { deleteUser(id: \"1\") { name } }"}`)
+		assert.Equal(t, `{"data":{"deleteUser":null}}`, resp)
+
+		assert.Len(t, m.Stores["users"].Objects, 2)
+	})
+}
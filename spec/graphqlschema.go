@@ -0,0 +1,434 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// graphqlSchemaBuilder builds an executable schema from a schema definition.
+// Every field of object types is resolved by the same resolve function.
+type graphqlSchemaBuilder struct {
+	defs       map[string]ast.Node
+	extensions map[string][]*ast.ObjectDefinition
+	operations map[string]string
+	types      map[string]graphql.Type
+	resolve    graphql.FieldResolveFn
+	err        error
+}
+
+// parseGraphQLSchema parses a schema definition into an executable schema.
+func parseGraphQLSchema(sdl string, resolve graphql.FieldResolveFn) (*graphql.Schema, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: sdl})
+	if err != nil {
+		return nil, graphqlError(gqlerrors.FormatError(err))
+	}
+
+	b := &graphqlSchemaBuilder{
+		defs:       map[string]ast.Node{},
+		extensions: map[string][]*ast.ObjectDefinition{},
+		operations: map[string]string{},
+		types: map[string]graphql.Type{
+			"String":  graphql.String,
+			"Int":     graphql.Int,
+			"Float":   graphql.Float,
+			"Boolean": graphql.Boolean,
+			"ID":      graphql.ID,
+		},
+		resolve: resolve,
+	}
+
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.SchemaDefinition:
+			for _, op := range d.OperationTypes {
+				b.operations[op.Operation] = op.Type.Name.Value
+			}
+
+		case *ast.TypeExtensionDefinition:
+			name := d.Definition.Name.Value
+			b.extensions[name] = append(b.extensions[name], d.Definition)
+
+		default:
+			name := typeDefName(d)
+			if name == "" {
+				continue
+			}
+			if _, ok := b.defs[name]; ok {
+				return nil, fmt.Errorf("There can be only one type named %q.", name)
+			}
+			b.defs[name] = d
+		}
+	}
+
+	return b.build()
+}
+
+// build creates the types of the schema and the schema itself.
+func (b *graphqlSchemaBuilder) build() (*graphql.Schema, error) {
+	config := graphql.SchemaConfig{}
+
+	for _, name := range sortedNodeKeys(b.defs) {
+		config.Types = append(config.Types, b.named(name))
+	}
+
+	config.Query = b.root("query", "Query")
+	config.Mutation = b.root("mutation", "Mutation")
+	config.Subscription = b.root("subscription", "Subscription")
+
+	if config.Query == nil {
+		return nil, errors.New("query root type must be provided")
+	}
+
+	schema, err := graphql.NewSchema(config)
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// root returns the root type of an operation set by the schema definition or by its default name.
+func (b *graphqlSchemaBuilder) root(operation, defaultName string) *graphql.Object {
+	name, ok := b.operations[operation]
+	if !ok {
+		name = defaultName
+	}
+
+	t, _ := b.types[name].(*graphql.Object)
+
+	return t
+}
+
+// fail records the first error in building the schema.
+func (b *graphqlSchemaBuilder) fail(format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+}
+
+// named returns a named type of the schema, creating it once from its definition.
+func (b *graphqlSchemaBuilder) named(name string) graphql.Type {
+	if t, ok := b.types[name]; ok {
+		return t
+	}
+
+	var t graphql.Type
+
+	switch d := b.defs[name].(type) {
+	case *ast.ObjectDefinition:
+		defs := append([]*ast.ObjectDefinition{d}, b.extensions[name]...)
+		t = graphql.NewObject(graphql.ObjectConfig{
+			Name:        name,
+			Description: description(d.Description),
+			Interfaces: (graphql.InterfacesThunk)(func() []*graphql.Interface {
+				interfaces := []*graphql.Interface{}
+				for _, d := range defs {
+					for _, named := range d.Interfaces {
+						if i, ok := b.named(named.Name.Value).(*graphql.Interface); ok {
+							interfaces = append(interfaces, i)
+						} else {
+							b.fail("type %s can only implement interfaces, it cannot implement %s", name, named.Name.Value)
+						}
+					}
+				}
+				return interfaces
+			}),
+			Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+				fields := []*ast.FieldDefinition{}
+				for _, d := range defs {
+					fields = append(fields, d.Fields...)
+				}
+				return b.fields(name, fields, b.resolve)
+			}),
+		})
+
+	case *ast.InterfaceDefinition:
+		t = graphql.NewInterface(graphql.InterfaceConfig{
+			Name:        name,
+			Description: description(d.Description),
+			ResolveType: resolveType(name),
+			Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+				return b.fields(name, d.Fields, nil)
+			}),
+		})
+
+	case *ast.UnionDefinition:
+		t = graphql.NewUnion(graphql.UnionConfig{
+			Name:        name,
+			Description: description(d.Description),
+			ResolveType: resolveType(name),
+			Types: (graphql.UnionTypesThunk)(func() []*graphql.Object {
+				objects := []*graphql.Object{}
+				for _, named := range d.Types {
+					if o, ok := b.named(named.Name.Value).(*graphql.Object); ok {
+						objects = append(objects, o)
+					} else {
+						b.fail("union %s can only include object types, it cannot include %s", name, named.Name.Value)
+					}
+				}
+				return objects
+			}),
+		})
+
+	case *ast.EnumDefinition:
+		values := graphql.EnumValueConfigMap{}
+		for _, v := range d.Values {
+			values[v.Name.Value] = &graphql.EnumValueConfig{
+				Value:             v.Name.Value,
+				Description:       description(v.Description),
+				DeprecationReason: deprecationReason(v.Directives),
+			}
+		}
+		t = graphql.NewEnum(graphql.EnumConfig{
+			Name:        name,
+			Description: description(d.Description),
+			Values:      values,
+		})
+
+	case *ast.InputObjectDefinition:
+		t = graphql.NewInputObject(graphql.InputObjectConfig{
+			Name:        name,
+			Description: description(d.Description),
+			Fields: (graphql.InputObjectConfigFieldMapThunk)(func() graphql.InputObjectConfigFieldMap {
+				fields := graphql.InputObjectConfigFieldMap{}
+				for _, f := range d.Fields {
+					fields[f.Name.Value] = &graphql.InputObjectFieldConfig{
+						Type:         b.inputType(name+"."+f.Name.Value, f.Type),
+						DefaultValue: astValue(f.DefaultValue),
+						Description:  description(f.Description),
+					}
+				}
+				return fields
+			}),
+		})
+
+	case *ast.ScalarDefinition:
+		// Custom scalars are passed through as is.
+		t = graphql.NewScalar(graphql.ScalarConfig{
+			Name:         name,
+			Description:  description(d.Description),
+			Serialize:    func(v interface{}) interface{} { return v },
+			ParseValue:   func(v interface{}) interface{} { return v },
+			ParseLiteral: func(v ast.Value) interface{} { return astValue(v) },
+		})
+
+	default:
+		return nil
+	}
+
+	b.types[name] = t
+
+	return t
+}
+
+// fields creates the fields of an object or an interface type.
+func (b *graphqlSchemaBuilder) fields(typeName string, defs []*ast.FieldDefinition, resolve graphql.FieldResolveFn) graphql.Fields {
+	fields := graphql.Fields{}
+
+	for _, f := range defs {
+		name := typeName + "." + f.Name.Value
+
+		args := graphql.FieldConfigArgument{}
+		for _, a := range f.Arguments {
+			args[a.Name.Value] = &graphql.ArgumentConfig{
+				Type:         b.inputType(name+"("+a.Name.Value+":)", a.Type),
+				DefaultValue: astValue(a.DefaultValue),
+				Description:  description(a.Description),
+			}
+		}
+
+		fields[f.Name.Value] = &graphql.Field{
+			Name:              f.Name.Value,
+			Type:              b.outputType(name, f.Type),
+			Args:              args,
+			Resolve:           resolve,
+			Description:       description(f.Description),
+			DeprecationReason: deprecationReason(f.Directives),
+		}
+	}
+
+	return fields
+}
+
+// typeRef returns the type of a type reference.
+func (b *graphqlSchemaBuilder) typeRef(name string, ref ast.Type) graphql.Type {
+	switch r := ref.(type) {
+	case *ast.NonNull:
+		if t := b.typeRef(name, r.Type); t != nil {
+			return graphql.NewNonNull(t)
+		}
+	case *ast.List:
+		if t := b.typeRef(name, r.Type); t != nil {
+			return graphql.NewList(t)
+		}
+	case *ast.Named:
+		if t := b.named(r.Name.Value); t != nil {
+			return t
+		}
+		b.fail("unknown type %s in %s", r.Name.Value, name)
+	default:
+		b.fail("no type for %s", name)
+	}
+
+	return nil
+}
+
+// outputType returns the type of a field, which should be an output type.
+func (b *graphqlSchemaBuilder) outputType(name string, ref ast.Type) graphql.Output {
+	t, ok := b.typeRef(name, ref).(graphql.Output)
+	if !ok || !graphql.IsOutputType(t) {
+		b.fail("%s must be an output type", name)
+		return graphql.String
+	}
+
+	return t
+}
+
+// inputType returns the type of an argument or an input field, which should be an input type.
+func (b *graphqlSchemaBuilder) inputType(name string, ref ast.Type) graphql.Input {
+	t, ok := b.typeRef(name, ref).(graphql.Input)
+	if !ok || !graphql.IsInputType(t) {
+		b.fail("%s must be an input type", name)
+		return graphql.String
+	}
+
+	return t
+}
+
+// resolveType creates a function determining the object type of a value of an abstract type.
+// The type is either set by __typename or is the first possible type with all properties of the value as fields.
+func resolveType(abstract string) graphql.ResolveTypeFn {
+	return func(p graphql.ResolveTypeParams) *graphql.Object {
+		obj, ok := asObject(p.Value)
+		if !ok {
+			return nil
+		}
+
+		possible := p.Info.Schema.PossibleTypes(p.Info.Schema.Type(abstract).(graphql.Abstract))
+
+		if name, ok := obj["__typename"].(string); ok {
+			for _, t := range possible {
+				if t.Name() == name {
+					return t
+				}
+			}
+			return nil
+		}
+
+		for _, t := range possible {
+			all := true
+			for key := range obj {
+				_, ok := t.Fields()[key]
+				all = all && ok
+			}
+
+			if all {
+				return t
+			}
+		}
+
+		return nil
+	}
+}
+
+// typeDefName returns the name of a type definition, or an empty string for other definitions.
+func typeDefName(def ast.Node) string {
+	switch d := def.(type) {
+	case *ast.ObjectDefinition:
+		return d.Name.Value
+	case *ast.InterfaceDefinition:
+		return d.Name.Value
+	case *ast.UnionDefinition:
+		return d.Name.Value
+	case *ast.EnumDefinition:
+		return d.Name.Value
+	case *ast.InputObjectDefinition:
+		return d.Name.Value
+	case *ast.ScalarDefinition:
+		return d.Name.Value
+	}
+
+	return ""
+}
+
+// description returns the value of a description.
+func description(s *ast.StringValue) string {
+	if s == nil {
+		return ""
+	}
+	return s.Value
+}
+
+// deprecationReason returns the reason of a @deprecated directive, if there is one.
+func deprecationReason(directives []*ast.Directive) string {
+	for _, d := range directives {
+		if d.Name.Value != "deprecated" {
+			continue
+		}
+
+		for _, arg := range d.Arguments {
+			if arg.Name.Value == "reason" {
+				if s, ok := astValue(arg.Value).(string); ok {
+					return s
+				}
+			}
+		}
+
+		return graphql.DefaultDeprecationReason
+	}
+
+	return ""
+}
+
+// astValue converts a constant value in a schema definition to a Go value.
+func astValue(v ast.Value) interface{} {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		i, _ := strconv.Atoi(v.Value)
+		return i
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case *ast.StringValue:
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	case *ast.ListValue:
+		list := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			list[i] = astValue(item)
+		}
+		return list
+	case *ast.ObjectValue:
+		obj := map[string]interface{}{}
+		for _, f := range v.Fields {
+			obj[f.Name.Value] = astValue(f.Value)
+		}
+		return obj
+	}
+
+	return nil
+}
+
+// sortedNodeKeys returns the sorted names of type definitions.
+func sortedNodeKeys(defs map[string]ast.Node) []string {
+	keys := make([]string, 0, len(defs))
+	for key := range defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
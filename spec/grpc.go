@@ -0,0 +1,906 @@
+package spec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcReflectionService is the name of the gRPC server reflection service.
+const grpcReflectionService = "grpc.reflection.v1alpha.ServerReflection"
+
+// IsGRPC determines whether or not an http request is a gRPC call.
+// The body of a gRPC call is a stream, so it should not be read ahead of serving the call.
+func IsGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// GRPCStatus represents a gRPC status returned by a mock method.
+// Code is either the name of a status code (i.e. NOT_FOUND) or its number.
+type GRPCStatus struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// Err returns the status as an error.
+// It returns nil if the status is not set or its code is OK.
+func (s *GRPCStatus) Err() error {
+	if s == nil {
+		return nil
+	}
+
+	code, err := s.code()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return status.Error(code, s.Message)
+}
+
+func (s *GRPCStatus) code() (codes.Code, error) {
+	b := []byte(strconv.Quote(strings.ToUpper(s.Code)))
+	if _, err := strconv.Atoi(s.Code); err == nil {
+		b = []byte(s.Code)
+	}
+
+	var code codes.Code
+	if err := code.UnmarshalJSON(b); err != nil {
+		return 0, fmt.Errorf("invalid status code %s", s.Code)
+	}
+
+	return code, nil
+}
+
+// GRPCMethod represents a mock response for a gRPC method.
+// Name is the full name of the method in the form of package.Service/Method.
+// Match maps the fields of the request message to regular expressions, and nested fields are separated by dots.
+// A method with a single response responds with Response, and a server-streaming method sends Stream instead.
+// A bidirectional-streaming method responds to every message it receives.
+// Messages are defined in the JSON mapping of their proto messages, and each is sent after Delay.
+// If Status is set, the call ends with it after the messages are sent.
+type GRPCMethod struct {
+	Name     string            `json:"name" yaml:"name"`
+	Match    map[string]string `json:"match" yaml:"match"`
+	Delay    string            `json:"delay" yaml:"delay"`
+	Response interface{}       `json:"response" yaml:"response"`
+	Stream   []interface{}     `json:"stream" yaml:"stream"`
+	Status   *GRPCStatus       `json:"status" yaml:"status"`
+}
+
+// matches determines whether or not a request message satisfies all field criteria of the method.
+func (m GRPCMethod) matches(msg protoreflect.Message) bool {
+	for path, pattern := range m.Match {
+		if !matchAny(pattern, grpcFieldValues(msg, path)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// service returns the full name of the service of the method.
+func (m GRPCMethod) service() string {
+	if i := strings.LastIndex(m.Name, "/"); i >= 0 {
+		return strings.TrimPrefix(m.Name[:i], "/")
+	}
+	return ""
+}
+
+// fullName returns the full name of the method descriptor.
+func (m GRPCMethod) fullName() protoreflect.FullName {
+	return protoreflect.FullName(strings.Replace(strings.TrimPrefix(m.Name, "/"), "/", ".", 1))
+}
+
+// grpcFieldValues returns the values of a field of a message as strings.
+// Fields are looked up by either their proto names or their JSON names.
+func grpcFieldValues(msg protoreflect.Message, path string) []string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(segment))
+		if fd == nil {
+			fd = fields.ByJSONName(segment)
+		}
+		if fd == nil {
+			return nil
+		}
+
+		v := msg.Get(fd)
+
+		if i == len(segments)-1 {
+			if fd.IsMap() {
+				return nil
+			}
+
+			if fd.IsList() {
+				list := v.List()
+				values := make([]string, list.Len())
+				for j := range values {
+					values[j] = grpcValueString(fd, list.Get(j))
+				}
+				return values
+			}
+
+			return []string{grpcValueString(fd, v)}
+		}
+
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil
+		}
+		msg = v.Message()
+	}
+
+	return nil
+}
+
+// grpcValueString formats a field value the same way as its JSON mapping.
+func grpcValueString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		b, _ := protojson.Marshal(v.Message().Interface())
+		return string(b)
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	default:
+		return v.String()
+	}
+}
+
+// GRPCMock represents a mock gRPC server.
+// The services are described by a compiled FileDescriptorSet (i.e. protoc --include_imports --descriptor_set_out).
+// Methods of the same name are matched in order, and the first one matching the request responds.
+// If Reflection is enabled, the services can be discovered using the gRPC server reflection protocol.
+type GRPCMock struct {
+	Listener      string       `json:"listener" yaml:"listener"`
+	Host          string       `json:"host" yaml:"host"`
+	DescriptorSet string       `json:"descriptorSet" yaml:"descriptor_set"`
+	Reflection    bool         `json:"reflection" yaml:"reflection"`
+	Methods       []GRPCMethod `json:"methods" yaml:"methods"`
+	Auth          *Auth        `json:"auth" yaml:"auth"`
+	RateLimit     *RateLimit   `json:"rateLimit" yaml:"rate_limit"`
+	Priority      int          `json:"priority" yaml:"priority"`
+	Tags          []string     `json:"tags" yaml:"tags"`
+
+	chaos  *Chaos
+	server *grpcServer
+}
+
+// services returns the sorted full names of all services with mock methods.
+func (m GRPCMock) services() []string {
+	services := []string{}
+	for _, method := range m.Methods {
+		if s := method.service(); s != "" && !containsString(services, s) {
+			services = append(services, s)
+		}
+	}
+
+	if m.Reflection {
+		services = append(services, grpcReflectionService)
+	}
+
+	sort.Strings(services)
+
+	return services
+}
+
+// readDescriptorSet reads and links the file descriptors of the mock.
+func (m *GRPCMock) readDescriptorSet() (*protoregistry.Files, error) {
+	if m.DescriptorSet == "" {
+		return nil, errors.New("descriptor_set is required")
+	}
+
+	b, err := ioutil.ReadFile(m.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor_set: %s", err)
+	}
+
+	set := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor_set: %s", err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor_set: %s", err)
+	}
+
+	return files, nil
+}
+
+// compile resolves the methods of the mock against its file descriptors and builds their messages.
+func (m *GRPCMock) compile() (*protoregistry.Files, map[protoreflect.FullName][]*grpcMethod, error) {
+	files, err := m.readDescriptorSet()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	methods := map[protoreflect.FullName][]*grpcMethod{}
+	for _, method := range m.Methods {
+		d, err := files.FindDescriptorByName(method.fullName())
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid method %s: no such method", method.Name)
+		}
+
+		md, ok := d.(protoreflect.MethodDescriptor)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid method %s: no such method", method.Name)
+		}
+
+		gm, err := newGRPCMethod(method, md)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid method %s: %s", method.Name, err)
+		}
+
+		methods[md.FullName()] = append(methods[md.FullName()], gm)
+	}
+
+	return files, methods, nil
+}
+
+// SetDefaults set default values for empty fields.
+func (m *GRPCMock) SetDefaults() {
+	for i := range m.Methods {
+		m.Methods[i].Name = strings.TrimPrefix(m.Methods[i].Name, "/")
+	}
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+func (m *GRPCMock) Inherit(c Config) {
+	if m.Auth == nil {
+		m.Auth = c.Auth
+	}
+
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *GRPCMock) Validate() error {
+	if len(m.Methods) == 0 {
+		return errors.New("at least one method is required")
+	}
+
+	for _, method := range m.Methods {
+		if method.service() == "" {
+			return fmt.Errorf("invalid method %s: expected package.Service/Method", method.Name)
+		}
+
+		if err := compilePatterns("match", method.Match, false); err != nil {
+			return fmt.Errorf("invalid method %s: %s", method.Name, err)
+		}
+	}
+
+	if _, _, err := m.compile(); err != nil {
+		return err
+	}
+
+	if err := m.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %s", err)
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	return nil
+}
+
+// String returns a string representation of the mock.
+func (m GRPCMock) String() string {
+	s := "gRPC "
+	if m.Host != "" {
+		s += m.Host + " "
+	}
+	s += strings.Join(m.services(), ", ")
+
+	return withListener(m.Listener, s)
+}
+
+// Hash calculates a hash for a grpc mock based on its location and services.
+func (m GRPCMock) Hash() uint64 {
+	h := fnv.New64a()
+
+	hashString(h, "grpc", m.Listener, m.Host)
+	hashString(h, m.services()...)
+
+	return h.Sum64()
+}
+
+// Rank returns the rank of the mock for ordering overlapping mocks.
+// The path of the service with the shortest name is used for its specificity.
+func (m GRPCMock) Rank() Rank {
+	path := ""
+	for i, s := range m.services() {
+		if i == 0 || len(s) < len(path)-2 {
+			path = "/" + s + "/"
+		}
+	}
+
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(false, path, 1),
+	}
+}
+
+// Diagnose scores a recorded request against the mock.
+// The request is scored against every service of the mock, and the best score is returned.
+// Mocks of other listeners are not considered at all.
+func (m GRPCMock) Diagnose(r Request) Diagnosis {
+	if m.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.Listener, r)
+	}
+
+	var best Diagnosis
+	for i, s := range m.services() {
+		e := HTTPExpect{
+			Host:    m.Host,
+			Methods: []string{"POST"},
+			Path:    "/" + s + "/",
+			Prefix:  true,
+			Headers: map[string]string{
+				"Content-Type": "^application/grpc",
+			},
+		}
+
+		if d := e.Diagnose(r); i == 0 || d.Score > best.Score {
+			best = d
+		}
+	}
+
+	best.Mock = m.String()
+
+	return best
+}
+
+// match adds the listener, host, and content type matchers of the mock to a route.
+func (m *GRPCMock) match(route *mux.Route) *mux.Route {
+	route.MatcherFunc(matchListener(m.Listener))
+
+	if m.Host != "" {
+		route.MatcherFunc(hostMatcher(m.Host))
+	}
+
+	return route.HeadersRegexp("Content-Type", "^application/grpc")
+}
+
+// wrap wraps a handler of the mock with chaos, rate limit, and auth.
+// gRPC clients do not send preflight requests, so cors is not applied.
+func (m *GRPCMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(nil, m.chaos, m.RateLimit, m.Auth, h)
+}
+
+// RegisterRoutes configure routes for a grpc mock.
+// gRPC calls are served over HTTP/2, so the mock server should accept HTTP/2 connections.
+func (m *GRPCMock) RegisterRoutes(router *mux.Router) {
+	var err error
+	if m.server == nil {
+		m.server, err = newGRPCServer(m)
+	}
+
+	handler := m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			writeGRPCStatus(w, status.New(codes.Internal, err.Error()))
+			return
+		}
+
+		m.server.ServeHTTP(w, r)
+	}))
+
+	for _, s := range m.services() {
+		m.match(router.Methods("POST").PathPrefix("/" + s + "/")).Handler(handler)
+	}
+}
+
+// writeGRPCStatus writes a gRPC response with no messages.
+func writeGRPCStatus(w http.ResponseWriter, s *status.Status) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(s.Code())))
+	w.Header().Set("Grpc-Message", s.Message())
+	w.WriteHeader(http.StatusOK)
+}
+
+// grpcMethod is a mock method resolved against its method descriptor.
+type grpcMethod struct {
+	GRPCMethod
+	desc     protoreflect.MethodDescriptor
+	delay    time.Duration
+	messages []proto.Message
+	err      error
+}
+
+func newGRPCMethod(m GRPCMethod, md protoreflect.MethodDescriptor) (*grpcMethod, error) {
+	delay, err := time.ParseDuration(m.Delay)
+	if m.Delay != "" && err != nil {
+		return nil, fmt.Errorf("invalid delay: %s", err)
+	}
+
+	if m.Status != nil {
+		if _, err := m.Status.code(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.Stream) > 0 {
+		if !md.IsStreamingServer() {
+			return nil, errors.New("stream is only allowed for server-streaming methods")
+		}
+		if m.Response != nil {
+			return nil, errors.New("only one of response or stream can be set")
+		}
+	}
+
+	for path := range m.Match {
+		if !grpcFieldExists(md.Input(), path) {
+			return nil, fmt.Errorf("invalid match: no field %s in %s", path, md.Input().FullName())
+		}
+	}
+
+	values := m.Stream
+	if m.Response != nil {
+		values = []interface{}{m.Response}
+	}
+
+	gm := &grpcMethod{
+		GRPCMethod: m,
+		desc:       md,
+		delay:      delay,
+		err:        m.Status.Err(),
+	}
+
+	for _, v := range values {
+		msg, err := grpcMessage(md.Output(), v)
+		if err != nil {
+			return nil, err
+		}
+		gm.messages = append(gm.messages, msg)
+	}
+
+	// A method with a single response either fails or responds with exactly one message.
+	if !md.IsStreamingServer() {
+		if gm.err != nil {
+			gm.messages = nil
+		} else if len(gm.messages) == 0 {
+			gm.messages = []proto.Message{dynamicpb.NewMessage(md.Output())}
+		}
+	}
+
+	return gm, nil
+}
+
+// grpcFieldExists determines whether or not a field path refers to a field of a message type.
+func grpcFieldExists(md protoreflect.MessageDescriptor, path string) bool {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if md == nil {
+			return false
+		}
+
+		fd := md.Fields().ByName(protoreflect.Name(segment))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(segment)
+		}
+		if fd == nil || (i < len(segments)-1 && (fd.IsList() || fd.IsMap())) {
+			return false
+		}
+
+		md = fd.Message()
+	}
+
+	return true
+}
+
+// grpcMessage creates a message of a message type from its JSON mapping.
+func grpcMessage(md protoreflect.MessageDescriptor, v interface{}) (proto.Message, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := protojson.Unmarshal(b, msg); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %s", md.FullName(), err)
+	}
+
+	return msg, nil
+}
+
+// respond sends the messages of the method, and then returns its status.
+func (m *grpcMethod) respond(stream grpc.ServerStream) error {
+	ctx := stream.Context()
+
+	wait := func() error {
+		if m.delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(m.delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	if len(m.messages) == 0 {
+		if err := wait(); err != nil {
+			return err
+		}
+	}
+
+	for _, msg := range m.messages {
+		if err := wait(); err != nil {
+			return err
+		}
+
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+
+	return m.err
+}
+
+// grpcServer serves the mock methods of a grpc mock.
+type grpcServer struct {
+	files   *protoregistry.Files
+	methods map[protoreflect.FullName][]*grpcMethod
+	server  *grpc.Server
+}
+
+func newGRPCServer(m *GRPCMock) (*grpcServer, error) {
+	files, methods, err := m.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &grpcServer{
+		files:   files,
+		methods: methods,
+		server:  grpc.NewServer(),
+	}
+
+	services := []string{}
+	for _, service := range m.services() {
+		if service == grpcReflectionService && m.Reflection {
+			continue
+		}
+
+		d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+		if err != nil {
+			return nil, fmt.Errorf("no such service %s", service)
+		}
+
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("no such service %s", service)
+		}
+
+		// All methods of a service are registered, so the ones with no mock fail with a proper status.
+		desc := &grpc.ServiceDesc{
+			ServiceName: service,
+			HandlerType: (*interface{})(nil),
+			Metadata:    sd.ParentFile().Path(),
+		}
+
+		for i := 0; i < sd.Methods().Len(); i++ {
+			md := sd.Methods().Get(i)
+			desc.Streams = append(desc.Streams, grpc.StreamDesc{
+				StreamName:    string(md.Name()),
+				Handler:       s.handler(md),
+				ServerStreams: md.IsStreamingServer(),
+				ClientStreams: md.IsStreamingClient(),
+			})
+		}
+
+		s.server.RegisterService(desc, nil)
+		services = append(services, service)
+	}
+
+	if m.Reflection {
+		// The reflection service can describe itself too.
+		_ = files.RegisterFile(reflectionpb.File_reflection_grpc_reflection_v1alpha_reflection_proto)
+
+		reflectionpb.RegisterServerReflectionServer(s.server, &grpcReflection{
+			files:    files,
+			services: append(services, grpcReflectionService),
+		})
+	}
+
+	return s, nil
+}
+
+// findGRPCMethod returns the first method matching any of the received messages.
+func findGRPCMethod(methods []*grpcMethod, msgs ...protoreflect.Message) *grpcMethod {
+	for _, m := range methods {
+		if len(m.Match) == 0 {
+			return m
+		}
+
+		for _, msg := range msgs {
+			if m.matches(msg) {
+				return m
+			}
+		}
+	}
+
+	return nil
+}
+
+// handler creates a stream handler for a method.
+// Every kind of method is served as a stream, and a method with a single request or response just receives or sends one message.
+func (s *grpcServer) handler(md protoreflect.MethodDescriptor) grpc.StreamHandler {
+	methods := s.methods[md.FullName()]
+
+	unmatched := func() error {
+		if len(methods) == 0 {
+			return status.Errorf(codes.Unimplemented, "no mock for method %s", md.FullName())
+		}
+		return status.Errorf(codes.Unimplemented, "no mock for method %s matches the request", md.FullName())
+	}
+
+	recv := func(stream grpc.ServerStream) (protoreflect.Message, error) {
+		msg := dynamicpb.NewMessage(md.Input())
+		if err := stream.RecvMsg(msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	return func(_ interface{}, stream grpc.ServerStream) error {
+		switch {
+		// A bidirectional-streaming method responds to every message, until the client closes the stream.
+		case md.IsStreamingClient() && md.IsStreamingServer():
+			for {
+				msg, err := recv(stream)
+				if err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+
+				m := findGRPCMethod(methods, msg)
+				if m == nil {
+					return unmatched()
+				}
+
+				if err := m.respond(stream); err != nil {
+					return err
+				}
+			}
+
+		// A client-streaming method responds once all messages are received.
+		case md.IsStreamingClient():
+			msgs := []protoreflect.Message{}
+			for {
+				msg, err := recv(stream)
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return err
+				}
+				msgs = append(msgs, msg)
+			}
+
+			m := findGRPCMethod(methods, msgs...)
+			if m == nil {
+				return unmatched()
+			}
+
+			return m.respond(stream)
+
+		default:
+			msg, err := recv(stream)
+			if err != nil {
+				return err
+			}
+
+			m := findGRPCMethod(methods, msg)
+			if m == nil {
+				return unmatched()
+			}
+
+			return m.respond(stream)
+		}
+	}
+}
+
+// ServeHTTP serves a gRPC call over HTTP/2.
+func (s *grpcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.ServeHTTP(w, r)
+}
+
+// grpcReflection implements the gRPC server reflection service using the file descriptors of a mock.
+type grpcReflection struct {
+	reflectionpb.UnimplementedServerReflectionServer
+	files    *protoregistry.Files
+	services []string
+}
+
+// ServerReflectionInfo answers reflection requests until the client closes the stream.
+func (r *grpcReflection) ServerReflectionInfo(stream reflectionpb.ServerReflection_ServerReflectionInfoServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		res := &reflectionpb.ServerReflectionResponse{
+			ValidHost:       req.Host,
+			OriginalRequest: req,
+		}
+
+		switch mr := req.MessageRequest.(type) {
+		case *reflectionpb.ServerReflectionRequest_ListServices:
+			list := &reflectionpb.ListServiceResponse{}
+			for _, name := range r.services {
+				list.Service = append(list.Service, &reflectionpb.ServiceResponse{Name: name})
+			}
+			res.MessageResponse = &reflectionpb.ServerReflectionResponse_ListServicesResponse{
+				ListServicesResponse: list,
+			}
+
+		case *reflectionpb.ServerReflectionRequest_FileByFilename:
+			fd, err := r.files.FindFileByPath(mr.FileByFilename)
+			r.fileResponse(res, fd, err)
+
+		case *reflectionpb.ServerReflectionRequest_FileContainingSymbol:
+			var fd protoreflect.FileDescriptor
+			d, err := r.files.FindDescriptorByName(protoreflect.FullName(mr.FileContainingSymbol))
+			if err == nil {
+				fd = d.ParentFile()
+			}
+			r.fileResponse(res, fd, err)
+
+		case *reflectionpb.ServerReflectionRequest_FileContainingExtension:
+			var fd protoreflect.FileDescriptor
+			err := fmt.Errorf("extension %d of %s not found", mr.FileContainingExtension.ExtensionNumber, mr.FileContainingExtension.ContainingType)
+			for _, xd := range r.extensions(mr.FileContainingExtension.ContainingType) {
+				if int32(xd.Number()) == mr.FileContainingExtension.ExtensionNumber {
+					fd, err = xd.ParentFile(), nil
+					break
+				}
+			}
+			r.fileResponse(res, fd, err)
+
+		case *reflectionpb.ServerReflectionRequest_AllExtensionNumbersOfType:
+			name := mr.AllExtensionNumbersOfType
+			if _, err := r.files.FindDescriptorByName(protoreflect.FullName(name)); err != nil {
+				reflectionError(res, codes.NotFound, err)
+				break
+			}
+
+			numbers := &reflectionpb.ExtensionNumberResponse{BaseTypeName: name}
+			for _, xd := range r.extensions(name) {
+				numbers.ExtensionNumber = append(numbers.ExtensionNumber, int32(xd.Number()))
+			}
+			res.MessageResponse = &reflectionpb.ServerReflectionResponse_AllExtensionNumbersResponse{
+				AllExtensionNumbersResponse: numbers,
+			}
+
+		default:
+			return status.Errorf(codes.InvalidArgument, "invalid MessageRequest: %v", req.MessageRequest)
+		}
+
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+}
+
+// fileResponse responds with a file descriptor followed by all of its transitive dependencies.
+func (r *grpcReflection) fileResponse(res *reflectionpb.ServerReflectionResponse, fd protoreflect.FileDescriptor, err error) {
+	if err != nil {
+		reflectionError(res, codes.NotFound, err)
+		return
+	}
+
+	seen := map[string]bool{}
+	files := [][]byte{}
+
+	var add func(protoreflect.FileDescriptor) error
+	add = func(fd protoreflect.FileDescriptor) error {
+		if seen[fd.Path()] {
+			return nil
+		}
+		seen[fd.Path()] = true
+
+		b, err := proto.Marshal(protodesc.ToFileDescriptorProto(fd))
+		if err != nil {
+			return err
+		}
+		files = append(files, b)
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			if err := add(imports.Get(i).FileDescriptor); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := add(fd); err != nil {
+		reflectionError(res, codes.Internal, err)
+		return
+	}
+
+	res.MessageResponse = &reflectionpb.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &reflectionpb.FileDescriptorResponse{
+			FileDescriptorProto: files,
+		},
+	}
+}
+
+// extensions returns all extensions of a message type.
+func (r *grpcReflection) extensions(name string) []protoreflect.ExtensionDescriptor {
+	xds := []protoreflect.ExtensionDescriptor{}
+
+	var walk func(protoreflect.ExtensionDescriptors, protoreflect.MessageDescriptors)
+	walk = func(xs protoreflect.ExtensionDescriptors, ms protoreflect.MessageDescriptors) {
+		for i := 0; i < xs.Len(); i++ {
+			if xd := xs.Get(i); string(xd.ContainingMessage().FullName()) == name {
+				xds = append(xds, xd)
+			}
+		}
+		for i := 0; i < ms.Len(); i++ {
+			walk(ms.Get(i).Extensions(), ms.Get(i).Messages())
+		}
+	}
+
+	r.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		walk(fd.Extensions(), fd.Messages())
+		return true
+	})
+
+	return xds
+}
+
+// reflectionError responds to a reflection request with an error.
+func reflectionError(res *reflectionpb.ServerReflectionResponse, code codes.Code, err error) {
+	res.MessageResponse = &reflectionpb.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &reflectionpb.ErrorResponse{
+			ErrorCode:    int32(code),
+			ErrorMessage: err.Error(),
+		},
+	}
+}
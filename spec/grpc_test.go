@@ -0,0 +1,519 @@
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGRPCStatusErr(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          *GRPCStatus
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{"Nil", nil, codes.OK, ""},
+		{"Name", &GRPCStatus{Code: "NOT_FOUND", Message: "not found"}, codes.NotFound, "not found"},
+		{"LowerCase", &GRPCStatus{Code: "unavailable"}, codes.Unavailable, ""},
+		{"Number", &GRPCStatus{Code: "7"}, codes.PermissionDenied, ""},
+		{"Invalid", &GRPCStatus{Code: "BROKEN"}, codes.Internal, "invalid status code BROKEN"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := status.Convert(tc.status.Err())
+
+			assert.Equal(t, tc.expectedCode, s.Code())
+			assert.Equal(t, tc.expectedMessage, s.Message())
+		})
+	}
+}
+
+func TestGRPCMockValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		mock          GRPCMock
+		expectedError string
+	}{
+		{
+			name:          "NoMethods",
+			mock:          GRPCMock{DescriptorSet: "test/greeter.pb"},
+			expectedError: "at least one method is required",
+		},
+		{
+			name: "InvalidMethodName",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "SayHello"}},
+			},
+			expectedError: "invalid method SayHello: expected package.Service/Method",
+		},
+		{
+			name: "InvalidMatchPattern",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Match: map[string]string{"name": "("}}},
+			},
+			expectedError: "invalid method greeter.v1.Greeter/SayHello: invalid match name: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "NoDescriptorSet",
+			mock: GRPCMock{
+				Methods: []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello"}},
+			},
+			expectedError: "descriptor_set is required",
+		},
+		{
+			name: "DescriptorSetNotFound",
+			mock: GRPCMock{
+				DescriptorSet: "test/missing.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello"}},
+			},
+			expectedError: "invalid descriptor_set: open test/missing.pb: no such file or directory",
+		},
+		{
+			name: "UnknownMethod",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayBye"}},
+			},
+			expectedError: "invalid method greeter.v1.Greeter/SayBye: no such method",
+		},
+		{
+			name: "InvalidDelay",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Delay: "soon"}},
+			},
+			expectedError: `invalid method greeter.v1.Greeter/SayHello: invalid delay: time: invalid duration "soon"`,
+		},
+		{
+			name: "InvalidStatusCode",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Status: &GRPCStatus{Code: "BROKEN"}}},
+			},
+			expectedError: "invalid method greeter.v1.Greeter/SayHello: invalid status code BROKEN",
+		},
+		{
+			name: "StreamForUnaryMethod",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Stream: []interface{}{JSON{}}}},
+			},
+			expectedError: "invalid method greeter.v1.Greeter/SayHello: stream is only allowed for server-streaming methods",
+		},
+		{
+			name: "UnknownMatchField",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Match: map[string]string{"address.zip": "."}}},
+			},
+			expectedError: "invalid method greeter.v1.Greeter/SayHello: invalid match: no field address.zip in greeter.v1.HelloRequest",
+		},
+		{
+			name: "InvalidResponse",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods:       []GRPCMethod{{Name: "greeter.v1.Greeter/SayHello", Response: JSON{"greeting": "Hi"}}},
+			},
+			expectedError: `invalid method greeter.v1.Greeter/SayHello: invalid greeter.v1.HelloReply message: proto: (line 1:2): unknown field "greeting"`,
+		},
+		{
+			name: "Valid",
+			mock: GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Methods: []GRPCMethod{
+					{Name: "greeter.v1.Greeter/SayHello", Match: map[string]string{"address.city": "^Paris$"}, Response: JSON{"message": "Bonjour"}},
+					{Name: "greeter.v1.Greeter/SayHellos", Stream: []interface{}{JSON{"message": "Hi"}}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mock.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				// protojson randomly uses non-breaking spaces in its errors to keep them unstable.
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, strings.Replace(err.Error(), "\u00a0", " ", -1))
+			}
+		})
+	}
+}
+
+func TestGRPCMockString(t *testing.T) {
+	m := GRPCMock{
+		Methods: []GRPCMethod{
+			{Name: "greeter.v1.Greeter/SayHello"},
+			{Name: "greeter.v1.Greeter/SayHellos"},
+		},
+	}
+	assert.Equal(t, "gRPC greeter.v1.Greeter", m.String())
+
+	m.Host = "api.example.com"
+	m.Reflection = true
+	assert.Equal(t, "gRPC api.example.com greeter.v1.Greeter, grpc.reflection.v1alpha.ServerReflection", m.String())
+
+	m.Listener = "admin"
+	assert.Equal(t, "admin: gRPC api.example.com greeter.v1.Greeter, grpc.reflection.v1alpha.ServerReflection", m.String())
+}
+
+func TestGRPCMockInherit(t *testing.T) {
+	auth := &Auth{Bearer: []string{"secret"}}
+	rateLimit := &RateLimit{Limit: 10, Window: "1m"}
+	chaos := &Chaos{Tags: []string{"flaky"}}
+	config := Config{Auth: auth, RateLimit: rateLimit, Chaos: chaos}
+
+	tests := []struct {
+		name         string
+		mock         GRPCMock
+		config       Config
+		expectedMock GRPCMock
+	}{
+		{
+			name:         "NoConfig",
+			mock:         GRPCMock{},
+			config:       Config{},
+			expectedMock: GRPCMock{},
+		},
+		{
+			name:         "Inherited",
+			mock:         GRPCMock{},
+			config:       config,
+			expectedMock: GRPCMock{Auth: auth, RateLimit: rateLimit},
+		},
+		{
+			name:         "Overridden",
+			mock:         GRPCMock{Auth: &Auth{Bearer: []string{"token"}}},
+			config:       config,
+			expectedMock: GRPCMock{Auth: &Auth{Bearer: []string{"token"}}, RateLimit: rateLimit},
+		},
+		{
+			name:         "Chaos",
+			mock:         GRPCMock{Tags: []string{"flaky"}},
+			config:       config,
+			expectedMock: GRPCMock{Auth: auth, RateLimit: rateLimit, Tags: []string{"flaky"}, chaos: chaos},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock.Inherit(tc.config)
+			assert.Equal(t, tc.expectedMock, tc.mock)
+		})
+	}
+}
+
+// grpcTestClient calls the methods of the greeter service using dynamic messages.
+type grpcTestClient struct {
+	conn    *grpc.ClientConn
+	service protoreflect.ServiceDescriptor
+}
+
+func newGRPCTestClient(t *testing.T, m *GRPCMock) (*grpcTestClient, func()) {
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+
+	ts := httptest.NewServer(h2c.NewHandler(router, &http2.Server{}))
+
+	conn, err := grpc.Dial(strings.TrimPrefix(ts.URL, "http://"), grpc.WithInsecure())
+	assert.NoError(t, err)
+
+	d, err := m.server.files.FindDescriptorByName("greeter.v1.Greeter")
+	assert.NoError(t, err)
+
+	return &grpcTestClient{conn, d.(protoreflect.ServiceDescriptor)}, func() {
+		conn.Close()
+		ts.Close()
+	}
+}
+
+func (c *grpcTestClient) message(t *testing.T, method, in string) *dynamicpb.Message {
+	md := c.service.Methods().ByName(protoreflect.Name(method))
+	msg := dynamicpb.NewMessage(md.Input())
+	assert.NoError(t, protojson.Unmarshal([]byte(in), msg))
+	return msg
+}
+
+// call sends the request messages and returns the JSON mapping of the response messages.
+func (c *grpcTestClient) call(t *testing.T, method string, in ...string) ([]string, error) {
+	md := c.service.Methods().ByName(protoreflect.Name(method))
+	desc := &grpc.StreamDesc{
+		ServerStreams: md.IsStreamingServer(),
+		ClientStreams: md.IsStreamingClient(),
+	}
+
+	stream, err := c.conn.NewStream(context.Background(), desc, "/greeter.v1.Greeter/"+method)
+	assert.NoError(t, err)
+
+	for _, s := range in {
+		assert.NoError(t, stream.SendMsg(c.message(t, method, s)))
+	}
+	assert.NoError(t, stream.CloseSend())
+
+	out := []string{}
+	for {
+		msg := dynamicpb.NewMessage(md.Output())
+		if err := stream.RecvMsg(msg); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return out, err
+		}
+
+		// protojson output is unstable, so it is normalized.
+		b, err := protojson.Marshal(msg)
+		assert.NoError(t, err)
+		var v interface{}
+		assert.NoError(t, json.Unmarshal(b, &v))
+		b, err = json.Marshal(v)
+		assert.NoError(t, err)
+		out = append(out, string(b))
+	}
+}
+
+func TestGRPCMockServe(t *testing.T) {
+	m := &GRPCMock{
+		DescriptorSet: "test/greeter.pb",
+		Methods: []GRPCMethod{
+			{
+				Name:   "greeter.v1.Greeter/SayHello",
+				Match:  map[string]string{"name": "^$"},
+				Status: &GRPCStatus{Code: "INVALID_ARGUMENT", Message: "name is required"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/SayHello",
+				Match:    map[string]string{"mood": "^GRUMPY$", "address.city": "Paris"},
+				Response: JSON{"message": "Bonjour?"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/SayHello",
+				Match:    map[string]string{"tags": "^vip$"},
+				Response: JSON{"message": "Welcome back!"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/SayHello",
+				Response: JSON{"message": "Hello!"},
+			},
+			{
+				Name:  "greeter.v1.Greeter/SayHellos",
+				Delay: "1ms",
+				Stream: []interface{}{
+					JSON{"message": "Hello", "count": 1},
+					JSON{"message": "Hello again", "count": 2},
+				},
+				Status: &GRPCStatus{Code: "RESOURCE_EXHAUSTED", Message: "out of hellos"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/CollectHellos",
+				Match:    map[string]string{"name": "^Jane$"},
+				Response: JSON{"message": "Hello, Jane and friends!"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/Chat",
+				Match:    map[string]string{"name": "^Jane$"},
+				Response: JSON{"message": "Hi, Jane!"},
+			},
+			{
+				Name:     "greeter.v1.Greeter/Chat",
+				Match:    map[string]string{"name": "^John$"},
+				Response: JSON{"message": "Hi, John!"},
+			},
+		},
+	}
+
+	m.SetDefaults()
+	assert.NoError(t, m.Validate())
+
+	client, close := newGRPCTestClient(t, m)
+	defer close()
+
+	tests := []struct {
+		name            string
+		method          string
+		in              []string
+		expectedOut     []string
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			name:            "Status",
+			method:          "SayHello",
+			in:              []string{`{}`},
+			expectedOut:     []string{},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "name is required",
+		},
+		{
+			name:         "MatchNestedAndEnumFields",
+			method:       "SayHello",
+			in:           []string{`{"name":"Jean","mood":"GRUMPY","address":{"city":"Paris"}}`},
+			expectedOut:  []string{`{"message":"Bonjour?"}`},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "MatchRepeatedField",
+			method:       "SayHello",
+			in:           []string{`{"name":"Jane","tags":["new","vip"]}`},
+			expectedOut:  []string{`{"message":"Welcome back!"}`},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Unary",
+			method:       "SayHello",
+			in:           []string{`{"name":"Jane","mood":"HAPPY"}`},
+			expectedOut:  []string{`{"message":"Hello!"}`},
+			expectedCode: codes.OK,
+		},
+		{
+			name:            "ServerStreaming",
+			method:          "SayHellos",
+			in:              []string{`{"name":"Jane"}`},
+			expectedOut:     []string{`{"count":1,"message":"Hello"}`, `{"count":2,"message":"Hello again"}`},
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "out of hellos",
+		},
+		{
+			name:         "ClientStreaming",
+			method:       "CollectHellos",
+			in:           []string{`{"name":"John"}`, `{"name":"Jane"}`},
+			expectedOut:  []string{`{"message":"Hello, Jane and friends!"}`},
+			expectedCode: codes.OK,
+		},
+		{
+			name:            "ClientStreamingUnmatched",
+			method:          "CollectHellos",
+			in:              []string{`{"name":"John"}`},
+			expectedOut:     []string{},
+			expectedCode:    codes.Unimplemented,
+			expectedMessage: "no mock for method greeter.v1.Greeter.CollectHellos matches the request",
+		},
+		{
+			name:         "BidirectionalStreaming",
+			method:       "Chat",
+			in:           []string{`{"name":"Jane"}`, `{"name":"John"}`},
+			expectedOut:  []string{`{"message":"Hi, Jane!"}`, `{"message":"Hi, John!"}`},
+			expectedCode: codes.OK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := client.call(t, tc.method, tc.in...)
+			s := status.Convert(err)
+
+			assert.Equal(t, tc.expectedOut, out)
+			assert.Equal(t, tc.expectedCode, s.Code())
+			assert.Equal(t, tc.expectedMessage, s.Message())
+		})
+	}
+}
+
+func TestGRPCMockAuth(t *testing.T) {
+	m := &GRPCMock{
+		DescriptorSet: "test/greeter.pb",
+		Methods: []GRPCMethod{
+			{
+				Name:     "greeter.v1.Greeter/SayHello",
+				Response: JSON{"message": "Hello!"},
+			},
+		},
+	}
+
+	m.SetDefaults()
+	m.Inherit(Config{Auth: &Auth{Bearer: []string{"secret"}}})
+	assert.NoError(t, m.Validate())
+
+	client, close := newGRPCTestClient(t, m)
+	defer close()
+
+	out, err := client.call(t, "SayHello", `{"name":"Jane"}`)
+	assert.Empty(t, out)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPCMockReflection(t *testing.T) {
+	m := &GRPCMock{
+		DescriptorSet: "test/greeter.pb",
+		Reflection:    true,
+		Methods: []GRPCMethod{
+			{Name: "greeter.v1.Greeter/SayHello"},
+		},
+	}
+
+	client, close := newGRPCTestClient(t, m)
+	defer close()
+
+	stream, err := reflectionpb.NewServerReflectionClient(client.conn).ServerReflectionInfo(context.Background())
+	assert.NoError(t, err)
+	defer stream.CloseSend()
+
+	send := func(req *reflectionpb.ServerReflectionRequest) *reflectionpb.ServerReflectionResponse {
+		assert.NoError(t, stream.Send(req))
+		res, err := stream.Recv()
+		assert.NoError(t, err)
+		return res
+	}
+
+	res := send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	services := []string{}
+	for _, s := range res.GetListServicesResponse().Service {
+		services = append(services, s.Name)
+	}
+	assert.Equal(t, []string{"greeter.v1.Greeter", "grpc.reflection.v1alpha.ServerReflection"}, services)
+
+	res = send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "greeter.v1.Greeter.SayHello",
+		},
+	})
+	assert.Len(t, res.GetFileDescriptorResponse().FileDescriptorProto, 1)
+
+	res = send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{
+			FileByFilename: "greeter.proto",
+		},
+	})
+	assert.Len(t, res.GetFileDescriptorResponse().FileDescriptorProto, 1)
+
+	res = send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "greeter.v1.Farewell",
+		},
+	})
+	assert.Equal(t, int32(codes.NotFound), res.GetErrorResponse().ErrorCode)
+
+	res = send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_AllExtensionNumbersOfType{
+			AllExtensionNumbersOfType: "greeter.v1.HelloRequest",
+		},
+	})
+	assert.Equal(t, "greeter.v1.HelloRequest", res.GetAllExtensionNumbersResponse().BaseTypeName)
+	assert.Empty(t, res.GetAllExtensionNumbersResponse().ExtensionNumber)
+
+	// Methods with no mock are unimplemented.
+	_, err = client.call(t, "SayHellos", `{}`)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -1,11 +1,28 @@
 package spec
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
+	"io/ioutil"
+	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+)
+
+// contextKey is the type of context keys set by this package.
+// All keys are declared in a single block, so their values are unique.
+type contextKey int
+
+const (
+	proxyErrorHandlerKey contextKey = iota
+	listenerKey
+	recorderKey
+	deliveryRecorderKey
+	graphqlDataKey
 )
 
 // JSON is the type for json objects.
@@ -30,6 +47,47 @@ func findID(key string, obj JSON) (interface{}, error) {
 	return nil, errors.New("cannot find an identifier")
 }
 
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// readBody reads the body of a request and replaces it, so it can be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// hasHeader determines whether or not a header is set in a map of headers regardless of its case.
+func hasHeader(headers map[string]string, key string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold determines whether or not a slice of strings contains a string regardless of its case.
+func containsFold(s []string, str string) bool {
+	for _, v := range s {
+		if strings.EqualFold(v, str) {
+			return true
+		}
+	}
+	return false
+}
+
 // Pair is a key-value pair
 type Pair struct {
 	Key   string `json:"key" yaml:"key"`
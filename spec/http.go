@@ -0,0 +1,518 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// HTTPExpect represents an http expectation.
+// Cookies are regular expressions for cookie values by name, and NoCookies are the names of cookies that must be absent.
+// Form are regular expressions for the fields of url-encoded or multipart forms,
+// and Files are expectations for the files of multipart forms by field name.
+// XPath are regular expressions for the values selected by xpath expressions in xml request bodies.
+type HTTPExpect struct {
+	Listener  string              `json:"listener" yaml:"listener"`
+	Host      string              `json:"host" yaml:"host"`
+	Methods   []string            `json:"methods" yaml:"methods"`
+	Path      string              `json:"path" yaml:"path"`
+	Prefix    bool                `json:"prefix" yaml:"prefix"`
+	Queries   map[string]string   `json:"queries" yaml:"queries"`
+	Headers   map[string]string   `json:"headers" yaml:"headers"`
+	Cookies   map[string]string   `json:"cookies" yaml:"cookies"`
+	NoCookies []string            `json:"noCookies" yaml:"no_cookies"`
+	Form      map[string]string   `json:"form" yaml:"form"`
+	Files     map[string]HTTPFile `json:"files" yaml:"files"`
+	XPath     map[string]string   `json:"xpath" yaml:"xpath"`
+	SOAP      *SOAPExpect         `json:"soap" yaml:"soap"`
+}
+
+// HTTPResponse represents a mock http response.
+// Only one of Body, BodyFile, BodyText, BodyBase64, BodyXML, Content, SOAP, or SSE can be set.
+// If Content is set, the response body is chosen based on the Accept header of the request.
+// If SOAP is set, the response body is a SOAP envelope.
+// If SSE is set, the response body is a stream of server-sent events.
+type HTTPResponse struct {
+	Delay      string            `json:"delay" yaml:"delay"`
+	StatusCode int               `json:"status" yaml:"status"`
+	Headers    map[string]string `json:"headers" yaml:"headers"`
+	Body       interface{}       `json:"body" yaml:"body"`
+	BodyFile   string            `json:"bodyFile" yaml:"body_file"`
+	BodyText   string            `json:"bodyText" yaml:"body_text"`
+	BodyBase64 string            `json:"bodyBase64" yaml:"body_base64"`
+	BodyXML    string            `json:"bodyXML" yaml:"body_xml"`
+	Content    []HTTPContent     `json:"content" yaml:"content"`
+	SOAP       *SOAPResponse     `json:"soap" yaml:"soap"`
+	SSE        *SSEStream        `json:"sse" yaml:"sse"`
+	Cookies    []HTTPCookie      `json:"cookies" yaml:"cookies"`
+}
+
+// body returns the body of the response as an HTTPContent.
+func (r *HTTPResponse) body() HTTPContent {
+	return HTTPContent{
+		Body:       r.Body,
+		BodyFile:   r.BodyFile,
+		BodyText:   r.BodyText,
+		BodyBase64: r.BodyBase64,
+		BodyXML:    r.BodyXML,
+	}
+}
+
+// Validate checks whether or not the response is valid.
+func (r *HTTPResponse) Validate() error {
+	for _, c := range r.Cookies {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+
+	body := r.body()
+
+	if r.SSE != nil {
+		if !body.empty() || len(r.Content) > 0 || r.SOAP != nil {
+			return errors.New("sse cannot be set along with body, body_file, body_text, body_base64, body_xml, content, or soap")
+		}
+
+		return r.SSE.Validate()
+	}
+
+	if r.SOAP != nil {
+		if !body.empty() || len(r.Content) > 0 {
+			return errors.New("soap cannot be set along with body, body_file, body_text, body_base64, body_xml, or content")
+		}
+
+		return r.SOAP.Validate()
+	}
+
+	if len(r.Content) > 0 {
+		if !body.empty() {
+			return errors.New("content cannot be set along with body, body_file, body_text, body_base64, or body_xml")
+		}
+
+		for _, c := range r.Content {
+			if c.Type == "" {
+				return errors.New("content type is required")
+			}
+			if err := c.Validate(); err != nil {
+				return fmt.Errorf("invalid content %s: %s", c.Type, err)
+			}
+		}
+
+		return nil
+	}
+
+	return body.Validate()
+}
+
+// Write writes the response for a request.
+// Headers are set first, then the status code is written, and then the body is written.
+func (r *HTTPResponse) Write(w http.ResponseWriter, req *http.Request) {
+	if r.SSE != nil {
+		r.writeHeaders(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(r.StatusCode)
+		r.SSE.write(w, req)
+		return
+	}
+
+	content := r.body()
+
+	if r.SOAP != nil {
+		content = HTTPContent{
+			Type:     r.SOAP.contentType(),
+			BodyText: r.SOAP.envelope(),
+		}
+	} else if len(r.Content) > 0 {
+		types := make([]string, len(r.Content))
+		for i, c := range r.Content {
+			types[i] = c.Type
+		}
+
+		w.Header().Add("Vary", "Accept")
+
+		i := negotiate(req.Header.Get("Accept"), types)
+		if i < 0 {
+			writeJSON(w, http.StatusNotAcceptable, JSON{
+				"message": "none of the available content types is acceptable",
+				"types":   types,
+			})
+			return
+		}
+
+		content = r.Content[i]
+	}
+
+	body, contentType, err := content.read()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, JSON{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	r.writeHeaders(w)
+
+	if content.Type != "" {
+		w.Header().Set("Content-Type", content.Type)
+	} else if contentType != "" && !hasHeader(r.Headers, "Content-Type") {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.WriteHeader(r.StatusCode)
+	_, _ = w.Write(body)
+}
+
+// writeHeaders sets the headers and the cookies of the response.
+func (r *HTTPResponse) writeHeaders(w http.ResponseWriter) {
+	for key, val := range r.Headers {
+		w.Header().Set(key, val)
+	}
+
+	now := time.Now()
+	for _, c := range r.Cookies {
+		http.SetCookie(w, c.cookie(now))
+	}
+}
+
+// HTTPForward represents a forwarder for an http request.
+type HTTPForward struct {
+	Delay   string            `json:"delay" yaml:"delay"`
+	To      string            `json:"to" yaml:"to"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+}
+
+// HTTPMock represents an http mock.
+type HTTPMock struct {
+	HTTPExpect    `json:",inline" yaml:",inline"`
+	*HTTPResponse `json:"response" yaml:"response"`
+	*HTTPForward  `json:"forward" yaml:"forward"`
+	Callbacks     []HTTPCallback `json:"callbacks" yaml:"callbacks"`
+	CORS          *CORS          `json:"cors" yaml:"cors"`
+	Auth          *Auth          `json:"auth" yaml:"auth"`
+	RateLimit     *RateLimit     `json:"rateLimit" yaml:"rate_limit"`
+	Priority      int            `json:"priority" yaml:"priority"`
+	Tags          []string       `json:"tags" yaml:"tags"`
+
+	chaos *Chaos
+}
+
+// SetDefaults set default values for empty fields.
+func (m *HTTPMock) SetDefaults() {
+	if len(m.HTTPExpect.Methods) == 0 {
+		m.HTTPExpect.Methods = []string{"GET"}
+	}
+
+	m.HTTPExpect.Path = path.Clean("/" + m.HTTPExpect.Path)
+
+	if m.HTTPResponse == nil && m.HTTPForward == nil {
+		m.HTTPResponse = &HTTPResponse{}
+	}
+
+	if m.HTTPResponse != nil {
+		if soap := m.HTTPResponse.SOAP; soap != nil {
+			soap.SetDefaults()
+
+			// SOAP faults are responded with 500 by default.
+			if soap.Fault != nil && m.HTTPResponse.StatusCode == 0 {
+				m.HTTPResponse.StatusCode = 500
+			}
+		}
+
+		if m.HTTPResponse.StatusCode == 0 {
+			m.HTTPResponse.StatusCode = 200
+		}
+	}
+
+	if m.HTTPForward != nil {
+		// No default
+	}
+
+	for i := range m.Callbacks {
+		m.Callbacks[i].SetDefaults()
+	}
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+func (m *HTTPMock) Inherit(c Config) {
+	if m.CORS == nil {
+		m.CORS = c.CORS
+	}
+
+	if m.Auth == nil {
+		m.Auth = c.Auth
+	}
+
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// Validate checks whether or not the regular expressions and the xpath expressions of the expectation are valid.
+func (e HTTPExpect) Validate() error {
+	if err := compilePatterns("query", e.Queries, true); err != nil {
+		return err
+	}
+
+	if err := compilePatterns("header", e.Headers, false); err != nil {
+		return err
+	}
+
+	if err := compilePatterns("cookie", e.Cookies, false); err != nil {
+		return err
+	}
+
+	if err := compilePatterns("form field", e.Form, false); err != nil {
+		return err
+	}
+
+	for _, field := range sortedFileKeys(e.Files) {
+		if err := e.Files[field].Validate(); err != nil {
+			return fmt.Errorf("invalid file %s: %s", field, err)
+		}
+	}
+
+	for _, expr := range sortedKeys(e.XPath) {
+		if _, err := compileXPath(expr); err != nil {
+			return fmt.Errorf("invalid xpath %s: %s", expr, err)
+		}
+	}
+
+	return compilePatterns("xpath", e.XPath, false)
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *HTTPMock) Validate() error {
+	if err := m.HTTPExpect.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %s", err)
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	for i := range m.Callbacks {
+		if err := m.Callbacks[i].Validate(); err != nil {
+			return fmt.Errorf("invalid callback %d: %s", i+1, err)
+		}
+	}
+
+	if m.HTTPResponse != nil {
+		return m.HTTPResponse.Validate()
+	}
+
+	if m.HTTPForward != nil {
+		return m.HTTPForward.Validate()
+	}
+
+	return nil
+}
+
+// String returns a string representation of the mock.
+// SOAP mocks are distinguished by their operations or actions since they usually share the same path.
+func (m HTTPMock) String() string {
+	s := fmt.Sprintf(
+		"%s %s%s",
+		strings.Join(m.HTTPExpect.Methods, "|"),
+		m.HTTPExpect.Host,
+		m.HTTPExpect.Path,
+	)
+
+	if m.HTTPExpect.SOAP != nil {
+		s += fmt.Sprintf(" [%s]", m.HTTPExpect.SOAP)
+	}
+
+	return withListener(m.HTTPExpect.Listener, s)
+}
+
+// Hash calculates a hash for an http mock based on the http expectation.
+func (m HTTPMock) Hash() uint64 {
+	h := fnv.New64a()
+	m.HTTPExpect.hash(h)
+
+	return h.Sum64()
+}
+
+// hash writes all criteria of the expectation to a hash.
+func (e HTTPExpect) hash(h hash.Hash) {
+	hashString(h, e.Listener, e.Host)
+	hashStringSlice(h, true, e.Methods)
+	hashString(h, e.Path)
+	hashBool(h, e.Prefix)
+	hashStringMap(h, true, e.Queries)
+	hashStringMap(h, true, e.Headers)
+	hashStringMap(h, true, e.Cookies)
+	hashStringSlice(h, true, e.NoCookies)
+	hashStringMap(h, true, e.Form)
+	hashFiles(h, e.Files)
+	hashStringMap(h, true, e.XPath)
+
+	if soap := e.SOAP; soap != nil {
+		hashString(h, "soap", soap.Action, soap.Operation)
+	}
+}
+
+// Rank returns the rank of the mock for ordering overlapping mocks.
+func (m HTTPMock) Rank() Rank {
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(!m.HTTPExpect.Prefix, m.HTTPExpect.Path, m.HTTPExpect.criteria()),
+	}
+}
+
+// criteria returns the number of criteria of the expectation other than its path.
+// Methods count as a single criterion regardless of their number, since more methods match more requests.
+func (e HTTPExpect) criteria() int {
+	criteria := len(e.Queries) + len(e.Headers) +
+		len(e.Cookies) + len(e.NoCookies) + len(e.Form) + len(e.Files) +
+		len(e.XPath)
+
+	if len(e.Methods) > 0 {
+		criteria++
+	}
+
+	if soap := e.SOAP; soap != nil {
+		if soap.Action != "" {
+			criteria++
+		}
+		if soap.Operation != "" {
+			criteria++
+		}
+	}
+
+	return criteria
+}
+
+// Diagnose scores a recorded request against the mock.
+// Mocks of other listeners are not considered at all.
+func (m HTTPMock) Diagnose(r Request) Diagnosis {
+	if m.HTTPExpect.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.HTTPExpect.Listener, r)
+	}
+
+	d := m.HTTPExpect.Diagnose(r)
+	d.Mock = m.String()
+
+	return d
+}
+
+// newHandler creates an http handler for either writing a response or forwarding a request.
+// It returns nil if neither a response nor a forwarder is set.
+func newHandler(response *HTTPResponse, forward *HTTPForward) http.Handler {
+	if response != nil {
+		delay, _ := time.ParseDuration(response.Delay)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			response.Write(w, r)
+		})
+	}
+
+	if forward != nil {
+		delay, _ := time.ParseDuration(forward.Delay)
+		proxy := forward.proxy()
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			proxy.ServeHTTP(w, r)
+		})
+	}
+
+	return nil
+}
+
+// wrap wraps a handler of the mock with cors, chaos, rate limit, and auth.
+func (m HTTPMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(m.CORS, m.chaos, m.RateLimit, m.Auth, h)
+}
+
+// RegisterRoutes configure routes for an http mock.
+func (m HTTPMock) RegisterRoutes(router *mux.Router) {
+	route := m.HTTPExpect.route(router.NewRoute())
+
+	if handler := newHandler(m.HTTPResponse, m.HTTPForward); handler != nil {
+		handler = withCallbacks(m.String(), m.Callbacks, handler)
+		route.Handler(m.wrap(handler))
+	}
+
+	// Preflight requests do not carry the headers of actual requests.
+	if preflight := m.CORS.RegisterPreflight(router, m.HTTPExpect.Methods); preflight != nil {
+		preflight.MatcherFunc(matchListener(m.HTTPExpect.Listener))
+
+		if m.HTTPExpect.Host != "" {
+			preflight.MatcherFunc(hostMatcher(m.HTTPExpect.Host))
+		}
+
+		if m.HTTPExpect.Prefix {
+			preflight.PathPrefix(m.HTTPExpect.Path)
+		} else {
+			preflight.Path(m.HTTPExpect.Path)
+		}
+
+		for query, pattern := range m.HTTPExpect.Queries {
+			preflight.Queries(query, fmt.Sprintf("{%s:%s}", query, pattern))
+		}
+	}
+}
+
+// route adds the matchers for all criteria of the expectation to a route.
+func (e HTTPExpect) route(route *mux.Route) *mux.Route {
+	route.MatcherFunc(matchListener(e.Listener))
+
+	if e.Host != "" {
+		route.MatcherFunc(hostMatcher(e.Host))
+	}
+
+	route.Methods(e.Methods...)
+
+	if e.Prefix {
+		route.PathPrefix(e.Path)
+	} else {
+		route.Path(e.Path)
+	}
+
+	for query, pattern := range e.Queries {
+		route.Queries(query, fmt.Sprintf("{%s:%s}", query, pattern))
+	}
+
+	for header, pattern := range e.Headers {
+		route.HeadersRegexp(header, pattern)
+	}
+
+	for name, pattern := range e.Cookies {
+		route.MatcherFunc(cookieMatcher(name, pattern))
+	}
+
+	for _, name := range e.NoCookies {
+		route.MatcherFunc(noCookieMatcher(name))
+	}
+
+	if len(e.Form) > 0 || len(e.Files) > 0 {
+		route.MatcherFunc(formMatcher(e.Form, e.Files))
+	}
+
+	if len(e.XPath) > 0 {
+		route.MatcherFunc(xpathMatcher(e.XPath))
+	}
+
+	if e.SOAP != nil {
+		route.MatcherFunc(soapMatcher(e.SOAP))
+	}
+
+	return route
+}
@@ -0,0 +1,1031 @@
+package spec
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMockSetDefaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		mock         HTTPMock
+		expectedMock HTTPMock
+	}{
+		{
+			"Empty",
+			HTTPMock{},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+			},
+		},
+		{
+			"WithHTTPResponse",
+			HTTPMock{
+				HTTPResponse: &HTTPResponse{},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+			},
+		},
+		{
+			"WithHTTPForward",
+			HTTPMock{
+				HTTPForward: &HTTPForward{},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPForward: &HTTPForward{
+					Delay:   "",
+					To:      "",
+					Headers: nil,
+				},
+			},
+		},
+		{
+			"WithHTTPResponseAndHTTPForward",
+			HTTPMock{
+				HTTPResponse: &HTTPResponse{},
+				HTTPForward:  &HTTPForward{},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+				HTTPForward: &HTTPForward{
+					Delay:   "",
+					To:      "",
+					Headers: nil,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock.SetDefaults()
+			assert.Equal(t, tc.expectedMock, tc.mock)
+		})
+	}
+}
+
+func TestHTTPMockString(t *testing.T) {
+	tests := []struct {
+		name           string
+		mock           HTTPMock
+		expectedString string
+	}{
+		{
+			"Empty",
+			HTTPMock{},
+			" ",
+		},
+		{
+			"OK",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    map[string]string{},
+				},
+			},
+			"POST|PUT /api/v1/sendMessage",
+		},
+		{
+			"SOAP",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/payments",
+					SOAP:    &SOAPExpect{Action: "urn:payments/GetBalance", Operation: "GetBalance"},
+				},
+			},
+			"POST /payments [GetBalance]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			str := tc.mock.String()
+			assert.Equal(t, tc.expectedString, str)
+		})
+	}
+}
+
+func TestHTTPMockHash(t *testing.T) {
+	tests := []struct {
+		name          string
+		m1            HTTPMock
+		m2            HTTPMock
+		expectedEqual bool
+	}{
+		{
+			"EqualWithHTTPResponse",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    map[string]string{},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "10ms",
+					StatusCode: 201,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: JSON{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+			true,
+		},
+		{
+			"EqualWithHTTPForward",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPForward: &HTTPForward{
+					Delay:   "",
+					To:      "",
+					Headers: map[string]string{},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPForward: &HTTPForward{
+					Delay: "10ms",
+					To:    "http://example.com",
+					Headers: map[string]string{
+						"Is-Test": "true",
+					},
+				},
+			},
+			true,
+		},
+		{
+			"NotEqualWithHTTPResponse",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    map[string]string{},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "10ms",
+					StatusCode: 201,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: JSON{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"NotEqualWithHTTPForward",
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPForward: &HTTPForward{
+					Delay:   "",
+					To:      "",
+					Headers: map[string]string{},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPForward: &HTTPForward{
+					Delay: "10ms",
+					To:    "http://example.com",
+					Headers: map[string]string{
+						"Is-Test": "true",
+					},
+				},
+			},
+			false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedEqual {
+				assert.Equal(t, tc.m1.Hash(), tc.m2.Hash())
+			} else {
+				assert.NotEqual(t, tc.m1.Hash(), tc.m2.Hash())
+			}
+		})
+	}
+}
+
+func TestHTTPMockRoute(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(JSON{
+			"path":   r.URL.Path,
+			"isTest": r.Header.Get("Is-Test"),
+		})
+	}))
+	defer upstream.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name               string
+		mock               HTTPMock
+		reqMethod          string
+		reqURL             string
+		reqQueries         map[string]string
+		reqHeaders         map[string]string
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+		expectedBody       JSON
+	}{
+		{
+			name: "WithHTTPResponse",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "10ms",
+					StatusCode: 200,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: JSON{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+			reqMethod: "POST",
+			reqURL:    "http://example.com/api/v1/sendMessage",
+			reqQueries: map[string]string{
+				"tenantId": "11111111-1111-1111-1111-111111111111",
+				"groupId":  "22222222-2222-2222-2222-222222222222",
+			},
+			reqHeaders: map[string]string{
+				"Accept":       "application/json",
+				"Content-Type": "application/json",
+			},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: JSON{
+				"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+			},
+		},
+		{
+			name: "WithHTTPResponseAndPrefix",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  true,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "10ms",
+					StatusCode: 200,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: JSON{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+			reqMethod: "POST",
+			reqURL:    "http://example.com/api/v1/sendMessage",
+			reqQueries: map[string]string{
+				"tenantId": "11111111-1111-1111-1111-111111111111",
+				"groupId":  "22222222-2222-2222-2222-222222222222",
+			},
+			reqHeaders: map[string]string{
+				"Accept":       "application/json",
+				"Content-Type": "application/json",
+			},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: JSON{
+				"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+			},
+		},
+		{
+			name: "WithHTTPForward",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":       "application/json",
+						"Content-Type": "application/json",
+					},
+				},
+				HTTPForward: &HTTPForward{
+					Delay: "10ms",
+					To:    upstream.URL,
+					Headers: map[string]string{
+						"Is-Test": "true",
+					},
+				},
+			},
+			reqMethod: "POST",
+			reqURL:    "http://example.com/api/v1/sendMessage",
+			reqQueries: map[string]string{
+				"tenantId": "11111111-1111-1111-1111-111111111111",
+				"groupId":  "22222222-2222-2222-2222-222222222222",
+			},
+			reqHeaders: map[string]string{
+				"Accept":       "application/json",
+				"Content-Type": "application/json",
+			},
+			expectedStatusCode: 202,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: JSON{
+				"path":   "/api/v1/sendMessage",
+				"isTest": "true",
+			},
+		},
+		{
+			name: "WithHTTPForwardError",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/app",
+				},
+				HTTPForward: &HTTPForward{
+					To: closed.URL,
+				},
+			},
+			reqMethod:          "GET",
+			reqURL:             "http://example.com/app",
+			expectedStatusCode: 502,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: JSON{
+				"message": "dial tcp " + closed.Listener.Addr().String() + ": connect: connection refused",
+			},
+		},
+		{
+			name: "WithAuthUnauthorized",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/api/v1/teams",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					Body:       JSON{"teams": []interface{}{}},
+				},
+				Auth: &Auth{
+					Bearer: []string{"secret"},
+				},
+			},
+			reqMethod:          "GET",
+			reqURL:             "http://example.com/api/v1/teams",
+			reqHeaders:         map[string]string{"Authorization": "Bearer wrong"},
+			expectedStatusCode: 401,
+			expectedHeaders: map[string]string{
+				"Content-Type":     "application/json",
+				"WWW-Authenticate": `Bearer realm="flax", error="invalid_token", error_description="invalid token"`,
+			},
+			expectedBody: JSON{
+				"message": "invalid token",
+			},
+		},
+		{
+			name: "WithAuthAuthorized",
+			mock: HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/api/v1/teams",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					Body:       JSON{"teams": []interface{}{}},
+				},
+				Auth: &Auth{
+					Bearer: []string{"secret"},
+				},
+			},
+			reqMethod:          "GET",
+			reqURL:             "http://example.com/api/v1/teams",
+			reqHeaders:         map[string]string{"Authorization": "Bearer secret"},
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{},
+			expectedBody: JSON{
+				"teams": []interface{}{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			tc.mock.RegisterRoutes(router)
+
+			req, err := http.NewRequest(tc.reqMethod, tc.reqURL, nil)
+			assert.NoError(t, err)
+
+			q := req.URL.Query()
+			for k, v := range tc.reqQueries {
+				q.Add(k, v)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			for k, v := range tc.reqHeaders {
+				req.Header.Add(k, v)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			for key, val := range tc.expectedHeaders {
+				assert.Equal(t, val, res.Result().Header.Get(key))
+			}
+
+			resBody := JSON{}
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, resBody)
+		})
+	}
+}
+
+func TestHTTPResponseValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      HTTPResponse
+		expectedError string
+	}{
+		{
+			name:          "Empty",
+			response:      HTTPResponse{},
+			expectedError: "",
+		},
+		{
+			name: "WithBodyFile",
+			response: HTTPResponse{
+				BodyFile: "./test/files/document.pdf",
+			},
+			expectedError: "",
+		},
+		{
+			name: "WithMissingBodyFile",
+			response: HTTPResponse{
+				BodyFile: "./test/files/missing.pdf",
+			},
+			expectedError: "invalid body_file",
+		},
+		{
+			name: "WithInvalidBodyBase64",
+			response: HTTPResponse{
+				BodyBase64: "not base64!",
+			},
+			expectedError: "invalid body_base64",
+		},
+		{
+			name: "WithMultipleBodies",
+			response: HTTPResponse{
+				Body:     JSON{"id": "aaaa"},
+				BodyText: "Hello, World!",
+			},
+			expectedError: "only one of body, body_file, body_text, body_base64, or body_xml can be set",
+		},
+		{
+			name: "WithContent",
+			response: HTTPResponse{
+				Content: []HTTPContent{
+					{Type: "application/json", Body: JSON{"id": "aaaa"}},
+					{Type: "text/plain", BodyText: "aaaa"},
+				},
+			},
+			expectedError: "",
+		},
+		{
+			name: "WithContentAndBody",
+			response: HTTPResponse{
+				Body: JSON{"id": "aaaa"},
+				Content: []HTTPContent{
+					{Type: "text/plain", BodyText: "aaaa"},
+				},
+			},
+			expectedError: "content cannot be set along with body, body_file, body_text, body_base64, or body_xml",
+		},
+		{
+			name: "WithContentWithoutType",
+			response: HTTPResponse{
+				Content: []HTTPContent{
+					{BodyText: "aaaa"},
+				},
+			},
+			expectedError: "content type is required",
+		},
+		{
+			name: "WithInvalidContent",
+			response: HTTPResponse{
+				Content: []HTTPContent{
+					{Type: "image/gif", BodyBase64: "not base64!"},
+				},
+			},
+			expectedError: "invalid content image/gif: invalid body_base64",
+		},
+		{
+			name: "WithInvalidBodyXML",
+			response: HTTPResponse{
+				BodyXML: "<order>",
+			},
+			expectedError: "invalid body_xml",
+		},
+		{
+			name: "WithSOAP",
+			response: HTTPResponse{
+				SOAP: &SOAPResponse{Version: SOAP11, Body: "<Balance>100</Balance>"},
+			},
+			expectedError: "",
+		},
+		{
+			name: "WithSOAPAndBody",
+			response: HTTPResponse{
+				BodyXML: "<Balance>100</Balance>",
+				SOAP:    &SOAPResponse{Version: SOAP11},
+			},
+			expectedError: "soap cannot be set along with body, body_file, body_text, body_base64, body_xml, or content",
+		},
+		{
+			name: "WithInvalidSOAP",
+			response: HTTPResponse{
+				SOAP: &SOAPResponse{Version: "1.3"},
+			},
+			expectedError: "unknown soap version: 1.3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.response.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestHTTPMockRouteBody(t *testing.T) {
+	tests := []struct {
+		name                string
+		response            HTTPResponse
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name: "WithBody",
+			response: HTTPResponse{
+				StatusCode: 200,
+				Body:       JSON{"id": "aaaa"},
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "{\"id\":\"aaaa\"}\n",
+		},
+		{
+			name: "WithBodyFile",
+			response: HTTPResponse{
+				StatusCode: 200,
+				BodyFile:   "./test/files/document.pdf",
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "application/pdf",
+			expectedBody:        "%PDF-1.4\n%flax test document\n%%EOF\n",
+		},
+		{
+			name: "WithMissingBodyFile",
+			response: HTTPResponse{
+				StatusCode: 200,
+				BodyFile:   "./test/files/missing.pdf",
+			},
+			expectedStatusCode:  500,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"message\":\"open ./test/files/missing.pdf: no such file or directory\"}\n",
+		},
+		{
+			name: "WithBodyText",
+			response: HTTPResponse{
+				StatusCode: 200,
+				BodyText:   "<h1>Hello, World!</h1>",
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<h1>Hello, World!</h1>",
+		},
+		{
+			name: "WithBodyBase64",
+			response: HTTPResponse{
+				StatusCode: 200,
+				BodyBase64: "R0lGODlhAQABAAAAACw=",
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "image/gif",
+			expectedBody:        "GIF89a\x01\x00\x01\x00\x00\x00\x00,",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+				},
+				HTTPResponse: &tc.response,
+			}
+
+			router := mux.NewRouter()
+			mock.RegisterRoutes(router)
+
+			ts := httptest.NewServer(router)
+			defer ts.Close()
+
+			res, err := http.Get(ts.URL)
+			assert.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := ioutil.ReadAll(res.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tc.expectedContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestHTTPMockRouteContent(t *testing.T) {
+	response := &HTTPResponse{
+		StatusCode: 201,
+		Headers: map[string]string{
+			"Location": "/api/v1/teams/aaaa",
+		},
+		Content: []HTTPContent{
+			{Type: "application/json", Body: JSON{"id": "aaaa"}},
+			{Type: "application/xml", BodyText: "<team><id>aaaa</id></team>"},
+			{Type: "text/csv; charset=utf-8", BodyText: "id\naaaa\n"},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		accept              string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "NoAccept",
+			accept:              "",
+			expectedStatusCode:  201,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"id\":\"aaaa\"}\n",
+		},
+		{
+			name:                "Any",
+			accept:              "*/*",
+			expectedStatusCode:  201,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"id\":\"aaaa\"}\n",
+		},
+		{
+			name:                "Exact",
+			accept:              "application/xml",
+			expectedStatusCode:  201,
+			expectedContentType: "application/xml",
+			expectedBody:        "<team><id>aaaa</id></team>",
+		},
+		{
+			name:                "Quality",
+			accept:              "application/json;q=0.5, text/*;q=0.8, */*;q=0.1",
+			expectedStatusCode:  201,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id\naaaa\n",
+		},
+		{
+			name:                "NotAcceptable",
+			accept:              "image/png, application/json;q=0",
+			expectedStatusCode:  406,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"message\":\"none of the available content types is acceptable\",\"types\":[\"application/json\",\"application/xml\",\"text/csv; charset=utf-8\"]}\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/api/v1/teams",
+				},
+				HTTPResponse: response,
+			}
+
+			router := mux.NewRouter()
+			mock.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/api/v1/teams", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			result := res.Result()
+			assert.Equal(t, tc.expectedStatusCode, result.StatusCode)
+			assert.Equal(t, tc.expectedContentType, result.Header.Get("Content-Type"))
+			assert.Equal(t, "Accept", result.Header.Get("Vary"))
+			assert.Equal(t, tc.expectedBody, res.Body.String())
+
+			if tc.expectedStatusCode == 201 {
+				assert.Equal(t, "/api/v1/teams/aaaa", result.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestHTTPMockValidateXPath(t *testing.T) {
+	m := HTTPMock{
+		HTTPExpect: HTTPExpect{
+			XPath: map[string]string{"/order[": ""},
+		},
+	}
+
+	assert.EqualError(t, m.Validate(), "invalid xpath /order[: expression must evaluate to a node-set")
+}
+
+func TestHTTPExpectValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		expect        HTTPExpect
+		expectedError string
+	}{
+		{
+			name:          "InvalidQuery",
+			expect:        HTTPExpect{Queries: map[string]string{"page": "("}},
+			expectedError: "invalid query page: error parsing regexp: missing closing ): `^($`",
+		},
+		{
+			name:          "InvalidHeader",
+			expect:        HTTPExpect{Headers: map[string]string{"Accept": "("}},
+			expectedError: "invalid header Accept: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:          "InvalidCookie",
+			expect:        HTTPExpect{Cookies: map[string]string{"session": "*"}},
+			expectedError: "invalid cookie session: error parsing regexp: missing argument to repetition operator: `*`",
+		},
+		{
+			name:          "InvalidFormField",
+			expect:        HTTPExpect{Form: map[string]string{"name": "["}},
+			expectedError: "invalid form field name: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name:          "InvalidFilename",
+			expect:        HTTPExpect{Files: map[string]HTTPFile{"avatar": {Filename: "("}}},
+			expectedError: "invalid file avatar: invalid filename: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:          "InvalidContentType",
+			expect:        HTTPExpect{Files: map[string]HTTPFile{"avatar": {ContentType: "("}}},
+			expectedError: "invalid file avatar: invalid content type: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:          "InvalidXPathPattern",
+			expect:        HTTPExpect{XPath: map[string]string{"/order/id": "("}},
+			expectedError: "invalid xpath /order/id: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "Valid",
+			expect: HTTPExpect{
+				Queries: map[string]string{"page": "[0-9]+"},
+				Headers: map[string]string{"Accept": "json"},
+				Cookies: map[string]string{"session": ".+"},
+				Form:    map[string]string{"name": "^J"},
+				Files:   map[string]HTTPFile{"avatar": {Filename: `\.png$`, ContentType: "image/.*"}},
+				XPath:   map[string]string{"/order/id": "^[0-9]+$"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.expect.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			// Mocks embedding the expectation are invalid too
+			m := HTTPMock{HTTPExpect: tc.expect}
+			assert.Equal(t, err, m.Validate())
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	// Hash functions used by JWT algorithms.
+	_ "crypto/sha512"
+)
+
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// jsonWebKey is a public key in a JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// publicKey decodes the public key of a JSON web key.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa modulus: %s", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa exponent: %s", err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ec x coordinate: %s", err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ec y coordinate: %s", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// jwks is a set of public keys keyed by their key ids.
+type jwks map[string]crypto.PublicKey
+
+// readJWKS reads a JSON Web Key Set from a file.
+func readJWKS(path string) (jwks, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks file %s: %s", path, err)
+	}
+
+	keys := jwks{}
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in %s: %s", k.Kid, path, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parseJWT verifies a JSON web token (RFC 7519) and returns its claims.
+// HMAC tokens are verified with the secret, and RSA and ECDSA tokens with the keys.
+func parseJWT(token string, secret []byte, keys jwks, now time.Time) (JSON, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %s", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %s", err)
+	}
+
+	if err := verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature, secret, keys); err != nil {
+		return nil, err
+	}
+
+	claims := JSON{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %s", err)
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return nil, errors.New("token is expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, errors.New("token is not valid yet")
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// verifySignature verifies the signature of a signed token using its algorithm.
+// If the token has no key id, all keys are tried.
+func verifySignature(alg, kid, signed string, signature, secret []byte, keys jwks) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+
+	if alg[:2] == "HS" {
+		if len(secret) == 0 {
+			return fmt.Errorf("unsupported algorithm: %s", alg)
+		}
+
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	candidates := []crypto.PublicKey{}
+	if key, ok := keys[kid]; ok {
+		candidates = append(candidates, key)
+	} else if kid == "" {
+		for _, key := range keys {
+			candidates = append(candidates, key)
+		}
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	for _, key := range candidates {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			if alg[:2] == "RS" && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+				return nil
+			}
+			if alg[:2] == "PS" && rsa.VerifyPSS(key, hash, digest, signature, nil) == nil {
+				return nil
+			}
+
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if alg[:2] == "ES" && len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				if ecdsa.Verify(key, digest, r, s) {
+					return nil
+				}
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("unknown key: %s", kid)
+	}
+
+	return errors.New("invalid signature")
+}
+
+// jwtSigner signs JSON web tokens using a private key.
+type jwtSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+// newJWTSigner creates a signer for an RSA or ECDSA private key.
+// The key id is derived from the public key.
+func newJWTSigner(key crypto.Signer) (*jwtSigner, error) {
+	var alg string
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			alg = "ES256"
+		case elliptic.P384():
+			alg = "ES384"
+		case elliptic.P521():
+			alg = "ES512"
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(der)
+
+	return &jwtSigner{
+		kid: base64.RawURLEncoding.EncodeToString(sum[:8]),
+		alg: alg,
+		key: key,
+	}, nil
+}
+
+// readPrivateKey reads a PEM-encoded RSA or ECDSA private key from a file.
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block found in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block in %s: %s", path, block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %s", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key in %s", path)
+	}
+
+	return signer, nil
+}
+
+// Sign creates a signed token with the given claims.
+func (s *jwtSigner) Sign(claims JSON) (string, error) {
+	header, err := json.Marshal(JSON{"alg": s.alg, "typ": "JWT", "kid": s.kid})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := jwtHashes[s.alg[2:]]
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, key, digest); err == nil {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// jwk returns the public key of the signer as a JSON web key.
+func (s *jwtSigner) jwk() jsonWebKey {
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+
+	k := jsonWebKey{
+		Kid: s.kid,
+		Use: "sig",
+		Alg: s.alg,
+	}
+
+	switch key := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = encode(key.N)
+		k.E = encode(big.NewInt(int64(key.E)))
+	case *ecdsa.PublicKey:
+		k.Kty = "EC"
+		k.Crv = key.Curve.Params().Name
+		k.X = encode(key.X)
+		k.Y = encode(key.Y)
+	}
+
+	return k
+}
+
+// keys returns the public key of the signer as a key set for verifying tokens.
+func (s *jwtSigner) keys() jwks {
+	return jwks{s.kid: s.key.Public()}
+}
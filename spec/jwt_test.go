@@ -0,0 +1,243 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signToken creates a signed JSON web token for testing.
+func signToken(t *testing.T, alg, kid string, key interface{}, claims JSON) string {
+	header := JSON{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signed := encode(header) + "." + encode(claims)
+	hash := jwtHashes[alg[2:]]
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	var err error
+
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+
+	assert.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes the public keys of private keys to a JWKS file for testing.
+func writeJWKS(t *testing.T, keys map[string]crypto.PrivateKey) string {
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				N:   encode(key.N),
+				E:   encode(big.NewInt(int64(key.E))),
+			})
+		case *ecdsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "EC",
+				Kid: kid,
+				Crv: key.Curve.Params().Name,
+				X:   encode(key.X),
+				Y:   encode(key.Y),
+			})
+		}
+	}
+
+	data, err := json.Marshal(set)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	return path
+}
+
+func TestReadJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	path := writeJWKS(t, map[string]crypto.PrivateKey{
+		"rsa": rsaKey,
+		"ec":  ecKey,
+	})
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	assert.NoError(t, ioutil.WriteFile(invalid, []byte(`{"keys":[{"kty":"oct","kid":"hmac"}]}`), 0644))
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedKeys  jwks
+		expectedError string
+	}{
+		{
+			name:          "Missing",
+			path:          "missing.json",
+			expectedError: "open missing.json: no such file or directory",
+		},
+		{
+			name:          "UnsupportedKey",
+			path:          invalid,
+			expectedError: `invalid key "hmac" in ` + invalid + ": unsupported key type: oct",
+		},
+		{
+			name: "OK",
+			path: path,
+			expectedKeys: jwks{
+				"rsa": &rsaKey.PublicKey,
+				"ec":  &ecKey.PublicKey,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := readJWKS(tc.path)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedKeys, keys)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, keys)
+			}
+		})
+	}
+}
+
+func TestParseJWT(t *testing.T) {
+	now := time.Now()
+	secret := []byte("secret")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	keys := jwks{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	}
+
+	claims := JSON{"sub": "jane", "exp": float64(now.Add(time.Hour).Unix())}
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedClaims JSON
+		expectedError  string
+	}{
+		{
+			name:          "Malformed",
+			token:         "token",
+			expectedError: "malformed token",
+		},
+		{
+			name:           "HS256",
+			token:          signToken(t, "HS256", "", secret, claims),
+			expectedClaims: claims,
+		},
+		{
+			name:          "HS256InvalidSignature",
+			token:         signToken(t, "HS256", "", []byte("wrong"), claims),
+			expectedError: "invalid signature",
+		},
+		{
+			name:           "RS256",
+			token:          signToken(t, "RS256", "rsa", rsaKey, claims),
+			expectedClaims: claims,
+		},
+		{
+			name:           "ES256",
+			token:          signToken(t, "ES256", "", ecKey, claims),
+			expectedClaims: claims,
+		},
+		{
+			name:          "UnknownKey",
+			token:         signToken(t, "RS256", "other", rsaKey, claims),
+			expectedError: "unknown key: other",
+		},
+		{
+			name:          "UnsupportedAlgorithm",
+			token:         "eyJhbGciOiJub25lIn0.e30.",
+			expectedError: "unsupported algorithm: none",
+		},
+		{
+			name:          "Expired",
+			token:         signToken(t, "HS256", "", secret, JSON{"exp": float64(now.Add(-time.Minute).Unix())}),
+			expectedError: "token is expired",
+		},
+		{
+			name:          "NotValidYet",
+			token:         signToken(t, "HS256", "", secret, JSON{"nbf": float64(now.Add(time.Minute).Unix())}),
+			expectedError: "token is not valid yet",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := parseJWT(tc.token, secret, keys, now)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedClaims, claims)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, claims)
+			}
+		})
+	}
+}
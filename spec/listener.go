@@ -0,0 +1,135 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Listener represents an additional port for serving its own set of mocks.
+// Mocks are assigned to a listener by its name, and mocks without a listener are served on the http port.
+type Listener struct {
+	Name string `json:"name" yaml:"name"`
+	Port uint16 `json:"port" yaml:"port"`
+}
+
+// WithListener returns a new context for a request received by a listener.
+func WithListener(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, listenerKey, name)
+}
+
+// ListenerFromContext returns the name of the listener receiving a request.
+// An empty name means the request is received on the http port.
+func ListenerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(listenerKey).(string)
+	return name
+}
+
+// validateListeners checks whether or not a list of listeners is valid.
+func validateListeners(c Config) error {
+	names := map[string]bool{}
+	ports := map[uint16]bool{c.HTTPPort: true, c.HTTPSPort: true}
+
+	for _, l := range c.Listeners {
+		if l.Name == "" {
+			return errors.New("listener name is required")
+		}
+
+		if names[l.Name] {
+			return fmt.Errorf("listener %s is defined more than once", l.Name)
+		}
+
+		if l.Port == 0 || ports[l.Port] {
+			return fmt.Errorf("listener %s must have a unique port", l.Name)
+		}
+
+		names[l.Name] = true
+		ports[l.Port] = true
+	}
+
+	return nil
+}
+
+// HasListener determines whether or not a listener is defined.
+// The empty name refers to the http port which always exists.
+func (c Config) HasListener(name string) bool {
+	if name == "" {
+		return true
+	}
+
+	for _, l := range c.Listeners {
+		if l.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withListener qualifies the string representation of a mock with its listener.
+func withListener(listener, s string) string {
+	if listener == "" {
+		return s
+	}
+
+	return listener + ": " + s
+}
+
+// listenerMismatch is the diagnosis of a request received by another listener than the listener of a mock.
+func listenerMismatch(mock, listener string, r Request) Diagnosis {
+	return Diagnosis{
+		Mock:  mock,
+		Score: 0,
+		Mismatches: []Mismatch{
+			{Criterion: "listener", Expected: listener, Actual: r.Listener},
+		},
+	}
+}
+
+// matchListener creates a route matcher for requests received by a listener.
+func matchListener(name string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return ListenerFromContext(r.Context()) == name
+	}
+}
+
+// matchHost determines whether or not a host matches a host pattern.
+// A pattern can have * as a wildcard, and the port of the host is ignored if the pattern has no port.
+func matchHost(pattern, host string) bool {
+	if !strings.Contains(pattern, ":") {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+
+	return matchGlob(pattern, host)
+}
+
+// hostMatcher creates a route matcher for a host pattern.
+func hostMatcher(pattern string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return matchHost(pattern, r.Host)
+	}
+}
+
+// matchGlob determines whether or not a string matches a pattern with * as a wildcard regardless of case.
+func matchGlob(pattern, s string) bool {
+	if pattern == "*" || strings.EqualFold(pattern, s) {
+		return true
+	}
+
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+
+	expr := "(?i)^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$"
+	matched, _ := regexp.MatchString(expr, s)
+
+	return matched
+}
@@ -0,0 +1,166 @@
+package spec
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenerContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", ListenerFromContext(ctx))
+
+	ctx = WithListener(ctx, "payments")
+	assert.Equal(t, "payments", ListenerFromContext(ctx))
+}
+
+func TestValidateListeners(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{
+			name:          "None",
+			config:        Config{HTTPPort: 8080, HTTPSPort: 8443},
+			expectedError: "",
+		},
+		{
+			name: "OK",
+			config: Config{HTTPPort: 8080, HTTPSPort: 8443, Listeners: []Listener{
+				{Name: "payments", Port: 8081},
+				{Name: "identity", Port: 8082},
+			}},
+			expectedError: "",
+		},
+		{
+			name: "NoName",
+			config: Config{HTTPPort: 8080, HTTPSPort: 8443, Listeners: []Listener{
+				{Port: 8081},
+			}},
+			expectedError: "listener name is required",
+		},
+		{
+			name: "DuplicateName",
+			config: Config{HTTPPort: 8080, HTTPSPort: 8443, Listeners: []Listener{
+				{Name: "payments", Port: 8081},
+				{Name: "payments", Port: 8082},
+			}},
+			expectedError: "listener payments is defined more than once",
+		},
+		{
+			name: "DuplicatePort",
+			config: Config{HTTPPort: 8080, HTTPSPort: 8443, Listeners: []Listener{
+				{Name: "payments", Port: 8080},
+			}},
+			expectedError: "listener payments must have a unique port",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateListeners(tc.config)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfigHasListener(t *testing.T) {
+	config := Config{
+		Listeners: []Listener{
+			{Name: "payments", Port: 8081},
+		},
+	}
+
+	assert.True(t, config.HasListener(""))
+	assert.True(t, config.HasListener("payments"))
+	assert.False(t, config.HasListener("identity"))
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		host          string
+		expectedMatch bool
+	}{
+		{"Exact", "payments.local", "payments.local", true},
+		{"ExactCase", "payments.local", "Payments.Local", true},
+		{"IgnorePort", "payments.local", "payments.local:8080", true},
+		{"WithPort", "payments.local:8080", "payments.local:8080", true},
+		{"WithPortMismatch", "payments.local:8080", "payments.local:9090", false},
+		{"Wildcard", "*.example.com", "api.example.com", true},
+		{"WildcardMismatch", "*.example.com", "example.org", false},
+		{"Mismatch", "payments.local", "identity.local", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMatch, matchHost(tc.pattern, tc.host))
+		})
+	}
+}
+
+func TestMockRoutesHostAndListener(t *testing.T) {
+	mocks := []interface {
+		RegisterRoutes(*mux.Router)
+	}{
+		HTTPMock{
+			HTTPExpect:   HTTPExpect{Host: "payments.local", Methods: []string{"GET"}, Path: "/health"},
+			HTTPResponse: &HTTPResponse{StatusCode: 201},
+		},
+		HTTPMock{
+			HTTPExpect:   HTTPExpect{Host: "identity.local", Methods: []string{"GET"}, Path: "/health"},
+			HTTPResponse: &HTTPResponse{StatusCode: 202},
+		},
+		HTTPMock{
+			HTTPExpect:   HTTPExpect{Listener: "search", Methods: []string{"GET"}, Path: "/health"},
+			HTTPResponse: &HTTPResponse{StatusCode: 203},
+		},
+		RESTMock{
+			RESTExpect:   RESTExpect{Host: "*.teams.local", BasePath: "/api/v1/teams"},
+			RESTResponse: RESTResponse{GetStatusCode: 200},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		listener           string
+		host               string
+		path               string
+		expectedStatusCode int
+	}{
+		{"PaymentsHost", "", "payments.local:8080", "/health", 201},
+		{"IdentityHost", "", "identity.local", "/health", 202},
+		{"UnknownHost", "", "search.local", "/health", 404},
+		{"Listener", "search", "search.local", "/health", 203},
+		{"RESTHost", "", "api.teams.local", "/api/v1/teams", 200},
+		{"RESTHostMismatch", "", "teams.local", "/api/v1/teams", 404},
+		{"RESTOtherListener", "search", "api.teams.local", "/api/v1/teams", 404},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			for _, m := range mocks {
+				m.RegisterRoutes(router)
+			}
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			req.Host = tc.host
+			req = req.WithContext(WithListener(req.Context(), tc.listener))
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var specExtensions = map[string]bool{
+	".json": true,
+	".yaml": true,
+	".yml":  true,
+}
+
+// loader reads and merges spec files and directories.
+type loader struct {
+	overrides      map[string]string
+	vars           map[string]string
+	spec           *Spec
+	visited        map[string]bool
+	configFile     string
+	httpFiles      []string
+	restFiles      []string
+	oidcFiles      []string
+	graphqlFiles   []string
+	grpcFiles      []string
+	websocketFiles []string
+}
+
+func newLoader(overrides map[string]string) *loader {
+	if overrides == nil {
+		overrides = map[string]string{}
+	}
+
+	return &loader{
+		overrides: overrides,
+		vars:      map[string]string{},
+		spec: &Spec{
+			HTTPMocks: []HTTPMock{},
+			RESTMocks: []RESTMock{},
+		},
+		visited: map[string]bool{},
+	}
+}
+
+// decodeBytes decodes the content of a JSON or YAML spec file.
+func decodeBytes(data []byte, v interface{}) error {
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeFile decodes a single JSON or YAML spec file after expanding variables in it.
+func (l *loader) decodeFile(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = l.expand(path, data); err != nil {
+		return nil, err
+	}
+
+	spec := new(Spec)
+	if err := decodeBytes(data, spec); err != nil {
+		return nil, fmt.Errorf("unknown spec file: %s: %s", path, err)
+	}
+
+	return spec, nil
+}
+
+// resolvePath resolves a path relative to the spec file referring to it.
+func resolvePath(specFile, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(specFile), path)
+}
+
+// load reads a spec file or all spec files in a directory.
+func (l *loader) load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return l.loadDir(path)
+	}
+
+	return l.loadFile(path)
+}
+
+// loadDir reads all spec files in a directory in lexical order.
+// Sub-directories are not read unless they are included.
+func (l *loader) loadDir(dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, info := range infos {
+		if info.IsDir() || !specExtensions[filepath.Ext(info.Name())] {
+			continue
+		}
+
+		found = true
+		if err := l.loadFile(filepath.Join(dir, info.Name())); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no spec file found in %s", dir)
+	}
+
+	return nil
+}
+
+// loadFile reads a spec file and all files it includes.
+// A file is read only once, so including a file more than once or cyclic includes have no effect.
+func (l *loader) loadFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if l.visited[abs] {
+		return nil
+	}
+	l.visited[abs] = true
+
+	s, err := l.decodeFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(s.Config, Config{}) {
+		if l.configFile != "" {
+			return fmt.Errorf("config is defined in both %s and %s", l.configFile, path)
+		}
+		l.configFile = path
+		l.spec.Config = s.Config
+
+		if s.Config.Fallback != nil {
+			resolveBodyFiles(path, s.Config.Fallback.HTTPResponse)
+		}
+
+		resolveAuthFiles(path, s.Config.Auth)
+	}
+
+	// The listener of a spec file applies to all mocks in it with no listener.
+	for i := range s.HTTPMocks {
+		if s.HTTPMocks[i].HTTPExpect.Listener == "" {
+			s.HTTPMocks[i].HTTPExpect.Listener = s.Listener
+		}
+	}
+	for i := range s.RESTMocks {
+		if s.RESTMocks[i].RESTExpect.Listener == "" {
+			s.RESTMocks[i].RESTExpect.Listener = s.Listener
+		}
+	}
+	for i := range s.OIDCMocks {
+		if s.OIDCMocks[i].Listener == "" {
+			s.OIDCMocks[i].Listener = s.Listener
+		}
+	}
+	for i := range s.GraphQLMocks {
+		if s.GraphQLMocks[i].Listener == "" {
+			s.GraphQLMocks[i].Listener = s.Listener
+		}
+	}
+	for i := range s.GRPCMocks {
+		if s.GRPCMocks[i].Listener == "" {
+			s.GRPCMocks[i].Listener = s.Listener
+		}
+	}
+	for i := range s.WebSocketMocks {
+		if s.WebSocketMocks[i].HTTPExpect.Listener == "" {
+			s.WebSocketMocks[i].HTTPExpect.Listener = s.Listener
+		}
+	}
+
+	for _, m := range s.HTTPMocks {
+		resolveBodyFiles(path, m.HTTPResponse)
+		resolveAuthFiles(path, m.Auth)
+		l.spec.HTTPMocks = append(l.spec.HTTPMocks, m)
+		l.httpFiles = append(l.httpFiles, path)
+	}
+
+	for _, m := range s.RESTMocks {
+		resolveAuthFiles(path, m.Auth)
+		l.spec.RESTMocks = append(l.spec.RESTMocks, m)
+		l.restFiles = append(l.restFiles, path)
+	}
+
+	for _, m := range s.OIDCMocks {
+		m.KeyFile = resolvePath(path, m.KeyFile)
+		l.spec.OIDCMocks = append(l.spec.OIDCMocks, m)
+		l.oidcFiles = append(l.oidcFiles, path)
+	}
+
+	for _, m := range s.GraphQLMocks {
+		m.SchemaFile = resolvePath(path, m.SchemaFile)
+		resolveAuthFiles(path, m.Auth)
+		l.spec.GraphQLMocks = append(l.spec.GraphQLMocks, m)
+		l.graphqlFiles = append(l.graphqlFiles, path)
+	}
+
+	for _, m := range s.GRPCMocks {
+		m.DescriptorSet = resolvePath(path, m.DescriptorSet)
+		l.spec.GRPCMocks = append(l.spec.GRPCMocks, m)
+		l.grpcFiles = append(l.grpcFiles, path)
+	}
+
+	for _, m := range s.WebSocketMocks {
+		resolveAuthFiles(path, m.Auth)
+		l.spec.WebSocketMocks = append(l.spec.WebSocketMocks, m)
+		l.websocketFiles = append(l.websocketFiles, path)
+	}
+
+	for _, pattern := range s.Includes {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include in %s: %s", path, err)
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("include %q in %s matches no file", pattern, path)
+		}
+
+		for _, match := range matches {
+			if err := l.load(match); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveBodyFiles resolves the body files of a response relative to the spec file defining them.
+func resolveBodyFiles(specFile string, r *HTTPResponse) {
+	if r == nil {
+		return
+	}
+
+	r.BodyFile = resolvePath(specFile, r.BodyFile)
+	for i := range r.Content {
+		r.Content[i].BodyFile = resolvePath(specFile, r.Content[i].BodyFile)
+	}
+}
+
+// resolveAuthFiles resolves the jwks file of authentication settings relative to the spec file defining them.
+func resolveAuthFiles(specFile string, a *Auth) {
+	if a == nil || a.JWT == nil {
+		return
+	}
+
+	a.JWT.JWKSFile = resolvePath(specFile, a.JWT.JWKSFile)
+}
+
+// validate reports the first invalid mock along with the file defining it.
+// It should be called after default values are set.
+func (l *loader) validate() error {
+	if err := l.spec.Config.Unmatched.Validate(); err != nil {
+		return fmt.Errorf("invalid config in %s: %s", l.configFile, err)
+	}
+
+	if err := l.spec.Config.Fallback.Validate(); err != nil {
+		return fmt.Errorf("invalid config in %s: %s", l.configFile, err)
+	}
+
+	if err := validateListeners(l.spec.Config); err != nil {
+		return fmt.Errorf("invalid config in %s: %s", l.configFile, err)
+	}
+
+	if err := l.spec.Config.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid config in %s: invalid auth: %s", l.configFile, err)
+	}
+
+	if err := l.spec.Config.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid config in %s: invalid rate limit: %s", l.configFile, err)
+	}
+
+	if err := l.spec.Config.Chaos.Validate(); err != nil {
+		return fmt.Errorf("invalid config in %s: invalid chaos: %s", l.configFile, err)
+	}
+
+	for i, m := range l.spec.HTTPMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.httpFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.HTTPExpect.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.httpFiles[i], m.HTTPExpect.Listener)
+		}
+	}
+
+	for i, m := range l.spec.RESTMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.restFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.RESTExpect.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.restFiles[i], m.RESTExpect.Listener)
+		}
+	}
+
+	for i, m := range l.spec.OIDCMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.oidcFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.oidcFiles[i], m.Listener)
+		}
+	}
+
+	for i, m := range l.spec.GraphQLMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.graphqlFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.graphqlFiles[i], m.Listener)
+		}
+	}
+
+	for i, m := range l.spec.GRPCMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.grpcFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.grpcFiles[i], m.Listener)
+		}
+	}
+
+	for i, m := range l.spec.WebSocketMocks {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid mock %s in %s: %s", m, l.websocketFiles[i], err)
+		}
+
+		if !l.spec.Config.HasListener(m.HTTPExpect.Listener) {
+			return fmt.Errorf("invalid mock %s in %s: unknown listener %s", m, l.websocketFiles[i], m.HTTPExpect.Listener)
+		}
+	}
+
+	return nil
+}
+
+// checkConflicts reports all mocks with the same hash along with the files defining them.
+// It should be called after default values are set.
+func (l *loader) checkConflicts() error {
+	type definition struct {
+		mock string
+		file string
+	}
+
+	defs := map[uint64]definition{}
+	conflicts := []string{}
+
+	check := func(hash uint64, mock, file string) {
+		if def, ok := defs[hash]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s in %s conflicts with %s in %s", mock, file, def.mock, def.file))
+		} else {
+			defs[hash] = definition{mock, file}
+		}
+	}
+
+	for i, m := range l.spec.HTTPMocks {
+		check(m.Hash(), m.String(), l.httpFiles[i])
+	}
+
+	for i, m := range l.spec.RESTMocks {
+		check(m.Hash(), m.String(), l.restFiles[i])
+	}
+
+	for i, m := range l.spec.OIDCMocks {
+		check(m.Hash(), m.String(), l.oidcFiles[i])
+	}
+
+	for i, m := range l.spec.GraphQLMocks {
+		check(m.Hash(), m.String(), l.graphqlFiles[i])
+	}
+
+	for i, m := range l.spec.GRPCMocks {
+		check(m.Hash(), m.String(), l.grpcFiles[i])
+	}
+
+	for i, m := range l.spec.WebSocketMocks {
+		check(m.Hash(), m.String(), l.websocketFiles[i])
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("conflicting mocks: %s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
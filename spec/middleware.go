@@ -0,0 +1,11 @@
+package spec
+
+import "net/http"
+
+// wrapHandler wraps the handler of a mock with the configurations shared by all mocks.
+// This is the only place defining the order of them: CORS responds first, then chaos is injected,
+// then the rate limit is applied, and finally the request is authenticated.
+// Any of them can be nil.
+func wrapHandler(cors *CORS, chaos *Chaos, rateLimit *RateLimit, auth *Auth, h http.Handler) http.Handler {
+	return cors.Handler(chaos.Handler(rateLimit.Handler(auth.Handler(h))))
+}
@@ -0,0 +1,791 @@
+package spec
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	oidcCodeTTL        = 10 * time.Minute
+	oidcDiscoveryPath  = "/.well-known/openid-configuration"
+	oidcJWKSPath       = "/.well-known/jwks.json"
+	oidcAuthorizePath  = "/authorize"
+	oidcTokenPath      = "/token"
+	oidcUserinfoPath   = "/userinfo"
+	oidcDefaultKeySize = 2048
+)
+
+// OIDCClient represents a client registered with an OIDC mock.
+// If Scopes is empty, any scope is allowed.
+// If Audience is empty, the client id is used as the audience of access tokens.
+type OIDCClient struct {
+	ID           string   `json:"id" yaml:"id"`
+	Secret       string   `json:"secret" yaml:"secret"`
+	RedirectURIs []string `json:"redirectURIs" yaml:"redirect_uris"`
+	Scopes       []string `json:"scopes" yaml:"scopes"`
+	Audience     string   `json:"audience" yaml:"audience"`
+	Claims       JSON     `json:"claims" yaml:"claims"`
+}
+
+// OIDCUser represents a user of an OIDC mock.
+type OIDCUser struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	Claims   JSON   `json:"claims" yaml:"claims"`
+}
+
+// OIDCMock represents a mock OAuth2 and OpenID Connect provider.
+// If Issuer is empty, the issuer is derived from requests.
+// If KeyFile is empty, a new RSA key is generated for signing tokens.
+type OIDCMock struct {
+	Listener string       `json:"listener" yaml:"listener"`
+	Host     string       `json:"host" yaml:"host"`
+	BasePath string       `json:"basePath" yaml:"base_path"`
+	Issuer   string       `json:"issuer" yaml:"issuer"`
+	KeyFile  string       `json:"keyFile" yaml:"key_file"`
+	TokenTTL string       `json:"tokenTTL" yaml:"token_ttl"`
+	Clients   []OIDCClient `json:"clients" yaml:"clients"`
+	Users     []OIDCUser   `json:"users" yaml:"users"`
+	RateLimit *RateLimit   `json:"rateLimit" yaml:"rate_limit"`
+	Priority  int          `json:"priority" yaml:"priority"`
+	Tags      []string     `json:"tags" yaml:"tags"`
+
+	chaos    *Chaos
+	provider *oidcProvider
+}
+
+// SetDefaults set default values for empty fields.
+func (m *OIDCMock) SetDefaults() {
+	m.BasePath = path.Clean("/" + m.BasePath)
+
+	if m.TokenTTL == "" {
+		m.TokenTTL = "1h"
+	}
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+// The spec-wide auth is not inherited, since clients and users authenticate with the provider itself.
+func (m *OIDCMock) Inherit(c Config) {
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *OIDCMock) Validate() error {
+	if ttl, err := time.ParseDuration(m.TokenTTL); err != nil || ttl <= 0 {
+		return fmt.Errorf("invalid token ttl: %s", m.TokenTTL)
+	}
+
+	if m.KeyFile != "" {
+		key, err := readPrivateKey(m.KeyFile)
+		if err != nil {
+			return err
+		}
+
+		if _, err := newJWTSigner(key); err != nil {
+			return err
+		}
+	}
+
+	ids := map[string]bool{}
+	for _, c := range m.Clients {
+		if c.ID == "" {
+			return errors.New("client id is required")
+		}
+
+		if ids[c.ID] {
+			return fmt.Errorf("client %s is defined more than once", c.ID)
+		}
+		ids[c.ID] = true
+	}
+
+	for _, u := range m.Users {
+		if u.Username == "" {
+			return errors.New("username is required")
+		}
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	return nil
+}
+
+// String returns a string representation of the mock.
+func (m OIDCMock) String() string {
+	return withListener(m.Listener, "OIDC "+m.Host+m.BasePath)
+}
+
+// Hash calculates a hash for an oidc mock based on its location.
+func (m OIDCMock) Hash() uint64 {
+	h := fnv.New64a()
+
+	hashString(h, "oidc", m.Listener, m.Host)
+	hashString(h, m.BasePath)
+
+	return h.Sum64()
+}
+
+// Rank returns the rank of the mock for ordering overlapping mocks.
+func (m OIDCMock) Rank() Rank {
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(false, m.BasePath, 0),
+	}
+}
+
+// Diagnose scores a recorded request against the mock.
+// Mocks of other listeners are not considered at all.
+func (m OIDCMock) Diagnose(r Request) Diagnosis {
+	if m.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.Listener, r)
+	}
+
+	e := HTTPExpect{
+		Host:    m.Host,
+		Methods: []string{"GET", "POST"},
+		Path:    m.BasePath,
+		Prefix:  true,
+	}
+
+	d := e.Diagnose(r)
+	d.Mock = m.String()
+
+	return d
+}
+
+// endpoint returns the path of an endpoint relative to the base path.
+func (m OIDCMock) endpoint(p string) string {
+	return path.Join(m.BasePath, p)
+}
+
+// baseURL returns the url of the base path for a request.
+func (m OIDCMock) baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + strings.TrimSuffix(m.BasePath, "/")
+}
+
+// issuer returns the issuer of tokens for a request.
+func (m OIDCMock) issuer(r *http.Request) string {
+	if m.Issuer != "" {
+		return m.Issuer
+	}
+
+	return m.baseURL(r)
+}
+
+func (m OIDCMock) client(id string) *OIDCClient {
+	for i := range m.Clients {
+		if m.Clients[i].ID == id {
+			return &m.Clients[i]
+		}
+	}
+	return nil
+}
+
+func (m OIDCMock) user(username string) *OIDCUser {
+	for i := range m.Users {
+		if m.Users[i].Username == username {
+			return &m.Users[i]
+		}
+	}
+	return nil
+}
+
+// oidcGrant is an authorization granted to a client for issuing tokens.
+type oidcGrant struct {
+	clientID    string
+	username    string
+	scope       []string
+	nonce       string
+	redirectURI string
+	challenge   string
+	method      string
+	expires     time.Time
+}
+
+// oidcProvider keeps the signing key and the issued codes and refresh tokens of an oidc mock.
+type oidcProvider struct {
+	mutex   sync.Mutex
+	signer  *jwtSigner
+	ttl     time.Duration
+	codes   map[string]oidcGrant
+	refresh map[string]oidcGrant
+}
+
+func newOIDCProvider(m OIDCMock) (*oidcProvider, error) {
+	ttl, err := time.ParseDuration(m.TokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token ttl: %s", m.TokenTTL)
+	}
+
+	var signer *jwtSigner
+	if m.KeyFile != "" {
+		key, err := readPrivateKey(m.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if signer, err = newJWTSigner(key); err != nil {
+			return nil, err
+		}
+	} else {
+		key, err := rsa.GenerateKey(rand.Reader, oidcDefaultKeySize)
+		if err != nil {
+			return nil, err
+		}
+
+		if signer, err = newJWTSigner(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oidcProvider{
+		signer:  signer,
+		ttl:     ttl,
+		codes:   map[string]oidcGrant{},
+		refresh: map[string]oidcGrant{},
+	}, nil
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// issueCode stores a grant and returns a new authorization code for it.
+func (p *oidcProvider) issueCode(g oidcGrant) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	code := randomToken()
+	g.expires = time.Now().Add(oidcCodeTTL)
+	p.codes[code] = g
+
+	return code
+}
+
+// redeemCode returns the grant of an authorization code.
+// A code can only be redeemed once.
+func (p *oidcProvider) redeemCode(code string) (oidcGrant, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+
+	if !ok || time.Now().After(g.expires) {
+		return oidcGrant{}, false
+	}
+
+	return g, true
+}
+
+// redeemRefreshToken returns the grant of a refresh token.
+// Refresh tokens are rotated, so a refresh token can only be redeemed once.
+func (p *oidcProvider) redeemRefreshToken(token string) (oidcGrant, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	g, ok := p.refresh[token]
+	delete(p.refresh, token)
+
+	return g, ok
+}
+
+func (p *oidcProvider) issueRefreshToken(g oidcGrant) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	token := randomToken()
+	p.refresh[token] = g
+
+	return token
+}
+
+// oauthError is an OAuth 2.0 error response (RFC 6749).
+type oauthError struct {
+	statusCode  int
+	code        string
+	description string
+}
+
+func (e *oauthError) Error() string {
+	return e.description
+}
+
+func invalidRequest(code, format string, args ...interface{}) *oauthError {
+	return &oauthError{
+		statusCode:  http.StatusBadRequest,
+		code:        code,
+		description: fmt.Sprintf(format, args...),
+	}
+}
+
+func writeOAuthError(w http.ResponseWriter, err *oauthError) {
+	if err.statusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", defaultRealm))
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, err.statusCode, JSON{
+		"error":             err.code,
+		"error_description": err.description,
+	})
+}
+
+// scopes checks the requested scopes against the scopes allowed for a client.
+// If no scope is requested, all scopes allowed for the client are granted.
+func (c *OIDCClient) scopes(requested string) ([]string, *oauthError) {
+	scope := strings.Fields(requested)
+	if len(scope) == 0 {
+		return c.Scopes, nil
+	}
+
+	if len(c.Scopes) > 0 {
+		for _, s := range scope {
+			if !containsString(c.Scopes, s) {
+				return nil, invalidRequest("invalid_scope", "scope %s is not allowed", s)
+			}
+		}
+	}
+
+	return scope, nil
+}
+
+// authenticateClient authenticates a client using either HTTP Basic or form parameters.
+// Clients with no secret are public clients and only identified by their ids.
+func (m OIDCMock) authenticateClient(r *http.Request) (*OIDCClient, *oauthError) {
+	id, secret, basic := r.BasicAuth()
+	if !basic {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	unauthorized := &oauthError{
+		statusCode:  http.StatusUnauthorized,
+		code:        "invalid_client",
+		description: "client authentication failed",
+	}
+
+	c := m.client(id)
+	if c == nil {
+		return nil, unauthorized
+	}
+
+	if c.Secret != "" && subtle.ConstantTimeCompare([]byte(c.Secret), []byte(secret)) != 1 {
+		return nil, unauthorized
+	}
+
+	return c, nil
+}
+
+// verifyChallenge verifies a PKCE code verifier against its code challenge (RFC 7636).
+func verifyChallenge(challenge, method, verifier string) bool {
+	if method == "S256" {
+		sum := sha256.Sum256([]byte(verifier))
+		verifier = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(verifier)) == 1
+}
+
+// tokens issues an access token, and if there is a user, a refresh token and an id token.
+func (m OIDCMock) tokens(r *http.Request, g oidcGrant) (JSON, error) {
+	c := m.client(g.clientID)
+	u := m.user(g.username)
+	now := time.Now()
+	issuer := m.issuer(r)
+
+	audience := c.Audience
+	if audience == "" {
+		audience = c.ID
+	}
+
+	subject := c.ID
+	if u != nil {
+		subject = u.Username
+	}
+
+	claims := JSON{}
+	for key, val := range c.Claims {
+		claims[key] = val
+	}
+
+	if u != nil {
+		for key, val := range u.Claims {
+			claims[key] = val
+		}
+	}
+
+	claims["iss"] = issuer
+	claims["sub"] = subject
+	claims["aud"] = audience
+	claims["client_id"] = c.ID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(m.provider.ttl).Unix()
+	claims["jti"] = randomToken()
+	if len(g.scope) > 0 {
+		claims["scope"] = strings.Join(g.scope, " ")
+	}
+
+	accessToken, err := m.provider.signer.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	res := JSON{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(m.provider.ttl.Seconds()),
+	}
+
+	if len(g.scope) > 0 {
+		res["scope"] = strings.Join(g.scope, " ")
+	}
+
+	if u == nil {
+		return res, nil
+	}
+
+	res["refresh_token"] = m.provider.issueRefreshToken(g)
+
+	if containsString(g.scope, "openid") {
+		idClaims := JSON{}
+		for key, val := range u.Claims {
+			idClaims[key] = val
+		}
+
+		idClaims["iss"] = issuer
+		idClaims["sub"] = u.Username
+		idClaims["aud"] = c.ID
+		idClaims["iat"] = now.Unix()
+		idClaims["exp"] = now.Add(m.provider.ttl).Unix()
+		idClaims["auth_time"] = now.Unix()
+		if g.nonce != "" {
+			idClaims["nonce"] = g.nonce
+		}
+
+		idToken, err := m.provider.signer.Sign(idClaims)
+		if err != nil {
+			return nil, err
+		}
+
+		res["id_token"] = idToken
+	}
+
+	return res, nil
+}
+
+func (m OIDCMock) discovery(w http.ResponseWriter, r *http.Request) {
+	base := m.baseURL(r)
+
+	writeJSON(w, http.StatusOK, JSON{
+		"issuer":                                m.issuer(r),
+		"authorization_endpoint":                base + oidcAuthorizePath,
+		"token_endpoint":                        base + oidcTokenPath,
+		"userinfo_endpoint":                     base + oidcUserinfoPath,
+		"jwks_uri":                              base + oidcJWKSPath,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "client_credentials", "password", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{m.provider.signer.alg},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"plain", "S256"},
+	})
+}
+
+func (m OIDCMock) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, JSON{
+		"keys": []jsonWebKey{m.provider.signer.jwk()},
+	})
+}
+
+// authorize approves authorization requests with no user interaction.
+// The user is selected by the login_hint parameter, or the first user is used.
+func (m OIDCMock) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	c := m.client(q.Get("client_id"))
+	if c == nil {
+		writeOAuthError(w, invalidRequest("invalid_request", "unknown client: %s", q.Get("client_id")))
+		return
+	}
+
+	redirectURI := q.Get("redirect_uri")
+	if redirectURI == "" && len(c.RedirectURIs) == 1 {
+		redirectURI = c.RedirectURIs[0]
+	}
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil || !redirect.IsAbs() || (len(c.RedirectURIs) > 0 && !containsString(c.RedirectURIs, redirectURI)) {
+		writeOAuthError(w, invalidRequest("invalid_request", "invalid redirect uri: %s", redirectURI))
+		return
+	}
+
+	// Errors after validating the redirect uri are sent to the client.
+	respond := func(params url.Values) {
+		if state := q.Get("state"); state != "" {
+			params.Set("state", state)
+		}
+
+		query := redirect.Query()
+		for key, vals := range params {
+			query[key] = vals
+		}
+		redirect.RawQuery = query.Encode()
+
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	}
+
+	fail := func(err *oauthError) {
+		respond(url.Values{"error": {err.code}, "error_description": {err.description}})
+	}
+
+	if q.Get("response_type") != "code" {
+		fail(invalidRequest("unsupported_response_type", "unsupported response type: %s", q.Get("response_type")))
+		return
+	}
+
+	scope, oerr := c.scopes(q.Get("scope"))
+	if oerr != nil {
+		fail(oerr)
+		return
+	}
+
+	challenge, method := q.Get("code_challenge"), q.Get("code_challenge_method")
+	if method == "" {
+		method = "plain"
+	}
+
+	if method != "plain" && method != "S256" {
+		fail(invalidRequest("invalid_request", "unsupported code challenge method: %s", method))
+		return
+	}
+
+	if challenge == "" && c.Secret == "" {
+		fail(invalidRequest("invalid_request", "code challenge is required for public clients"))
+		return
+	}
+
+	var u *OIDCUser
+	if hint := q.Get("login_hint"); hint != "" {
+		u = m.user(hint)
+	} else if len(m.Users) > 0 {
+		u = &m.Users[0]
+	}
+
+	if u == nil {
+		fail(invalidRequest("access_denied", "no user to authorize"))
+		return
+	}
+
+	code := m.provider.issueCode(oidcGrant{
+		clientID:    c.ID,
+		username:    u.Username,
+		scope:       scope,
+		nonce:       q.Get("nonce"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   challenge,
+		method:      method,
+	})
+
+	respond(url.Values{"code": {code}})
+}
+
+func (m OIDCMock) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, invalidRequest("invalid_request", "invalid form: %s", err))
+		return
+	}
+
+	c, oerr := m.authenticateClient(r)
+	if oerr != nil {
+		writeOAuthError(w, oerr)
+		return
+	}
+
+	var g oidcGrant
+
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case "client_credentials":
+		if c.Secret == "" {
+			writeOAuthError(w, invalidRequest("unauthorized_client", "public clients cannot use client credentials"))
+			return
+		}
+
+		scope, oerr := c.scopes(r.PostForm.Get("scope"))
+		if oerr != nil {
+			writeOAuthError(w, oerr)
+			return
+		}
+
+		g = oidcGrant{clientID: c.ID, scope: scope}
+
+	case "password":
+		u := m.user(r.PostForm.Get("username"))
+		if u == nil || subtle.ConstantTimeCompare([]byte(u.Password), []byte(r.PostForm.Get("password"))) != 1 {
+			writeOAuthError(w, invalidRequest("invalid_grant", "invalid username or password"))
+			return
+		}
+
+		scope, oerr := c.scopes(r.PostForm.Get("scope"))
+		if oerr != nil {
+			writeOAuthError(w, oerr)
+			return
+		}
+
+		g = oidcGrant{clientID: c.ID, username: u.Username, scope: scope}
+
+	case "authorization_code":
+		var ok bool
+		g, ok = m.provider.redeemCode(r.PostForm.Get("code"))
+		if !ok || g.clientID != c.ID || g.redirectURI != r.PostForm.Get("redirect_uri") {
+			writeOAuthError(w, invalidRequest("invalid_grant", "invalid authorization code"))
+			return
+		}
+
+		if g.challenge != "" && !verifyChallenge(g.challenge, g.method, r.PostForm.Get("code_verifier")) {
+			writeOAuthError(w, invalidRequest("invalid_grant", "invalid code verifier"))
+			return
+		}
+
+	case "refresh_token":
+		var ok bool
+		g, ok = m.provider.redeemRefreshToken(r.PostForm.Get("refresh_token"))
+		if !ok || g.clientID != c.ID {
+			writeOAuthError(w, invalidRequest("invalid_grant", "invalid refresh token"))
+			return
+		}
+
+		// The scope of a refreshed token can only be narrowed.
+		if requested := strings.Fields(r.PostForm.Get("scope")); len(requested) > 0 {
+			for _, s := range requested {
+				if !containsString(g.scope, s) {
+					writeOAuthError(w, invalidRequest("invalid_scope", "scope %s was not granted", s))
+					return
+				}
+			}
+			g.scope = requested
+		}
+
+	default:
+		writeOAuthError(w, invalidRequest("unsupported_grant_type", "unsupported grant type: %s", grantType))
+		return
+	}
+
+	res, err := m.tokens(r, g)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, JSON{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (m OIDCMock) userinfo(w http.ResponseWriter, r *http.Request) {
+	invalid := func(description string) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q, error="invalid_token", error_description=%q`, defaultRealm, description))
+		writeJSON(w, http.StatusUnauthorized, JSON{
+			"error":             "invalid_token",
+			"error_description": description,
+		})
+	}
+
+	token, ok := bearerToken(r.Header.Get("Authorization"))
+	if !ok {
+		invalid("missing access token")
+		return
+	}
+
+	claims, err := parseJWT(token, nil, m.provider.signer.keys(), time.Now())
+	if err != nil {
+		invalid(err.Error())
+		return
+	}
+
+	sub, _ := claims["sub"].(string)
+	u := m.user(sub)
+	if u == nil {
+		invalid("access token has no user")
+		return
+	}
+
+	res := JSON{}
+	for key, val := range u.Claims {
+		res[key] = val
+	}
+	res["sub"] = u.Username
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// wrap wraps a handler of the mock with chaos and rate limit.
+func (m *OIDCMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(nil, m.chaos, m.RateLimit, nil, h)
+}
+
+// RegisterRoutes configure routes for an oidc mock.
+// The signing key is created when routes are registered for the first time.
+func (m *OIDCMock) RegisterRoutes(router *mux.Router) {
+	var err error
+	if m.provider == nil {
+		m.provider, err = newOIDCProvider(*m)
+	}
+
+	handle := func(methods []string, p string, f http.HandlerFunc) {
+		route := router.Methods(methods...).Path(m.endpoint(p))
+		route.MatcherFunc(matchListener(m.Listener))
+
+		if m.Host != "" {
+			route.MatcherFunc(hostMatcher(m.Host))
+		}
+
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, JSON{
+					"message": err.Error(),
+				})
+				return
+			}
+
+			f(w, r)
+		})))
+	}
+
+	handle([]string{"GET"}, oidcDiscoveryPath, m.discovery)
+	handle([]string{"GET"}, oidcJWKSPath, m.jwks)
+	handle([]string{"GET"}, oidcAuthorizePath, m.authorize)
+	handle([]string{"POST"}, oidcTokenPath, m.token)
+	handle([]string{"GET", "POST"}, oidcUserinfoPath, m.userinfo)
+}
@@ -0,0 +1,496 @@
+package spec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var oidcMock = OIDCMock{
+	BasePath: "/auth",
+	TokenTTL: "1h",
+	Clients: []OIDCClient{
+		{
+			ID:       "backend",
+			Secret:   "secret",
+			Scopes:   []string{"openid", "teams:read", "teams:write"},
+			Audience: "teams",
+			Claims:   JSON{"tenant": "acme"},
+		},
+		{
+			ID:           "spa",
+			RedirectURIs: []string{"http://localhost:3000/callback"},
+		},
+	},
+	Users: []OIDCUser{
+		{
+			Username: "jane",
+			Password: "pass",
+			Claims:   JSON{"email": "jane@example.com"},
+		},
+		{
+			Username: "john",
+			Password: "pass",
+		},
+	},
+}
+
+func TestOIDCMockSetDefaults(t *testing.T) {
+	m := OIDCMock{BasePath: "auth/"}
+	m.SetDefaults()
+
+	assert.Equal(t, OIDCMock{BasePath: "/auth", TokenTTL: "1h"}, m)
+}
+
+func TestOIDCMockInherit(t *testing.T) {
+	rateLimit := &RateLimit{Limit: 10, Window: "1m"}
+	chaos := &Chaos{Tags: []string{"flaky"}}
+
+	m := OIDCMock{Tags: []string{"flaky"}}
+	m.Inherit(Config{
+		Auth:      &Auth{Bearer: []string{"secret"}},
+		RateLimit: rateLimit,
+		Chaos:     chaos,
+	})
+
+	assert.Equal(t, OIDCMock{RateLimit: rateLimit, Tags: []string{"flaky"}, chaos: chaos}, m)
+}
+
+func TestOIDCMockValidate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+
+	tests := []struct {
+		name          string
+		mock          OIDCMock
+		expectedError string
+	}{
+		{
+			name:          "InvalidTokenTTL",
+			mock:          OIDCMock{TokenTTL: "forever"},
+			expectedError: "invalid token ttl: forever",
+		},
+		{
+			name:          "MissingKeyFile",
+			mock:          OIDCMock{TokenTTL: "1h", KeyFile: "missing.pem"},
+			expectedError: "open missing.pem: no such file or directory",
+		},
+		{
+			name:          "NoClientID",
+			mock:          OIDCMock{TokenTTL: "1h", Clients: []OIDCClient{{Secret: "secret"}}},
+			expectedError: "client id is required",
+		},
+		{
+			name:          "DuplicateClient",
+			mock:          OIDCMock{TokenTTL: "1h", Clients: []OIDCClient{{ID: "web"}, {ID: "web"}}},
+			expectedError: "client web is defined more than once",
+		},
+		{
+			name:          "NoUsername",
+			mock:          OIDCMock{TokenTTL: "1h", Users: []OIDCUser{{Password: "pass"}}},
+			expectedError: "username is required",
+		},
+		{
+			name:          "OK",
+			mock:          OIDCMock{TokenTTL: "1h", KeyFile: keyFile, Clients: oidcMock.Clients, Users: oidcMock.Users},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mock.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOIDCMockString(t *testing.T) {
+	assert.Equal(t, "OIDC /auth", oidcMock.String())
+	assert.Equal(t, "identity: OIDC auth.local/", OIDCMock{Listener: "identity", Host: "auth.local", BasePath: "/"}.String())
+}
+
+// oidcClient sends requests to an oidc mock for testing.
+type oidcClient struct {
+	t      *testing.T
+	router *mux.Router
+}
+
+func newOIDCClient(t *testing.T, m *OIDCMock) *oidcClient {
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+
+	return &oidcClient{
+		t:      t,
+		router: router,
+	}
+}
+
+func (c *oidcClient) do(req *http.Request) (*http.Response, JSON) {
+	res := httptest.NewRecorder()
+	c.router.ServeHTTP(res, req)
+
+	body := JSON{}
+	if res.Result().Header.Get("Content-Type") == "application/json" {
+		assert.NoError(c.t, json.NewDecoder(res.Body).Decode(&body))
+	}
+
+	return res.Result(), body
+}
+
+func (c *oidcClient) get(url, token string) (*http.Response, JSON) {
+	req := httptest.NewRequest("GET", url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.do(req)
+}
+
+func (c *oidcClient) token(clientID, clientSecret string, form url.Values) (*http.Response, JSON) {
+	req := httptest.NewRequest("POST", "http://auth.example.com/auth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if clientSecret != "" {
+		req.SetBasicAuth(clientID, clientSecret)
+	}
+
+	return c.do(req)
+}
+
+func (c *oidcClient) claims(token string, keys jwks) JSON {
+	claims, err := parseJWT(token, nil, keys, time.Now())
+	assert.NoError(c.t, err)
+
+	return claims
+}
+
+func TestOIDCMockDiscovery(t *testing.T) {
+	m := oidcMock
+	client := newOIDCClient(t, &m)
+
+	res, body := client.get("http://auth.example.com/auth/.well-known/openid-configuration", "")
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "http://auth.example.com/auth", body["issuer"])
+	assert.Equal(t, "http://auth.example.com/auth/authorize", body["authorization_endpoint"])
+	assert.Equal(t, "http://auth.example.com/auth/token", body["token_endpoint"])
+	assert.Equal(t, "http://auth.example.com/auth/userinfo", body["userinfo_endpoint"])
+	assert.Equal(t, "http://auth.example.com/auth/.well-known/jwks.json", body["jwks_uri"])
+	assert.Equal(t, []interface{}{"RS256"}, body["id_token_signing_alg_values_supported"])
+
+	res, body = client.get("http://auth.example.com/auth/.well-known/jwks.json", "")
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Len(t, body["keys"], 1)
+
+	key := body["keys"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "RSA", key["kty"])
+	assert.Equal(t, "RS256", key["alg"])
+	assert.Equal(t, m.provider.signer.kid, key["kid"])
+}
+
+func TestOIDCMockRateLimit(t *testing.T) {
+	m := oidcMock
+	m.Inherit(Config{RateLimit: &RateLimit{Limit: 1, Window: "1m"}})
+	client := newOIDCClient(t, &m)
+
+	res, _ := client.get("http://auth.example.com/auth/.well-known/openid-configuration", "")
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, body := client.get("http://auth.example.com/auth/.well-known/jwks.json", "")
+	assert.Equal(t, 429, res.StatusCode)
+	assert.Equal(t, "rate limit exceeded", body["message"])
+}
+
+func TestOIDCMockToken(t *testing.T) {
+	m := oidcMock
+	m.Issuer = "https://auth.example.com"
+	client := newOIDCClient(t, &m)
+	keys := m.provider.signer.keys()
+
+	tests := []struct {
+		name               string
+		clientID           string
+		clientSecret       string
+		form               url.Values
+		expectedStatusCode int
+		expectedError      string
+		expectedClaims     JSON
+		expectedRefresh    bool
+		expectedIDToken    bool
+	}{
+		{
+			name:               "InvalidClient",
+			clientID:           "backend",
+			clientSecret:       "wrong",
+			form:               url.Values{"grant_type": {"client_credentials"}},
+			expectedStatusCode: 401,
+			expectedError:      "invalid_client",
+		},
+		{
+			name:               "UnsupportedGrantType",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"implicit"}},
+			expectedStatusCode: 400,
+			expectedError:      "unsupported_grant_type",
+		},
+		{
+			name:               "ClientCredentialsPublicClient",
+			form:               url.Values{"grant_type": {"client_credentials"}, "client_id": {"spa"}},
+			expectedStatusCode: 400,
+			expectedError:      "unauthorized_client",
+		},
+		{
+			name:               "ClientCredentialsInvalidScope",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"client_credentials"}, "scope": {"admin"}},
+			expectedStatusCode: 400,
+			expectedError:      "invalid_scope",
+		},
+		{
+			name:               "ClientCredentials",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"client_credentials"}, "scope": {"teams:read"}},
+			expectedStatusCode: 200,
+			expectedClaims: JSON{
+				"iss":       "https://auth.example.com",
+				"sub":       "backend",
+				"aud":       "teams",
+				"client_id": "backend",
+				"scope":     "teams:read",
+				"tenant":    "acme",
+			},
+		},
+		{
+			name:               "PasswordInvalid",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"password"}, "username": {"jane"}, "password": {"wrong"}},
+			expectedStatusCode: 400,
+			expectedError:      "invalid_grant",
+		},
+		{
+			name:               "Password",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"password"}, "username": {"jane"}, "password": {"pass"}, "scope": {"openid teams:read"}},
+			expectedStatusCode: 200,
+			expectedClaims: JSON{
+				"iss":       "https://auth.example.com",
+				"sub":       "jane",
+				"aud":       "teams",
+				"client_id": "backend",
+				"scope":     "openid teams:read",
+				"tenant":    "acme",
+				"email":     "jane@example.com",
+			},
+			expectedRefresh: true,
+			expectedIDToken: true,
+		},
+		{
+			name:               "PasswordClientSecretPost",
+			form:               url.Values{"grant_type": {"password"}, "client_id": {"backend"}, "client_secret": {"secret"}, "username": {"john"}, "password": {"pass"}},
+			expectedStatusCode: 200,
+			expectedClaims: JSON{
+				"iss":       "https://auth.example.com",
+				"sub":       "john",
+				"aud":       "teams",
+				"client_id": "backend",
+				"scope":     "openid teams:read teams:write",
+				"tenant":    "acme",
+			},
+			expectedRefresh: true,
+			expectedIDToken: true,
+		},
+		{
+			name:               "RefreshTokenInvalid",
+			clientID:           "backend",
+			clientSecret:       "secret",
+			form:               url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"invalid"}},
+			expectedStatusCode: 400,
+			expectedError:      "invalid_grant",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, body := client.token(tc.clientID, tc.clientSecret, tc.form)
+
+			assert.Equal(t, tc.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+			if tc.expectedError != "" {
+				assert.Equal(t, tc.expectedError, body["error"])
+				return
+			}
+
+			assert.Equal(t, "Bearer", body["token_type"])
+			assert.Equal(t, float64(3600), body["expires_in"])
+
+			claims := client.claims(body["access_token"].(string), keys)
+			for key, val := range tc.expectedClaims {
+				assert.Equal(t, val, claims[key], key)
+			}
+
+			_, ok := body["refresh_token"]
+			assert.Equal(t, tc.expectedRefresh, ok)
+
+			_, ok = body["id_token"]
+			assert.Equal(t, tc.expectedIDToken, ok)
+		})
+	}
+}
+
+func TestOIDCMockAuthorizationCode(t *testing.T) {
+	m := oidcMock
+	client := newOIDCClient(t, &m)
+	keys := m.provider.signer.keys()
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	authorize := func(params url.Values) url.Values {
+		res, _ := client.get("http://auth.example.com/auth/authorize?"+params.Encode(), "")
+		assert.Equal(t, 302, res.StatusCode)
+
+		location, err := url.Parse(res.Header.Get("Location"))
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost:3000", location.Host)
+
+		return location.Query()
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {"http://localhost:3000/callback"},
+		"scope":                 {"openid profile"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6"},
+		"login_hint":            {"jane"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	t.Run("UnknownClient", func(t *testing.T) {
+		res, body := client.get("http://auth.example.com/auth/authorize?client_id=unknown", "")
+		assert.Equal(t, 400, res.StatusCode)
+		assert.Equal(t, "invalid_request", body["error"])
+	})
+
+	t.Run("InvalidRedirectURI", func(t *testing.T) {
+		res, body := client.get("http://auth.example.com/auth/authorize?client_id=spa&redirect_uri=http://evil.com", "")
+		assert.Equal(t, 400, res.StatusCode)
+		assert.Equal(t, "invalid_request", body["error"])
+	})
+
+	t.Run("MissingChallenge", func(t *testing.T) {
+		query := authorize(url.Values{
+			"response_type": {"code"},
+			"client_id":     {"spa"},
+			"state":         {"xyz"},
+		})
+
+		assert.Equal(t, "invalid_request", query.Get("error"))
+		assert.Equal(t, "xyz", query.Get("state"))
+	})
+
+	t.Run("InvalidVerifier", func(t *testing.T) {
+		query := authorize(params)
+		assert.Equal(t, "xyz", query.Get("state"))
+
+		res, body := client.token("", "", url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {"spa"},
+			"code":          {query.Get("code")},
+			"redirect_uri":  {"http://localhost:3000/callback"},
+			"code_verifier": {"wrong"},
+		})
+
+		assert.Equal(t, 400, res.StatusCode)
+		assert.Equal(t, "invalid_grant", body["error"])
+	})
+
+	t.Run("Flow", func(t *testing.T) {
+		query := authorize(params)
+
+		form := url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {"spa"},
+			"code":          {query.Get("code")},
+			"redirect_uri":  {"http://localhost:3000/callback"},
+			"code_verifier": {verifier},
+		}
+
+		res, body := client.token("", "", form)
+		assert.Equal(t, 200, res.StatusCode)
+
+		accessToken := body["access_token"].(string)
+		refreshToken := body["refresh_token"].(string)
+
+		idClaims := client.claims(body["id_token"].(string), keys)
+		assert.Equal(t, "jane", idClaims["sub"])
+		assert.Equal(t, "spa", idClaims["aud"])
+		assert.Equal(t, "n-0S6", idClaims["nonce"])
+		assert.Equal(t, "jane@example.com", idClaims["email"])
+
+		// A code can only be redeemed once.
+		res, body = client.token("", "", form)
+		assert.Equal(t, 400, res.StatusCode)
+		assert.Equal(t, "invalid_grant", body["error"])
+
+		res, body = client.get("http://auth.example.com/auth/userinfo", accessToken)
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, JSON{"sub": "jane", "email": "jane@example.com"}, body)
+
+		res, body = client.get("http://auth.example.com/auth/userinfo", "invalid")
+		assert.Equal(t, 401, res.StatusCode)
+		assert.Equal(t, "invalid_token", body["error"])
+
+		// Refresh tokens are rotated.
+		refresh := url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {"spa"},
+			"refresh_token": {refreshToken},
+			"scope":         {"openid"},
+		}
+
+		res, body = client.token("", "", refresh)
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, "openid", body["scope"])
+		assert.NotEqual(t, refreshToken, body["refresh_token"])
+
+		res, body = client.token("", "", refresh)
+		assert.Equal(t, 400, res.StatusCode)
+		assert.Equal(t, "invalid_grant", body["error"])
+	})
+}
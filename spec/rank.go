@@ -0,0 +1,29 @@
+package spec
+
+// Rank determines the order in which overlapping mocks are tried.
+// Mocks with higher priorities are tried first, and then more specific mocks are tried first.
+type Rank struct {
+	Priority    int
+	Specificity int
+}
+
+// Less determines whether or not a mock with this rank is tried after a mock with another rank.
+func (r Rank) Less(o Rank) bool {
+	if r.Priority != o.Priority {
+		return r.Priority < o.Priority
+	}
+
+	return r.Specificity < o.Specificity
+}
+
+// specificity calculates how specific a set of matching criteria is.
+// An exact path is more specific than any path prefix, a longer path is more specific than a shorter one,
+// and every query, header, or other criterion makes a mock more specific.
+func specificity(exact bool, path string, criteria int) int {
+	s := len(path)*100 + criteria
+	if exact {
+		s += 1000000
+	}
+
+	return s
+}
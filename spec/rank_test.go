@@ -0,0 +1,91 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankLess(t *testing.T) {
+	tests := []struct {
+		name         string
+		r1           Rank
+		r2           Rank
+		expectedLess bool
+	}{
+		{"Equal", Rank{Priority: 1, Specificity: 10}, Rank{Priority: 1, Specificity: 10}, false},
+		{"LowerPriority", Rank{Priority: 0, Specificity: 100}, Rank{Priority: 1, Specificity: 10}, true},
+		{"HigherPriority", Rank{Priority: 1, Specificity: 10}, Rank{Priority: 0, Specificity: 100}, false},
+		{"LessSpecific", Rank{Priority: 1, Specificity: 10}, Rank{Priority: 1, Specificity: 100}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedLess, tc.r1.Less(tc.r2))
+		})
+	}
+}
+
+func TestHTTPMockRank(t *testing.T) {
+	tests := []struct {
+		name         string
+		m1           HTTPMock
+		m2           HTTPMock
+		expectedLess bool
+	}{
+		{
+			name:         "PrefixAndExact",
+			m1:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/api/v1/teams", Prefix: true}},
+			m2:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/"}},
+			expectedLess: true,
+		},
+		{
+			name:         "ShorterPrefix",
+			m1:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/api", Prefix: true}},
+			m2:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/api/v1", Prefix: true}},
+			expectedLess: true,
+		},
+		{
+			name: "FewerCriteria",
+			m1:   HTTPMock{HTTPExpect: HTTPExpect{Path: "/api/v1/teams"}},
+			m2: HTTPMock{HTTPExpect: HTTPExpect{
+				Path:    "/api/v1/teams",
+				Headers: map[string]string{"Authorization": "Bearer .*"},
+			}},
+			expectedLess: true,
+		},
+		{
+			name: "MoreMethods",
+			m1: HTTPMock{HTTPExpect: HTTPExpect{
+				Methods: []string{"GET", "POST", "PUT"},
+				Path:    "/x",
+			}},
+			m2: HTTPMock{HTTPExpect: HTTPExpect{
+				Methods: []string{"GET"},
+				Path:    "/x",
+				Headers: map[string]string{"Accept": "json"},
+			}},
+			expectedLess: true,
+		},
+		{
+			name:         "Priority",
+			m1:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/api/v1/teams"}},
+			m2:           HTTPMock{HTTPExpect: HTTPExpect{Path: "/", Prefix: true}, Priority: 1},
+			expectedLess: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedLess, tc.m1.Rank().Less(tc.m2.Rank()))
+			assert.False(t, tc.m2.Rank().Less(tc.m1.Rank()))
+		})
+	}
+}
+
+func TestRESTMockRank(t *testing.T) {
+	rest := RESTMock{RESTExpect: RESTExpect{BasePath: "/api/v1/teams"}}
+	prefix := HTTPMock{HTTPExpect: HTTPExpect{Path: "/api", Prefix: true}}
+
+	assert.True(t, prefix.Rank().Less(rest.Rank()))
+}
@@ -0,0 +1,215 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// StrategyFixedWindow allows a number of requests in every window of time.
+	StrategyFixedWindow = "fixed_window"
+	// StrategyTokenBucket allows bursts of requests up to a limit and refills at a constant rate.
+	StrategyTokenBucket = "token_bucket"
+
+	// KeyIP limits requests per client ip address.
+	KeyIP = "ip"
+	// KeyHeader limits requests per value of a header (i.e. an api key).
+	KeyHeader = "header"
+	// KeyQuery limits requests per value of a query parameter (i.e. an api key).
+	KeyQuery = "query"
+)
+
+// rateLimitState keeps the requests counted for every client key.
+type rateLimitState struct {
+	mutex   sync.Mutex
+	windows map[string]*rateWindow
+	buckets map[string]*rateBucket
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit represents a rate limit for requests per client.
+// Limit is the number of requests allowed per window of time.
+// For the token bucket strategy, Limit is also the size of bursts.
+type RateLimit struct {
+	Strategy string `json:"strategy" yaml:"strategy"`
+	Limit    int    `json:"limit" yaml:"limit"`
+	Window   string `json:"window" yaml:"window"`
+	Key      string `json:"key" yaml:"key"`
+	KeyName  string `json:"keyName" yaml:"key_name"`
+
+	state *rateLimitState
+}
+
+// SetDefaults set default values for empty fields.
+// It also creates the state shared by all mocks using the rate limit.
+// If r is nil, nothing is set.
+func (r *RateLimit) SetDefaults() {
+	if r == nil {
+		return
+	}
+
+	if r.Strategy == "" {
+		r.Strategy = StrategyFixedWindow
+	}
+
+	if r.Window == "" {
+		r.Window = "1s"
+	}
+
+	if r.Key == "" {
+		r.Key = KeyIP
+	}
+
+	if r.state == nil {
+		r.state = &rateLimitState{
+			windows: map[string]*rateWindow{},
+			buckets: map[string]*rateBucket{},
+		}
+	}
+}
+
+// Validate checks whether or not the rate limit is valid.
+// If r is nil, it is valid.
+func (r *RateLimit) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.Strategy != StrategyFixedWindow && r.Strategy != StrategyTokenBucket {
+		return fmt.Errorf("unknown rate limit strategy: %s", r.Strategy)
+	}
+
+	if r.Limit <= 0 {
+		return errors.New("rate limit should be positive")
+	}
+
+	if window, err := time.ParseDuration(r.Window); err != nil || window <= 0 {
+		return fmt.Errorf("invalid rate limit window: %s", r.Window)
+	}
+
+	switch r.Key {
+	case KeyIP:
+	case KeyHeader, KeyQuery:
+		if r.KeyName == "" {
+			return fmt.Errorf("rate limit key name is required for %s keys", r.Key)
+		}
+	default:
+		return fmt.Errorf("unknown rate limit key: %s", r.Key)
+	}
+
+	return nil
+}
+
+// clientKey returns the key identifying the client of a request.
+func (r *RateLimit) clientKey(req *http.Request) string {
+	switch r.Key {
+	case KeyHeader:
+		return req.Header.Get(r.KeyName)
+	case KeyQuery:
+		return req.URL.Query().Get(r.KeyName)
+	default:
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			return req.RemoteAddr
+		}
+		return host
+	}
+}
+
+// take counts a request for a client key at a given time.
+// It returns whether or not the request is allowed, the number of remaining requests,
+// and the time until the limit resets or another request is allowed.
+func (r *RateLimit) take(key string, window time.Duration, now time.Time) (bool, int, time.Duration) {
+	s := r.state
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Strategy == StrategyTokenBucket {
+		rate := float64(r.Limit) / window.Seconds()
+
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &rateBucket{tokens: float64(r.Limit), last: now}
+			s.buckets[key] = b
+		}
+
+		b.tokens = math.Min(float64(r.Limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+			return false, 0, wait
+		}
+
+		b.tokens--
+		full := time.Duration((float64(r.Limit) - b.tokens) / rate * float64(time.Second))
+		return true, int(b.tokens), full
+	}
+
+	w, ok := s.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		s.windows[key] = w
+	}
+
+	reset := w.start.Add(window).Sub(now)
+
+	if w.count >= r.Limit {
+		return false, 0, reset
+	}
+
+	w.count++
+	return true, r.Limit - w.count, reset
+}
+
+// seconds rounds a duration up to whole seconds.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Handler wraps an http handler for rejecting requests exceeding the rate limit with 429.
+// All responses have X-RateLimit-Limit, X-RateLimit-Remaining, and X-RateLimit-Reset headers,
+// and rejected requests have a Retry-After header.
+// If r is nil, the handler is returned unchanged.
+func (r *RateLimit) Handler(h http.Handler) http.Handler {
+	if r == nil {
+		return h
+	}
+
+	// Rate limits not read from a spec have no state yet.
+	r.SetDefaults()
+	window, _ := time.ParseDuration(r.Window)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		allowed, remaining, reset := r.take(r.clientKey(req), window, time.Now())
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", seconds(reset))
+
+		if !allowed {
+			w.Header().Set("Retry-After", seconds(reset))
+			writeJSON(w, http.StatusTooManyRequests, JSON{
+				"message": "rate limit exceeded",
+			})
+			return
+		}
+
+		h.ServeHTTP(w, req)
+	})
+}
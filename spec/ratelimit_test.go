@@ -0,0 +1,255 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitSetDefaults(t *testing.T) {
+	var nilLimit *RateLimit
+	nilLimit.SetDefaults()
+	assert.Nil(t, nilLimit)
+
+	r := &RateLimit{Limit: 10}
+	r.SetDefaults()
+
+	assert.Equal(t, StrategyFixedWindow, r.Strategy)
+	assert.Equal(t, "1s", r.Window)
+	assert.Equal(t, KeyIP, r.Key)
+	assert.NotNil(t, r.state)
+}
+
+func TestRateLimitValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		rateLimit     *RateLimit
+		expectedError string
+	}{
+		{
+			name:          "Nil",
+			rateLimit:     nil,
+			expectedError: "",
+		},
+		{
+			name:          "UnknownStrategy",
+			rateLimit:     &RateLimit{Strategy: "sliding_log", Limit: 10, Window: "1s", Key: KeyIP},
+			expectedError: "unknown rate limit strategy: sliding_log",
+		},
+		{
+			name:          "NoLimit",
+			rateLimit:     &RateLimit{Strategy: StrategyFixedWindow, Window: "1s", Key: KeyIP},
+			expectedError: "rate limit should be positive",
+		},
+		{
+			name:          "InvalidWindow",
+			rateLimit:     &RateLimit{Strategy: StrategyFixedWindow, Limit: 10, Window: "1", Key: KeyIP},
+			expectedError: "invalid rate limit window: 1",
+		},
+		{
+			name:          "UnknownKey",
+			rateLimit:     &RateLimit{Strategy: StrategyFixedWindow, Limit: 10, Window: "1s", Key: "user"},
+			expectedError: "unknown rate limit key: user",
+		},
+		{
+			name:          "NoKeyName",
+			rateLimit:     &RateLimit{Strategy: StrategyFixedWindow, Limit: 10, Window: "1s", Key: KeyHeader},
+			expectedError: "rate limit key name is required for header keys",
+		},
+		{
+			name:          "OK",
+			rateLimit:     &RateLimit{Strategy: StrategyTokenBucket, Limit: 10, Window: "1m", Key: KeyQuery, KeyName: "api_key"},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rateLimit.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRateLimitTake(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type take struct {
+		key               string
+		after             time.Duration
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedReset     time.Duration
+	}
+
+	tests := []struct {
+		name      string
+		rateLimit *RateLimit
+		takes     []take
+	}{
+		{
+			name:      "FixedWindow",
+			rateLimit: &RateLimit{Strategy: StrategyFixedWindow, Limit: 2, Window: "1m"},
+			takes: []take{
+				{"a", 0, true, 1, time.Minute},
+				{"a", 10 * time.Second, true, 0, 50 * time.Second},
+				{"a", 20 * time.Second, false, 0, 40 * time.Second},
+				{"b", 20 * time.Second, true, 1, time.Minute},
+				{"a", time.Minute, true, 1, time.Minute},
+			},
+		},
+		{
+			name:      "TokenBucket",
+			rateLimit: &RateLimit{Strategy: StrategyTokenBucket, Limit: 2, Window: "1m"},
+			takes: []take{
+				{"a", 0, true, 1, 30 * time.Second},
+				{"a", 0, true, 0, time.Minute},
+				{"a", 10 * time.Second, false, 0, 20 * time.Second},
+				{"a", 30 * time.Second, true, 0, time.Minute},
+				{"a", 2 * time.Minute, true, 1, 30 * time.Second},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.rateLimit.SetDefaults()
+			window, _ := time.ParseDuration(tc.rateLimit.Window)
+
+			for i, take := range tc.takes {
+				allowed, remaining, reset := tc.rateLimit.take(take.key, window, start.Add(take.after))
+
+				assert.Equal(t, take.expectedAllowed, allowed, "take %d", i)
+				assert.Equal(t, take.expectedRemaining, remaining, "take %d", i)
+				assert.Equal(t, take.expectedReset, reset, "take %d", i)
+			}
+		})
+	}
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		rateLimit          *RateLimit
+		requests           []*http.Request
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+	}{
+		{
+			name:               "Nil",
+			rateLimit:          nil,
+			requests:           []*http.Request{httptest.NewRequest("GET", "/", nil)},
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{},
+		},
+		{
+			name:               "Allowed",
+			rateLimit:          &RateLimit{Limit: 2, Window: "1m"},
+			requests:           []*http.Request{httptest.NewRequest("GET", "/", nil)},
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit":     "2",
+				"X-RateLimit-Remaining": "1",
+				"X-RateLimit-Reset":     "60",
+			},
+		},
+		{
+			name:      "ExceededByIP",
+			rateLimit: &RateLimit{Limit: 1, Window: "1m"},
+			requests: []*http.Request{
+				httptest.NewRequest("GET", "/", nil),
+				httptest.NewRequest("GET", "/", nil),
+			},
+			expectedStatusCode: 429,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit":     "1",
+				"X-RateLimit-Remaining": "0",
+				"Retry-After":           "60",
+			},
+		},
+		{
+			name:      "AllowedByHeader",
+			rateLimit: &RateLimit{Limit: 1, Window: "1m", Key: KeyHeader, KeyName: "X-API-Key"},
+			requests: func() []*http.Request {
+				r1 := httptest.NewRequest("GET", "/", nil)
+				r1.Header.Set("X-API-Key", "a")
+				r2 := httptest.NewRequest("GET", "/", nil)
+				r2.Header.Set("X-API-Key", "b")
+				return []*http.Request{r1, r2}
+			}(),
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Remaining": "0",
+			},
+		},
+		{
+			name:      "ExceededByQuery",
+			rateLimit: &RateLimit{Strategy: StrategyTokenBucket, Limit: 1, Window: "1m", Key: KeyQuery, KeyName: "api_key"},
+			requests: []*http.Request{
+				httptest.NewRequest("GET", "/?api_key=a", nil),
+				httptest.NewRequest("GET", "/?api_key=a", nil),
+			},
+			expectedStatusCode: 429,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Remaining": "0",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.rateLimit.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			var res *httptest.ResponseRecorder
+			for _, req := range tc.requests {
+				res = httptest.NewRecorder()
+				handler.ServeHTTP(res, req)
+			}
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			for key, val := range tc.expectedHeaders {
+				assert.Equal(t, val, res.Result().Header.Get(key), key)
+			}
+		})
+	}
+}
+
+func TestRESTMockRateLimit(t *testing.T) {
+	m := RESTMock{
+		RESTExpect: RESTExpect{BasePath: "/api/v1/teams"},
+		RateLimit:  &RateLimit{Limit: 2, Window: "1m"},
+	}
+
+	m.SetDefaults()
+	m.RESTStore.Index()
+
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+
+	// All routes of a rest mock share the same rate limit.
+	for _, tc := range []struct {
+		method             string
+		path               string
+		expectedStatusCode int
+	}{
+		{"GET", "/api/v1/teams", 200},
+		{"DELETE", "/api/v1/teams/aaaaaaaa", 204},
+		{"GET", "/api/v1/teams", 429},
+	} {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(tc.method, tc.path, nil))
+
+		assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode, "%s %s", tc.method, tc.path)
+	}
+}
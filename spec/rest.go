@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -16,6 +17,8 @@ const idTemplate = "/{id:[-0-9A-Za-z]+}"
 
 // RESTExpect represents a RESTful expectation.
 type RESTExpect struct {
+	Listener string            `json:"listener" yaml:"listener"`
+	Host     string            `json:"host" yaml:"host"`
 	BasePath string            `json:"basePath" yaml:"base_path"`
 	Headers  map[string]string `json:"headers" yaml:"headers"`
 }
@@ -56,6 +59,13 @@ type RESTMock struct {
 	RESTExpect   `json:",inline" yaml:",inline"`
 	RESTResponse `json:"response" yaml:"response"`
 	RESTStore    `json:"store" yaml:"store"`
+	CORS         *CORS      `json:"cors" yaml:"cors"`
+	Auth         *Auth      `json:"auth" yaml:"auth"`
+	RateLimit    *RateLimit `json:"rateLimit" yaml:"rate_limit"`
+	Priority     int        `json:"priority" yaml:"priority"`
+	Tags         []string   `json:"tags" yaml:"tags"`
+
+	chaos *Chaos
 }
 
 // SetDefaults set default values for empty fields.
@@ -91,23 +101,125 @@ func (m *RESTMock) SetDefaults() {
 	if m.RESTStore.Objects == nil {
 		m.RESTStore.Objects = []JSON{}
 	}
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+func (m *RESTMock) Inherit(c Config) {
+	if m.CORS == nil {
+		m.CORS = c.CORS
+	}
+
+	if m.Auth == nil {
+		m.Auth = c.Auth
+	}
+
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *RESTMock) Validate() error {
+	if err := compilePatterns("header", m.RESTExpect.Headers, false); err != nil {
+		return err
+	}
+
+	if err := m.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %s", err)
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	return nil
 }
 
 // String returns a string representation of the mock.
 func (m RESTMock) String() string {
-	return fmt.Sprintf("%s", m.RESTExpect.BasePath)
+	return withListener(m.RESTExpect.Listener, m.RESTExpect.Host+m.RESTExpect.BasePath)
 }
 
 // Hash calculates a hash for a rest mock based on the rest expectation.
 func (m RESTMock) Hash() uint64 {
 	h := fnv.New64a()
 
+	hashString(h, m.RESTExpect.Listener, m.RESTExpect.Host)
 	hashString(h, m.RESTExpect.BasePath)
 	hashStringMap(h, true, m.RESTExpect.Headers)
 
 	return h.Sum64()
 }
 
+// Rank returns the rank of the mock for ordering overlapping mocks.
+func (m RESTMock) Rank() Rank {
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(true, m.RESTExpect.BasePath, len(m.RESTExpect.Headers)),
+	}
+}
+
+// Diagnose scores a recorded request against the mock.
+// Requests for a single object are scored against the object routes, and the rest against the collection routes.
+// Mocks of other listeners are not considered at all.
+func (m RESTMock) Diagnose(r Request) Diagnosis {
+	if m.RESTExpect.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.RESTExpect.Listener, r)
+	}
+
+	e := HTTPExpect{
+		Host:    m.RESTExpect.Host,
+		Methods: []string{"GET", "POST"},
+		Path:    m.RESTExpect.BasePath,
+		Headers: m.RESTExpect.Headers,
+	}
+
+	prefix := strings.TrimSuffix(m.RESTExpect.BasePath, "/") + "/"
+	if id := strings.TrimPrefix(r.Path, prefix); id != r.Path && id != "" && !strings.Contains(id, "/") {
+		e.Methods = []string{"GET", "PUT", "PATCH", "DELETE"}
+		e.Path = r.Path
+	}
+
+	d := e.Diagnose(r)
+	d.Mock = m.String()
+
+	return d
+}
+
+// route creates a new route for a method and a path of the mock.
+func (m RESTMock) route(router *mux.Router, method, path string) *mux.Route {
+	return m.match(router.Methods(method).Path(path), true)
+}
+
+// match adds the listener, host, and optionally header matchers of the mock to a route.
+// Preflight requests do not carry the headers of actual requests.
+func (m RESTMock) match(route *mux.Route, headers bool) *mux.Route {
+	route.MatcherFunc(matchListener(m.RESTExpect.Listener))
+
+	if m.RESTExpect.Host != "" {
+		route.MatcherFunc(hostMatcher(m.RESTExpect.Host))
+	}
+
+	if headers {
+		for header, pattern := range m.RESTExpect.Headers {
+			route.HeadersRegexp(header, pattern)
+		}
+	}
+
+	return route
+}
+
+// wrap wraps a handler of the mock with cors, chaos, rate limit, and auth.
+func (m RESTMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(m.CORS, m.chaos, m.RateLimit, m.Auth, h)
+}
+
 // RegisterRoutes configure routes for a rest mock.
 func (m RESTMock) RegisterRoutes(router *mux.Router) {
 	delay, _ := time.ParseDuration(m.Delay)
@@ -115,18 +227,15 @@ func (m RESTMock) RegisterRoutes(router *mux.Router) {
 	// GET /
 	{
 		path := m.RESTExpect.BasePath
-		route := router.Methods("GET").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "GET", path)
 
 		// TODO: implement filtering through query parameters
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			time.Sleep(delay)
-			w.WriteHeader(m.RESTResponse.GetStatusCode)
 			for key, val := range m.RESTResponse.Headers {
 				w.Header().Set(key, val)
 			}
+			w.WriteHeader(m.RESTResponse.GetStatusCode)
 
 			var resp interface{}
 			if m.RESTResponse.ListKey == "" {
@@ -138,138 +247,132 @@ func (m RESTMock) RegisterRoutes(router *mux.Router) {
 			}
 
 			_ = json.NewEncoder(w).Encode(resp)
-		})
+		})))
 	}
 
 	// POST /
 	{
 		path := m.RESTExpect.BasePath
-		route := router.Methods("POST").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "POST", path)
 
 		// TODO: Finish implementation
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if m.RESTStore.Directory != nil {
 				time.Sleep(delay)
-				w.WriteHeader(m.RESTResponse.PostStatusCode)
 				for key, val := range m.RESTResponse.Headers {
 					w.Header().Set(key, val)
 				}
+				w.WriteHeader(m.RESTResponse.PostStatusCode)
 
 				_ = json.NewEncoder(w).Encode(JSON{
 					"message": "not implemented yet!",
 				})
 			}
-		})
+		})))
 	}
 
 	// GET /id
 	{
 		path := filepath.Join(m.RESTExpect.BasePath, idTemplate)
-		route := router.Methods("GET").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "GET", path)
 
 		// TODO: Finish implementation
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// vars := mux.Vars(r)
 			// id := vars["id"]
 
 			if m.RESTStore.Directory != nil {
 				time.Sleep(delay)
-				w.WriteHeader(m.RESTResponse.GetStatusCode)
 				for key, val := range m.RESTResponse.Headers {
 					w.Header().Set(key, val)
 				}
+				w.WriteHeader(m.RESTResponse.GetStatusCode)
 
 				_ = json.NewEncoder(w).Encode(JSON{
 					"message": "not implemented yet!",
 				})
 			}
-		})
+		})))
 	}
 
 	// PUT /id
 	{
 		path := filepath.Join(m.RESTExpect.BasePath, idTemplate)
-		route := router.Methods("PUT").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "PUT", path)
 
 		// TODO: Finish implementation
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// vars := mux.Vars(r)
 			// id := vars["id"]
 
 			if m.RESTStore.Directory != nil {
 				time.Sleep(delay)
-				w.WriteHeader(m.RESTResponse.PutStatusCode)
 				for key, val := range m.RESTResponse.Headers {
 					w.Header().Set(key, val)
 				}
+				w.WriteHeader(m.RESTResponse.PutStatusCode)
 
 				_ = json.NewEncoder(w).Encode(JSON{
 					"message": "not implemented yet!",
 				})
 			}
-		})
+		})))
 	}
 
 	// PATCH /id
 	{
 		path := filepath.Join(m.RESTExpect.BasePath, idTemplate)
-		route := router.Methods("PATCH").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "PATCH", path)
 
 		// TODO: Finish implementation
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// vars := mux.Vars(r)
 			// id := vars["id"]
 
 			if m.RESTStore.Directory != nil {
 				time.Sleep(delay)
-				w.WriteHeader(m.RESTResponse.PatchStatusCode)
 				for key, val := range m.RESTResponse.Headers {
 					w.Header().Set(key, val)
 				}
+				w.WriteHeader(m.RESTResponse.PatchStatusCode)
 
 				_ = json.NewEncoder(w).Encode(JSON{
 					"message": "not implemented yet!",
 				})
 			}
-		})
+		})))
 	}
 
 	// DELETE /id
 	{
 		path := filepath.Join(m.RESTExpect.BasePath, idTemplate)
-		route := router.Methods("DELETE").Path(path)
-		for header, pattern := range m.RESTExpect.Headers {
-			route.HeadersRegexp(header, pattern)
-		}
+		route := m.route(router, "DELETE", path)
 
 		// TODO: Finish implementation
-		route.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route.Handler(m.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// vars := mux.Vars(r)
 			// id := vars["id"]
 
 			if m.RESTStore.Directory != nil {
 				time.Sleep(delay)
-				w.WriteHeader(m.RESTResponse.DeleteStatusCode)
 				for key, val := range m.RESTResponse.Headers {
 					w.Header().Set(key, val)
 				}
+				w.WriteHeader(m.RESTResponse.DeleteStatusCode)
 
 				_ = json.NewEncoder(w).Encode(JSON{
 					"message": "not implemented yet!",
 				})
 			}
-		})
+		})))
+	}
+
+	// Preflight requests do not carry the headers of actual requests.
+	if preflight := m.CORS.RegisterPreflight(router, []string{"GET", "POST"}); preflight != nil {
+		m.match(preflight.Path(m.RESTExpect.BasePath), false)
+	}
+
+	if preflight := m.CORS.RegisterPreflight(router, []string{"GET", "PUT", "PATCH", "DELETE"}); preflight != nil {
+		m.match(preflight.Path(filepath.Join(m.RESTExpect.BasePath, idTemplate)), false)
 	}
 }
@@ -85,11 +85,11 @@ func TestRESTMockSetDefaults(t *testing.T) {
 			"Empty",
 			RESTMock{},
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/",
 					Headers:  nil,
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -101,7 +101,7 @@ func TestRESTMockSetDefaults(t *testing.T) {
 					},
 					ListKey: "",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "",
 					Objects:    []JSON{},
 					Directory:  nil,
@@ -132,14 +132,14 @@ func TestRESTMockkString(t *testing.T) {
 		{
 			"OK",
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -149,7 +149,7 @@ func TestRESTMockkString(t *testing.T) {
 					Headers:          map[string]string{},
 					ListKey:          "",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "",
 					Objects:    []JSON{},
 				},
@@ -176,14 +176,14 @@ func TestRESTMockHash(t *testing.T) {
 		{
 			"Equal",
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -193,20 +193,20 @@ func TestRESTMockHash(t *testing.T) {
 					Headers:          map[string]string{},
 					ListKey:          "",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "",
 					Objects:    []JSON{},
 				},
 			},
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "10ms",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -218,7 +218,7 @@ func TestRESTMockHash(t *testing.T) {
 					},
 					ListKey: "data",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "_id",
 					Objects:    []JSON{},
 				},
@@ -228,14 +228,14 @@ func TestRESTMockHash(t *testing.T) {
 		{
 			"DifferentVersions",
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -245,20 +245,20 @@ func TestRESTMockHash(t *testing.T) {
 					Headers:          map[string]string{},
 					ListKey:          "",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "",
 					Objects:    []JSON{},
 				},
 			},
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v2/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "10ms",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -270,7 +270,7 @@ func TestRESTMockHash(t *testing.T) {
 					},
 					ListKey: "data",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "_id",
 					Objects:    []JSON{},
 				},
@@ -280,11 +280,11 @@ func TestRESTMockHash(t *testing.T) {
 		{
 			"DifferentHeaders",
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers:  map[string]string{},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -294,20 +294,20 @@ func TestRESTMockHash(t *testing.T) {
 					Headers:          map[string]string{},
 					ListKey:          "",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "",
 					Objects:    []JSON{},
 				},
 			},
 			RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "10ms",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -319,7 +319,7 @@ func TestRESTMockHash(t *testing.T) {
 					},
 					ListKey: "data",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "_id",
 					Objects:    []JSON{},
 				},
@@ -356,14 +356,14 @@ func TestRESTMockRegisterRoutes(t *testing.T) {
 		{
 			name: "WithListKey",
 			mock: RESTMock{
-				RESTExpect{
+				RESTExpect: RESTExpect{
 					BasePath: "/api/v1/teams",
 					Headers: map[string]string{
 						"Accept":       "application/json",
 						"Content-Type": "application/json",
 					},
 				},
-				RESTResponse{
+				RESTResponse: RESTResponse{
 					Delay:            "10ms",
 					GetStatusCode:    200,
 					PostStatusCode:   201,
@@ -375,7 +375,7 @@ func TestRESTMockRegisterRoutes(t *testing.T) {
 					},
 					ListKey: "data",
 				},
-				RESTStore{
+				RESTStore: RESTStore{
 					Identifier: "id",
 					Objects: []JSON{
 						{"id": "aaaa", "name": "Back-end"},
@@ -423,7 +423,7 @@ func TestRESTMockRegisterRoutes(t *testing.T) {
 
 				assert.Equal(t, tc.expectedGetStatusCode, res.Result().StatusCode)
 				for key, val := range tc.expectedHeaders {
-					assert.Equal(t, val, res.Header().Get(key))
+					assert.Equal(t, val, res.Result().Header.Get(key))
 				}
 
 				resBody := JSON{}
@@ -0,0 +1,231 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// SOAP11 is the version 1.1 of SOAP.
+	SOAP11 = "1.1"
+	// SOAP12 is the version 1.2 of SOAP.
+	SOAP12 = "1.2"
+)
+
+var soapNamespaces = map[string]string{
+	SOAP11: "http://schemas.xmlsoap.org/soap/envelope/",
+	SOAP12: "http://www.w3.org/2003/05/soap-envelope",
+}
+
+var soapContentTypes = map[string]string{
+	SOAP11: "text/xml; charset=utf-8",
+	SOAP12: "application/soap+xml; charset=utf-8",
+}
+
+// operationXPath selects the first element in the body of a SOAP envelope.
+var operationXPath, _ = compileXPath("/Envelope/Body/*[1]")
+
+// soapAction returns the action of a SOAP request from either
+// the SOAPAction header (SOAP 1.1) or the action parameter of the Content-Type header (SOAP 1.2).
+func soapAction(headers http.Header) string {
+	if action := headers.Get("SOAPAction"); action != "" {
+		return strings.Trim(action, `"`)
+	}
+
+	if _, params, err := mime.ParseMediaType(headers.Get("Content-Type")); err == nil {
+		return params["action"]
+	}
+
+	return ""
+}
+
+// soapOperation returns the name of the first element in the body of a SOAP envelope.
+func soapOperation(body []byte) string {
+	doc, err := parseXML(body)
+	if err != nil {
+		return ""
+	}
+
+	if n := xmlquery.QuerySelector(doc, operationXPath); n != nil {
+		return n.Data
+	}
+
+	return ""
+}
+
+// validXML checks whether or not a string is a well-formed xml fragment.
+func validXML(s string) error {
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + s + "</root>"))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// SOAPExpect represents an expectation for a SOAP request.
+// Action is compared to the SOAPAction header (SOAP 1.1) or the action parameter of the Content-Type header (SOAP 1.2).
+// Operation is compared to the name of the first element in the SOAP body regardless of its namespace.
+type SOAPExpect struct {
+	Action    string `json:"action" yaml:"action"`
+	Operation string `json:"operation" yaml:"operation"`
+}
+
+func (e *SOAPExpect) String() string {
+	if e.Operation != "" {
+		return e.Operation
+	}
+	return e.Action
+}
+
+// Match determines whether or not a SOAP request satisfies the expectation.
+func (e *SOAPExpect) Match(headers http.Header, body []byte) bool {
+	if e.Action != "" && e.Action != soapAction(headers) {
+		return false
+	}
+
+	if e.Operation != "" && e.Operation != soapOperation(body) {
+		return false
+	}
+
+	return true
+}
+
+// soapMatcher creates a route matcher for a SOAP request.
+func soapMatcher(e *SOAPExpect) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		body, err := readBody(r)
+		if err != nil {
+			return false
+		}
+
+		return e.Match(r.Header, body)
+	}
+}
+
+// SOAPFault represents a SOAP fault.
+// Code is a fault code such as Client or Server for SOAP 1.1 and Sender or Receiver for SOAP 1.2.
+// Detail is the xml of the fault detail.
+type SOAPFault struct {
+	Code   string `json:"code" yaml:"code"`
+	Reason string `json:"reason" yaml:"reason"`
+	Actor  string `json:"actor" yaml:"actor"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// SOAPResponse represents a mock SOAP response.
+// Body is the xml of the SOAP body without the envelope.
+// If Fault is set, a SOAP fault is responded instead.
+type SOAPResponse struct {
+	Version string     `json:"version" yaml:"version"`
+	Body    string     `json:"body" yaml:"body"`
+	Fault   *SOAPFault `json:"fault" yaml:"fault"`
+}
+
+// SetDefaults set default values for empty fields.
+func (r *SOAPResponse) SetDefaults() {
+	if r.Version == "" {
+		r.Version = SOAP11
+	}
+
+	if r.Fault != nil && r.Fault.Code == "" {
+		if r.Version == SOAP12 {
+			r.Fault.Code = "Receiver"
+		} else {
+			r.Fault.Code = "Server"
+		}
+	}
+}
+
+// Validate checks whether or not the SOAP response is valid.
+func (r *SOAPResponse) Validate() error {
+	if _, ok := soapNamespaces[r.Version]; !ok {
+		return fmt.Errorf("unknown soap version: %s", r.Version)
+	}
+
+	if r.Fault != nil {
+		if r.Body != "" {
+			return errors.New("soap body cannot be set along with fault")
+		}
+
+		if err := validXML(r.Fault.Detail); err != nil {
+			return fmt.Errorf("invalid soap fault detail: %s", err)
+		}
+
+		return nil
+	}
+
+	if err := validXML(r.Body); err != nil {
+		return fmt.Errorf("invalid soap body: %s", err)
+	}
+
+	return nil
+}
+
+// contentType returns the media type of the SOAP response.
+func (r *SOAPResponse) contentType() string {
+	return soapContentTypes[r.Version]
+}
+
+// envelope returns the SOAP envelope of the response.
+func (r *SOAPResponse) envelope() string {
+	buf := new(bytes.Buffer)
+
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(buf, `<soap:Envelope xmlns:soap="%s"><soap:Body>`, soapNamespaces[r.Version])
+
+	if f := r.Fault; f != nil {
+		code := f.Code
+		if !strings.Contains(code, ":") {
+			code = "soap:" + code
+		}
+
+		buf.WriteString("<soap:Fault>")
+
+		if r.Version == SOAP12 {
+			writeXMLElement(buf, "soap:Code", "<soap:Value>"+escapeXML(code)+"</soap:Value>", false)
+			writeXMLElement(buf, "soap:Reason", `<soap:Text xml:lang="en">`+escapeXML(f.Reason)+"</soap:Text>", false)
+			writeXMLElement(buf, "soap:Role", escapeXML(f.Actor), true)
+			writeXMLElement(buf, "soap:Detail", f.Detail, true)
+		} else {
+			writeXMLElement(buf, "faultcode", escapeXML(code), false)
+			writeXMLElement(buf, "faultstring", escapeXML(f.Reason), false)
+			writeXMLElement(buf, "faultactor", escapeXML(f.Actor), true)
+			writeXMLElement(buf, "detail", f.Detail, true)
+		}
+
+		buf.WriteString("</soap:Fault>")
+	} else {
+		buf.WriteString(r.Body)
+	}
+
+	buf.WriteString("</soap:Body></soap:Envelope>")
+
+	return buf.String()
+}
+
+func escapeXML(s string) string {
+	buf := new(bytes.Buffer)
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+// writeXMLElement writes an element with some inner xml, and skips empty optional elements.
+func writeXMLElement(buf *bytes.Buffer, name, inner string, optional bool) {
+	if optional && inner == "" {
+		return
+	}
+
+	fmt.Fprintf(buf, "<%s>%s</%s>", name, inner, name)
+}
@@ -0,0 +1,300 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const soapRequest = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:p="urn:payments">
+  <soap:Header/>
+  <soap:Body>
+    <p:GetBalance><p:Account>1111</p:Account></p:GetBalance>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestSOAPAction(t *testing.T) {
+	tests := []struct {
+		name           string
+		headers        http.Header
+		expectedAction string
+	}{
+		{"None", http.Header{}, ""},
+		{"SOAP11", http.Header{"Soapaction": {`"urn:payments/GetBalance"`}}, "urn:payments/GetBalance"},
+		{"SOAP12", http.Header{"Content-Type": {`application/soap+xml; charset=utf-8; action="urn:payments/GetBalance"`}}, "urn:payments/GetBalance"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAction, soapAction(tc.headers))
+		})
+	}
+}
+
+func TestSOAPOperation(t *testing.T) {
+	assert.Equal(t, "GetBalance", soapOperation([]byte(soapRequest)))
+	assert.Equal(t, "", soapOperation([]byte(`<Envelope><Body/></Envelope>`)))
+	assert.Equal(t, "", soapOperation([]byte(`not xml`)))
+}
+
+func TestSOAPExpectMatch(t *testing.T) {
+	headers := http.Header{"Soapaction": {"urn:payments/GetBalance"}}
+
+	tests := []struct {
+		name          string
+		expect        SOAPExpect
+		expectedMatch bool
+	}{
+		{"Empty", SOAPExpect{}, true},
+		{"Action", SOAPExpect{Action: "urn:payments/GetBalance"}, true},
+		{"ActionMismatch", SOAPExpect{Action: "urn:payments/Transfer"}, false},
+		{"Operation", SOAPExpect{Operation: "GetBalance"}, true},
+		{"OperationMismatch", SOAPExpect{Operation: "Transfer"}, false},
+		{"Both", SOAPExpect{Action: "urn:payments/GetBalance", Operation: "GetBalance"}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMatch, tc.expect.Match(headers, []byte(soapRequest)))
+		})
+	}
+}
+
+func TestSOAPResponseSetDefaults(t *testing.T) {
+	r := &SOAPResponse{Fault: &SOAPFault{}}
+	r.SetDefaults()
+	assert.Equal(t, SOAP11, r.Version)
+	assert.Equal(t, "Server", r.Fault.Code)
+
+	r = &SOAPResponse{Version: SOAP12, Fault: &SOAPFault{}}
+	r.SetDefaults()
+	assert.Equal(t, "Receiver", r.Fault.Code)
+}
+
+func TestSOAPResponseValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      SOAPResponse
+		expectedError string
+	}{
+		{
+			name:          "UnknownVersion",
+			response:      SOAPResponse{Version: "2.0"},
+			expectedError: "unknown soap version: 2.0",
+		},
+		{
+			name:          "BodyAndFault",
+			response:      SOAPResponse{Version: SOAP11, Body: "<a/>", Fault: &SOAPFault{}},
+			expectedError: "soap body cannot be set along with fault",
+		},
+		{
+			name:          "InvalidBody",
+			response:      SOAPResponse{Version: SOAP11, Body: "<a>"},
+			expectedError: "invalid soap body: XML syntax error on line 1: element <a> closed by </root>",
+		},
+		{
+			name:          "InvalidDetail",
+			response:      SOAPResponse{Version: SOAP11, Fault: &SOAPFault{Detail: "<a>"}},
+			expectedError: "invalid soap fault detail: XML syntax error on line 1: element <a> closed by </root>",
+		},
+		{
+			name:          "OK",
+			response:      SOAPResponse{Version: SOAP12, Body: "<p:Balance xmlns:p=\"urn:payments\">100</p:Balance>"},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.response.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSOAPResponseEnvelope(t *testing.T) {
+	tests := []struct {
+		name             string
+		response         SOAPResponse
+		expectedEnvelope string
+	}{
+		{
+			name:     "Body",
+			response: SOAPResponse{Version: SOAP11, Body: "<Balance>100</Balance>"},
+			expectedEnvelope: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<Balance>100</Balance>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+		{
+			name: "SOAP11Fault",
+			response: SOAPResponse{
+				Version: SOAP11,
+				Fault:   &SOAPFault{Code: "Client", Reason: "Account <1111> not found", Detail: "<code>404</code>"},
+			},
+			expectedEnvelope: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<soap:Fault><faultcode>soap:Client</faultcode><faultstring>Account &lt;1111&gt; not found</faultstring><detail><code>404</code></detail></soap:Fault>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+		{
+			name: "SOAP12Fault",
+			response: SOAPResponse{
+				Version: SOAP12,
+				Fault:   &SOAPFault{Code: "Receiver", Reason: "Unavailable", Actor: "urn:payments"},
+			},
+			expectedEnvelope: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>` +
+				`<soap:Fault><soap:Code><soap:Value>soap:Receiver</soap:Value></soap:Code>` +
+				`<soap:Reason><soap:Text xml:lang="en">Unavailable</soap:Text></soap:Reason>` +
+				`<soap:Role>urn:payments</soap:Role></soap:Fault>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			envelope := tc.response.envelope()
+
+			assert.Equal(t, tc.expectedEnvelope, envelope)
+			assert.NoError(t, validXML(strings.TrimPrefix(envelope, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")))
+		})
+	}
+}
+
+func TestHTTPMockRouteSOAP(t *testing.T) {
+	mocks := []HTTPMock{
+		{
+			HTTPExpect: HTTPExpect{
+				Methods: []string{"POST"},
+				Path:    "/payments",
+				SOAP:    &SOAPExpect{Operation: "GetBalance"},
+			},
+			HTTPResponse: &HTTPResponse{
+				SOAP: &SOAPResponse{Body: "<Balance>100</Balance>"},
+			},
+		},
+		{
+			HTTPExpect: HTTPExpect{
+				Methods: []string{"POST"},
+				Path:    "/payments",
+				SOAP:    &SOAPExpect{Action: "urn:payments/Transfer"},
+			},
+			HTTPResponse: &HTTPResponse{
+				SOAP: &SOAPResponse{Version: SOAP12, Fault: &SOAPFault{Reason: "Insufficient funds"}},
+			},
+		},
+	}
+
+	router := mux.NewRouter()
+	for _, m := range mocks {
+		m.SetDefaults()
+		assert.NoError(t, m.Validate())
+		m.RegisterRoutes(router)
+	}
+
+	tests := []struct {
+		name                string
+		headers             map[string]string
+		body                string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Operation",
+			headers:             map[string]string{"Content-Type": "text/xml"},
+			body:                soapRequest,
+			expectedStatusCode:  200,
+			expectedContentType: "text/xml; charset=utf-8",
+			expectedBody:        "<soap:Body><Balance>100</Balance></soap:Body>",
+		},
+		{
+			name:                "Fault",
+			headers:             map[string]string{"Content-Type": `application/soap+xml; action="urn:payments/Transfer"`},
+			body:                `<Envelope><Body><Transfer/></Body></Envelope>`,
+			expectedStatusCode:  500,
+			expectedContentType: "application/soap+xml; charset=utf-8",
+			expectedBody:        `<soap:Text xml:lang="en">Insufficient funds</soap:Text>`,
+		},
+		{
+			name:               "NoMatch",
+			headers:            map[string]string{"Content-Type": "text/xml"},
+			body:               `<Envelope><Body><Refund/></Body></Envelope>`,
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/payments", strings.NewReader(tc.body))
+			for key, val := range tc.headers {
+				req.Header.Set(key, val)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, res.Result().Header.Get("Content-Type"))
+				assert.Contains(t, res.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
+
+func TestHTTPMockRouteXPath(t *testing.T) {
+	mock := HTTPMock{
+		HTTPExpect: HTTPExpect{
+			Methods: []string{"POST"},
+			Path:    "/orders",
+			XPath: map[string]string{
+				"/order/@currency": "^CAD$",
+				"//item":           "",
+			},
+		},
+		HTTPResponse: &HTTPResponse{
+			StatusCode: 201,
+			BodyXML:    "<order><id>1</id></order>",
+		},
+	}
+
+	router := mux.NewRouter()
+	mock.RegisterRoutes(router)
+
+	tests := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+	}{
+		{"Match", `<order currency="CAD"><item>book</item></order>`, 201},
+		{"ValueMismatch", `<order currency="USD"><item>book</item></order>`, 404},
+		{"NoNode", `<order currency="CAD"></order>`, 404},
+		{"NotXML", `{"currency":"CAD"}`, 404},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(tc.body))
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			if tc.expectedStatusCode == 201 {
+				assert.Equal(t, "application/xml", res.Result().Header.Get("Content-Type"))
+				assert.Equal(t, "<order><id>1</id></order>", res.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package spec
+
+import (
+	"os"
+)
+
+// Config has the specifications for mock server configurations.
+type Config struct {
+	HTTPPort  uint16     `json:"httpPort" yaml:"http_port"`
+	HTTPSPort uint16     `json:"httpsPort" yaml:"https_port"`
+	CORS      *CORS      `json:"cors" yaml:"cors"`
+	Unmatched *Unmatched `json:"unmatched" yaml:"unmatched"`
+	Fallback  *Fallback  `json:"fallback" yaml:"fallback"`
+	Listeners []Listener `json:"listeners" yaml:"listeners"`
+	Auth      *Auth      `json:"auth" yaml:"auth"`
+	RateLimit *RateLimit `json:"rateLimit" yaml:"rate_limit"`
+	Chaos     *Chaos     `json:"chaos" yaml:"chaos"`
+}
+
+// Spec has all the specifications.
+type Spec struct {
+	Config         Config            `json:"config" yaml:"config"`
+	Includes       []string          `json:"include" yaml:"include"`
+	Vars           map[string]string `json:"vars" yaml:"vars"`
+	Listener       string            `json:"listener" yaml:"listener"`
+	HTTPMocks      []HTTPMock        `json:"http" yaml:"http"`
+	RESTMocks      []RESTMock        `json:"rest" yaml:"rest"`
+	OIDCMocks      []OIDCMock        `json:"oidc" yaml:"oidc"`
+	GraphQLMocks   []GraphQLMock     `json:"graphql" yaml:"graphql"`
+	GRPCMocks      []GRPCMock        `json:"grpc" yaml:"grpc"`
+	WebSocketMocks []WebSocketMock   `json:"websocket" yaml:"websocket"`
+}
+
+// DefaultSpec returns a default Spec.
+func DefaultSpec() *Spec {
+	config := Config{
+		HTTPPort:  8080,
+		HTTPSPort: 8443,
+	}
+
+	httpMock := HTTPMock{}
+	httpMock.SetDefaults()
+
+	return &Spec{
+		Config:    config,
+		HTTPMocks: []HTTPMock{httpMock},
+		RESTMocks: []RESTMock{},
+	}
+}
+
+// ReadSpec reads and returns a Spec from a JSON or YAML file, or from all such files in a directory.
+// A spec file can include other spec files or directories using a list of glob patterns relative to itself.
+// Variables in the form of ${NAME} or ${NAME:-default} are expanded before decoding spec files.
+// They are resolved from the given vars first, then the vars section of spec files, and then environment variables.
+// It returns a default spec if no spec file found.
+func ReadSpec(path string, vars map[string]string) (*Spec, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return DefaultSpec(), nil
+		}
+		return nil, err
+	}
+
+	l := newLoader(vars)
+	if err := l.load(path); err != nil {
+		return nil, err
+	}
+
+	spec := l.spec
+
+	if spec.Config.HTTPPort == 0 {
+		spec.Config.HTTPPort = 8080
+	}
+
+	if spec.Config.HTTPSPort == 0 {
+		spec.Config.HTTPSPort = 8443
+	}
+
+	spec.Config.Fallback.SetDefaults()
+	spec.Config.RateLimit.SetDefaults()
+	spec.Config.Chaos.SetDefaults()
+
+	for i := range spec.HTTPMocks {
+		spec.HTTPMocks[i].SetDefaults()
+		spec.HTTPMocks[i].Inherit(spec.Config)
+	}
+
+	for i := range spec.RESTMocks {
+		spec.RESTMocks[i].SetDefaults()
+		spec.RESTMocks[i].Inherit(spec.Config)
+		spec.RESTMocks[i].RESTStore.Index()
+	}
+
+	for i := range spec.OIDCMocks {
+		spec.OIDCMocks[i].SetDefaults()
+		spec.OIDCMocks[i].Inherit(spec.Config)
+	}
+
+	for i := range spec.GraphQLMocks {
+		spec.GraphQLMocks[i].SetDefaults()
+		spec.GraphQLMocks[i].Inherit(spec.Config)
+	}
+
+	for i := range spec.GRPCMocks {
+		spec.GRPCMocks[i].SetDefaults()
+		spec.GRPCMocks[i].Inherit(spec.Config)
+	}
+
+	for i := range spec.WebSocketMocks {
+		spec.WebSocketMocks[i].SetDefaults()
+		spec.WebSocketMocks[i].Inherit(spec.Config)
+	}
+
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := l.checkConflicts(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,1049 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	defaultSpec = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specSimple = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/health",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 201,
+					Headers:    nil,
+					Body: map[string]interface{}{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+		},
+		RESTMocks: []RESTMock{
+			RESTMock{
+				RESTExpect: RESTExpect{
+					BasePath: "/api/v1/teams",
+					Headers:  nil,
+				},
+				RESTResponse: RESTResponse{
+					Delay:            "",
+					GetStatusCode:    200,
+					PostStatusCode:   201,
+					PutStatusCode:    200,
+					PatchStatusCode:  200,
+					DeleteStatusCode: 204,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					ListKey: "",
+				},
+				RESTStore: RESTStore{
+					Objects: []JSON{
+						{"_id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "name": "Back-end"},
+						{"_id": "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "name": "Front-end"},
+					},
+					Directory: map[interface{}]JSON{
+						"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa": {"_id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "name": "Back-end"},
+						"bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb": {"_id": "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "name": "Front-end"},
+					},
+				},
+			},
+		},
+	}
+
+	specDir = func() *Spec {
+		spec := *specSimple
+		spec.Config = Config{
+			HTTPPort:  9080,
+			HTTPSPort: 9443,
+		}
+		return &spec
+	}()
+
+	specBodies = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/documents/1",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					BodyFile:   "test/files/document.pdf",
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/teams.csv",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					Headers: map[string]string{
+						"Content-Type": "text/plain",
+					},
+					BodyFile: "test/files/teams.csv",
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/hello",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					BodyText:   "Hello, World!",
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/pixel",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					BodyBase64: "R0lGODlhAQABAAAAACw=",
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specCORS = func() *Spec {
+		cors := &CORS{
+			Origins:     []string{"https://*.example.com"},
+			Credentials: true,
+			MaxAge:      600,
+		}
+
+		return &Spec{
+			Config: Config{
+				HTTPPort:  8080,
+				HTTPSPort: 8443,
+				CORS:      cors,
+			},
+			HTTPMocks: []HTTPMock{
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/health",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					CORS: cors,
+				},
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"POST"},
+						Path:    "/api/v1/sendMessage",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					CORS: &CORS{
+						Origins:        []string{"*"},
+						Headers:        []string{"Content-Type", "Authorization"},
+						ExposedHeaders: []string{"Location"},
+					},
+				},
+			},
+			RESTMocks: []RESTMock{},
+		}
+	}()
+
+	specFallback = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+			Fallback: &Fallback{
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					Headers: map[string]string{
+						"Content-Type": "text/csv",
+					},
+					BodyFile: "test/files/teams.csv",
+				},
+			},
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/health",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specListeners = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+			Listeners: []Listener{
+				{Name: "payments", Port: 8081},
+			},
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Host:    "*.example.com",
+					Methods: []string{"GET"},
+					Path:    "/health",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Listener: "payments",
+					Methods:  []string{"GET"},
+					Path:     "/health",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 204,
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specAuth = func() *Spec {
+		auth := &Auth{
+			JWT: &JWTAuth{
+				JWKSFile: "test/auth/jwks.json",
+				Issuer:   "https://auth.example.com",
+			},
+		}
+
+		return &Spec{
+			Config: Config{
+				HTTPPort:  8080,
+				HTTPSPort: 8443,
+				Auth:      auth,
+			},
+			HTTPMocks: []HTTPMock{
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/health",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					Auth: &Auth{
+						Basic: []BasicAuth{
+							{Username: "admin", Password: "secret"},
+						},
+					},
+				},
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/api/v1/teams",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					Auth: auth,
+				},
+			},
+			RESTMocks: []RESTMock{},
+		}
+	}()
+
+	specOIDC = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{},
+		RESTMocks: []RESTMock{},
+		OIDCMocks: []OIDCMock{
+			OIDCMock{
+				BasePath: "/auth",
+				TokenTTL: "1h",
+				Clients: []OIDCClient{
+					{
+						ID:       "backend",
+						Secret:   "secret",
+						Scopes:   []string{"openid", "teams:read"},
+						Audience: "teams",
+					},
+				},
+				Users: []OIDCUser{
+					{
+						Username: "jane",
+						Password: "pass",
+						Claims:   JSON{"email": "jane@example.com"},
+					},
+				},
+			},
+		},
+	}
+
+	specRateLimit = func() *Spec {
+		global := &RateLimit{Limit: 100, Window: "1m"}
+		global.SetDefaults()
+
+		search := &RateLimit{Strategy: StrategyTokenBucket, Limit: 5, Key: KeyHeader, KeyName: "X-API-Key"}
+		search.SetDefaults()
+
+		return &Spec{
+			Config: Config{
+				HTTPPort:  8080,
+				HTTPSPort: 8443,
+				RateLimit: global,
+			},
+			HTTPMocks: []HTTPMock{
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/health",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					RateLimit: global,
+				},
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/api/v1/search",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					RateLimit: search,
+				},
+			},
+			RESTMocks: []RESTMock{},
+		}
+	}()
+
+	specChaos = func() *Spec {
+		chaos := &Chaos{
+			Seed: 42,
+			Tags: []string{"flaky"},
+			Errors: &ChaosErrors{
+				Percent:     10,
+				StatusCodes: []int{500, 503},
+			},
+			Latency: &ChaosLatency{
+				Percent: 20,
+				Min:     "100ms",
+				Max:     "2s",
+			},
+		}
+		chaos.SetDefaults()
+
+		return &Spec{
+			Config: Config{
+				HTTPPort:  8080,
+				HTTPSPort: 8443,
+				Chaos:     chaos,
+			},
+			HTTPMocks: []HTTPMock{
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/health",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+				},
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/api/v1/search",
+					},
+					HTTPResponse: &HTTPResponse{
+						StatusCode: 200,
+					},
+					Tags:  []string{"flaky"},
+					chaos: chaos,
+				},
+			},
+			RESTMocks: []RESTMock{},
+		}
+	}()
+
+	specSOAP = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/payments",
+					XPath: map[string]string{
+						"//Account": "^[0-9]+$",
+					},
+					SOAP: &SOAPExpect{
+						Operation: "GetBalance",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					SOAP: &SOAPResponse{
+						Version: SOAP11,
+						Body:    "<Balance>100</Balance>",
+					},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/payments",
+					SOAP: &SOAPExpect{
+						Action: "urn:payments/Transfer",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 500,
+					SOAP: &SOAPResponse{
+						Version: SOAP12,
+						Fault: &SOAPFault{
+							Code:   "Sender",
+							Reason: "Insufficient funds",
+						},
+					},
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specGraphQL = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{},
+		RESTMocks: []RESTMock{},
+		GraphQLMocks: []GraphQLMock{
+			GraphQLMock{
+				Path:       "/graphql",
+				SchemaFile: "test/schema.graphql",
+				Operations: []GraphQLOperation{
+					{
+						Name:      "GetUser",
+						Variables: map[string]string{"id": "^0$"},
+						Errors:    []GraphQLError{{Message: "user not found"}},
+					},
+				},
+				Resolvers: []GraphQLResolver{
+					{Field: "Query.user", Store: "users"},
+					{Field: "Query.users", Store: "users"},
+					{Field: "Mutation.createUser", Store: "users"},
+				},
+				Stores: map[string]RESTStore{
+					"users": {
+						Objects: []JSON{
+							{"id": "1", "name": "Jane"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	specGRPC = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{},
+		RESTMocks: []RESTMock{},
+		GRPCMocks: []GRPCMock{
+			GRPCMock{
+				DescriptorSet: "test/greeter.pb",
+				Reflection:    true,
+				Methods: []GRPCMethod{
+					{
+						Name:  "greeter.v1.Greeter/SayHello",
+						Match: map[string]string{"name": "^$"},
+						Status: &GRPCStatus{
+							Code:    "INVALID_ARGUMENT",
+							Message: "name is required",
+						},
+					},
+					{
+						Name:     "greeter.v1.Greeter/SayHello",
+						Response: map[string]interface{}{"message": "Hello!"},
+					},
+					{
+						Name:  "greeter.v1.Greeter/SayHellos",
+						Delay: "10ms",
+						Stream: []interface{}{
+							map[string]interface{}{"message": "Hello", "count": 1},
+							map[string]interface{}{"message": "Hello again", "count": 2},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	specWebSocket = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{},
+		RESTMocks: []RESTMock{},
+		WebSocketMocks: []WebSocketMock{
+			WebSocketMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/feed",
+				},
+				Script: []WebSocketStep{
+					{Send: map[string]interface{}{"type": "hello"}},
+					{On: "^ping$", Send: "pong"},
+					{Every: "1s", Send: map[string]interface{}{"type": "tick"}},
+					{On: "^bye$", Close: &WebSocketClose{Code: 4000, Reason: "goodbye"}},
+				},
+			},
+		},
+	}
+
+	specSSE = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/notifications",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 200,
+					SSE: &SSEStream{
+						Loop: true,
+						Events: []SSEEvent{
+							{
+								ID:    "1",
+								Event: "notification",
+								Data:  map[string]interface{}{"message": "You have a new follower"},
+								Retry: 3000,
+							},
+							{
+								ID:    "2",
+								Delay: "5s",
+								Data:  "ping",
+							},
+						},
+					},
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specCallbacks = &Spec{
+		Config: Config{
+			HTTPPort:  8080,
+			HTTPSPort: 8443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST"},
+					Path:    "/payments",
+				},
+				HTTPResponse: &HTTPResponse{
+					StatusCode: 202,
+				},
+				Callbacks: []HTTPCallback{
+					{
+						Method: "POST",
+						URL:    `http://localhost:9000/webhooks/payments?id={{ .Query "id" }}`,
+						Headers: map[string]string{
+							"X-Request-ID": `{{ .Header "X-Request-ID" }}`,
+						},
+						Body: map[string]interface{}{
+							"payment": `{{ .JSON "id" }}`,
+							"status":  "succeeded",
+						},
+						Delay:   "2s",
+						Retries: 3,
+						Backoff: "500ms",
+						Signature: &CallbackSignature{
+							Secret:    "webhook-secret",
+							Header:    "X-Signature",
+							Algorithm: "sha256",
+						},
+					},
+				},
+			},
+		},
+		RESTMocks: []RESTMock{},
+	}
+
+	specFull = &Spec{
+		Config: Config{
+			HTTPPort:  9080,
+			HTTPSPort: 9443,
+		},
+		HTTPMocks: []HTTPMock{
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/health",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "",
+					StatusCode: 200,
+					Headers:    nil,
+					Body:       nil,
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"GET"},
+					Path:    "/app",
+					Prefix:  false,
+					Queries: nil,
+					Headers: nil,
+				},
+				HTTPForward: &HTTPForward{
+					Delay: "",
+					To:    "http://example.com",
+					Headers: map[string]string{
+						"Is-Test": "true",
+					},
+				},
+			},
+			HTTPMock{
+				HTTPExpect: HTTPExpect{
+					Methods: []string{"POST", "PUT"},
+					Path:    "/api/v1/sendMessage",
+					Prefix:  false,
+					Queries: map[string]string{
+						"tenantId": "[0-9A-Fa-f-]+",
+						"groupId":  "[0-9A-Fa-f-]+",
+					},
+					Headers: map[string]string{
+						"Accept":        "application/json",
+						"Content-Type":  "application/json",
+						"Authorization": "Bearer .*",
+					},
+				},
+				HTTPResponse: &HTTPResponse{
+					Delay:      "10ms",
+					StatusCode: 201,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: map[string]interface{}{
+						"id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+					},
+				},
+			},
+		},
+		RESTMocks: []RESTMock{
+			RESTMock{
+				RESTExpect: RESTExpect{
+					BasePath: "/api/v1/teams",
+					Headers: map[string]string{
+						"Accept":        "application/json",
+						"Content-Type":  "application/json",
+						"Authorization": "Bearer .*",
+					},
+				},
+				RESTResponse: RESTResponse{
+					Delay:            "10ms",
+					GetStatusCode:    200,
+					PostStatusCode:   201,
+					PutStatusCode:    200,
+					PatchStatusCode:  200,
+					DeleteStatusCode: 204,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					ListKey: "data",
+				},
+				RESTStore: RESTStore{
+					Identifier: "_id",
+					Objects: []JSON{
+						{"_id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "name": "Back-end"},
+						{"_id": "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "name": "Front-end"},
+					},
+					Directory: map[interface{}]JSON{
+						"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa": {"_id": "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "name": "Back-end"},
+						"bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb": {"_id": "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "name": "Front-end"},
+					},
+				},
+			},
+		},
+	}
+)
+
+func TestDefaultSpec(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectedSpec *Spec
+	}{
+		{
+			"OK",
+			defaultSpec,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := DefaultSpec()
+			assert.Equal(t, tc.expectedSpec, spec)
+		})
+	}
+}
+
+func TestReadSpec(t *testing.T) {
+	specVars := func(upstream string) *Spec {
+		return &Spec{
+			Config: Config{
+				HTTPPort:  9080,
+				HTTPSPort: 9443,
+			},
+			HTTPMocks: []HTTPMock{
+				HTTPMock{
+					HTTPExpect: HTTPExpect{
+						Methods: []string{"GET"},
+						Path:    "/app",
+					},
+					HTTPForward: &HTTPForward{
+						To: "http://" + upstream,
+						Headers: map[string]string{
+							"Authorization": "Bearer secret",
+							"Price":         "$10",
+						},
+					},
+				},
+			},
+			RESTMocks: []RESTMock{},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		vars          map[string]string
+		expectedError string
+		expectedSpec  *Spec
+	}{
+		{
+			name:          "NoFile",
+			path:          "./test/spec",
+			expectedError: "",
+			expectedSpec:  defaultSpec,
+		},
+		{
+			name:          "UnknownFormat",
+			path:          "./test/unknown",
+			expectedError: "unknown spec file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "EmptyJSON",
+			path:          "./test/empty.json",
+			expectedError: "unknown spec file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "EmptyYAML",
+			path:          "./test/empty.yaml",
+			expectedError: "unknown spec file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "InvalidJSON",
+			path:          "./test/invalid.json",
+			expectedError: "unknown spec file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "InvalidYAML",
+			path:          "./test/invalid.yaml",
+			expectedError: "unknown spec file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "SimpleJSON",
+			path:          "./test/simple.json",
+			expectedError: "",
+			expectedSpec:  specSimple,
+		},
+		{
+			name:          "SimpleYAML",
+			path:          "./test/simple.yaml",
+			expectedError: "",
+			expectedSpec:  specSimple,
+		},
+		{
+			name:          "FullJSON",
+			path:          "./test/full.json",
+			expectedError: "",
+			expectedSpec:  specFull,
+		},
+		{
+			name:          "FullYAML",
+			path:          "./test/full.yaml",
+			expectedError: "",
+			expectedSpec:  specFull,
+		},
+		{
+			name:          "Bodies",
+			path:          "./test/bodies.yaml",
+			expectedError: "",
+			expectedSpec:  specBodies,
+		},
+		{
+			name:          "InvalidBody",
+			path:          "./test/invalid_body.yaml",
+			expectedError: "invalid mock GET /documents/1 in ./test/invalid_body.yaml: invalid body_file",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "Fallback",
+			path:          "./test/fallback/flax.yaml",
+			expectedError: "",
+			expectedSpec:  specFallback,
+		},
+		{
+			name:          "Listeners",
+			path:          "./test/listeners",
+			expectedError: "",
+			expectedSpec:  specListeners,
+		},
+		{
+			name:          "InvalidListener",
+			path:          "./test/invalid_listener.yaml",
+			expectedError: "invalid mock identity: GET /health in ./test/invalid_listener.yaml: unknown listener identity",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "Auth",
+			path:          "./test/auth/flax.yaml",
+			expectedError: "",
+			expectedSpec:  specAuth,
+		},
+		{
+			name:          "InvalidAuth",
+			path:          "./test/invalid_auth.yaml",
+			expectedError: "invalid mock GET /health in ./test/invalid_auth.yaml: invalid auth: exactly one of api key header or query should be set",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "OIDC",
+			path:          "./test/oidc.yaml",
+			expectedError: "",
+			expectedSpec:  specOIDC,
+		},
+		{
+			name:          "RateLimit",
+			path:          "./test/ratelimit.yaml",
+			expectedError: "",
+			expectedSpec:  specRateLimit,
+		},
+		{
+			name:          "Chaos",
+			path:          "./test/chaos.yaml",
+			expectedError: "",
+			expectedSpec:  specChaos,
+		},
+		{
+			name:          "SOAP",
+			path:          "./test/soap.yaml",
+			expectedError: "",
+			expectedSpec:  specSOAP,
+		},
+		{
+			name:          "GraphQL",
+			path:          "./test/graphql.yaml",
+			expectedError: "",
+			expectedSpec:  specGraphQL,
+		},
+		{
+			name:          "GRPC",
+			path:          "./test/grpc.yaml",
+			expectedError: "",
+			expectedSpec:  specGRPC,
+		},
+		{
+			name:          "WebSocket",
+			path:          "./test/websocket.yaml",
+			expectedError: "",
+			expectedSpec:  specWebSocket,
+		},
+		{
+			name:          "SSE",
+			path:          "./test/sse.yaml",
+			expectedError: "",
+			expectedSpec:  specSSE,
+		},
+		{
+			name:          "Callbacks",
+			path:          "./test/callbacks.yaml",
+			expectedError: "",
+			expectedSpec:  specCallbacks,
+		},
+		{
+			name:          "InvalidUnmatched",
+			path:          "./test/invalid_unmatched.yaml",
+			expectedError: "invalid config in ./test/invalid_unmatched.yaml: unknown unmatched format: xml",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "CORS",
+			path:          "./test/cors.yaml",
+			expectedError: "",
+			expectedSpec:  specCORS,
+		},
+		{
+			name:          "Directory",
+			path:          "./test/dir",
+			expectedError: "",
+			expectedSpec:  specDir,
+		},
+		{
+			name:          "DirectoryWithoutSpec",
+			path:          "./test/nospec",
+			expectedError: "no spec file found in ./test/nospec",
+			expectedSpec:  nil,
+		},
+		{
+			name:          "Include",
+			path:          "./test/include.yaml",
+			expectedError: "",
+			expectedSpec:  specSimple,
+		},
+		{
+			name:          "IncludeMissing",
+			path:          "./test/include_missing.yaml",
+			expectedError: `include "test/missing/*.yaml" in ./test/include_missing.yaml matches no file`,
+			expectedSpec:  nil,
+		},
+		{
+			name:          "ConflictingMocks",
+			path:          "./test/conflict",
+			expectedError: "conflicting mocks: GET /health in test/conflict/search.yaml conflicts with GET /health in test/conflict/payments.yaml",
+			expectedSpec:  nil,
+		},
+		{
+			name: "Vars",
+			path: "./test/vars.yaml",
+			vars: map[string]string{
+				"TOKEN": "secret",
+			},
+			expectedError: "",
+			expectedSpec:  specVars("example.com"),
+		},
+		{
+			name: "VarsOverride",
+			path: "./test/vars.yaml",
+			vars: map[string]string{
+				"TOKEN":    "secret",
+				"upstream": "payments:8080",
+			},
+			expectedError: "",
+			expectedSpec:  specVars("payments:8080"),
+		},
+		{
+			name:          "VarsUndefined",
+			path:          "./test/vars.yaml",
+			vars:          nil,
+			expectedError: `./test/vars.yaml: variable "TOKEN" is not defined`,
+			expectedSpec:  nil,
+		},
+		{
+			name:          "ConflictingConfigs",
+			path:          "./test/config_conflict",
+			expectedError: "config is defined in both test/config_conflict/a.yaml and test/config_conflict/b.yaml",
+			expectedSpec:  nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := ReadSpec(tc.path, tc.vars)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSpec, spec)
+			} else {
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package spec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEEvent represents an event of a server-sent events stream.
+// A string Data is sent as is, and any other Data is sent as JSON.
+// Retry is the reconnection time in milliseconds.
+type SSEEvent struct {
+	Delay string      `json:"delay" yaml:"delay"`
+	Event string      `json:"event" yaml:"event"`
+	ID    string      `json:"id" yaml:"id"`
+	Data  interface{} `json:"data" yaml:"data"`
+	Retry int         `json:"retry" yaml:"retry"`
+}
+
+// data returns the data of the event as text.
+func (e SSEEvent) data() (string, error) {
+	switch v := e.Data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// Validate checks whether or not the event is valid.
+func (e SSEEvent) Validate() error {
+	if e.Delay != "" {
+		if _, err := time.ParseDuration(e.Delay); err != nil {
+			return fmt.Errorf("invalid delay: %s", err)
+		}
+	}
+
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("event cannot contain line breaks")
+	}
+
+	if strings.ContainsAny(e.ID, "\r\n\x00") {
+		return errors.New("id cannot contain line breaks or null characters")
+	}
+
+	if e.Retry < 0 {
+		return errors.New("retry cannot be negative")
+	}
+
+	if _, err := e.data(); err != nil {
+		return fmt.Errorf("invalid data: %s", err)
+	}
+
+	return nil
+}
+
+// String returns the event in the text/event-stream format.
+// Every line of data is sent in a separate data field.
+func (e SSEEvent) String() string {
+	var b strings.Builder
+
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.Itoa(e.Retry) + "\n")
+	}
+
+	if e.Data != nil {
+		data, _ := e.data()
+		data = strings.Replace(data, "\r\n", "\n", -1)
+		for _, line := range strings.Split(data, "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// SSEStream represents a server-sent events (SSE) stream.
+// Events are sent in order, each after its delay, and then the stream ends.
+// If Loop is set, the events are sent again from the beginning instead.
+// A client reconnecting with a Last-Event-ID header resumes the stream after the event with that id.
+type SSEStream struct {
+	Events []SSEEvent `json:"events" yaml:"events"`
+	Loop   bool       `json:"loop" yaml:"loop"`
+}
+
+// Validate checks whether or not the stream is valid.
+func (s *SSEStream) Validate() error {
+	if len(s.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+
+	var total time.Duration
+	for i, e := range s.Events {
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("invalid event %d: %s", i+1, err)
+		}
+
+		d, _ := time.ParseDuration(e.Delay)
+		total += d
+	}
+
+	if s.Loop && total <= 0 {
+		return errors.New("a looping stream requires a delay")
+	}
+
+	return nil
+}
+
+// resume returns the index of the event after the event with an id.
+// If no event has the id, the stream starts from the beginning.
+func (s *SSEStream) resume(id string) int {
+	if id == "" {
+		return 0
+	}
+
+	for i, e := range s.Events {
+		if e.ID == id {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// write sends the events to a client and flushes every event.
+// It returns once all events are sent, or when the request context is canceled
+// (i.e. the client disconnects or the server shuts down).
+func (s *SSEStream) write(w http.ResponseWriter, r *http.Request) {
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	// The headers are sent right away, so clients know the stream is open.
+	flush()
+
+	ctx := r.Context()
+
+	for i := s.resume(r.Header.Get("Last-Event-ID")); ; i++ {
+		if i >= len(s.Events) {
+			if !s.Loop {
+				return
+			}
+			i = 0
+		}
+
+		e := s.Events[i]
+
+		delay, _ := time.ParseDuration(e.Delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		if _, err := io.WriteString(w, e.String()); err != nil {
+			return
+		}
+
+		flush()
+	}
+}
@@ -0,0 +1,239 @@
+package spec
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEEventString(t *testing.T) {
+	tests := []struct {
+		name           string
+		event          SSEEvent
+		expectedString string
+	}{
+		{
+			name:           "Empty",
+			event:          SSEEvent{},
+			expectedString: "\n",
+		},
+		{
+			name:           "Text",
+			event:          SSEEvent{Data: "hello"},
+			expectedString: "data: hello\n\n",
+		},
+		{
+			name:           "MultiLine",
+			event:          SSEEvent{Data: "hello\r\nworld\n"},
+			expectedString: "data: hello\ndata: world\ndata: \n\n",
+		},
+		{
+			name:           "JSON",
+			event:          SSEEvent{Event: "update", ID: "42", Retry: 3000, Data: JSON{"count": 1}},
+			expectedString: "event: update\nid: 42\nretry: 3000\ndata: {\"count\":1}\n\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedString, tc.event.String())
+		})
+	}
+}
+
+func TestSSEStreamValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		stream        SSEStream
+		expectedError string
+	}{
+		{
+			name:          "NoEvents",
+			stream:        SSEStream{},
+			expectedError: "at least one event is required",
+		},
+		{
+			name:          "InvalidDelay",
+			stream:        SSEStream{Events: []SSEEvent{{Delay: "soon"}}},
+			expectedError: `invalid event 1: invalid delay: time: invalid duration "soon"`,
+		},
+		{
+			name:          "InvalidEvent",
+			stream:        SSEStream{Events: []SSEEvent{{Data: "a"}, {Event: "a\nb"}}},
+			expectedError: "invalid event 2: event cannot contain line breaks",
+		},
+		{
+			name:          "InvalidID",
+			stream:        SSEStream{Events: []SSEEvent{{ID: "1\n"}}},
+			expectedError: "invalid event 1: id cannot contain line breaks or null characters",
+		},
+		{
+			name:          "NegativeRetry",
+			stream:        SSEStream{Events: []SSEEvent{{Retry: -1}}},
+			expectedError: "invalid event 1: retry cannot be negative",
+		},
+		{
+			name:          "InvalidData",
+			stream:        SSEStream{Events: []SSEEvent{{Data: JSON{"f": func() {}}}}},
+			expectedError: "invalid event 1: invalid data: json: unsupported type: func()",
+		},
+		{
+			name:          "LoopWithoutDelay",
+			stream:        SSEStream{Events: []SSEEvent{{Data: "a"}}, Loop: true},
+			expectedError: "a looping stream requires a delay",
+		},
+		{
+			name:   "Valid",
+			stream: SSEStream{Events: []SSEEvent{{Data: "a", Delay: "1s"}}, Loop: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.stream.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestHTTPResponseValidateSSE(t *testing.T) {
+	r := &HTTPResponse{
+		BodyText: "hello",
+		SSE:      &SSEStream{Events: []SSEEvent{{Data: "a"}}},
+	}
+
+	assert.EqualError(t, r.Validate(), "sse cannot be set along with body, body_file, body_text, body_base64, body_xml, content, or soap")
+}
+
+// readSSE reads a number of events from a server-sent events stream.
+func readSSE(r *bufio.Reader, n int) []string {
+	events := []string{}
+	var event strings.Builder
+
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			break
+		}
+
+		event.WriteString(line)
+		if line == "\n" {
+			events = append(events, event.String())
+			event.Reset()
+		}
+	}
+
+	return events
+}
+
+func TestHTTPResponseWriteSSE(t *testing.T) {
+	done := make(chan struct{}, 1)
+
+	newServer := func(stream *SSEStream) *httptest.Server {
+		r := &HTTPResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"X-Stream": "news"},
+			SSE:        stream,
+		}
+
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.Write(w, req)
+			done <- struct{}{}
+		}))
+	}
+
+	t.Run("Events", func(t *testing.T) {
+		ts := newServer(&SSEStream{
+			Events: []SSEEvent{
+				{ID: "1", Data: "one"},
+				{ID: "2", Data: "two", Delay: "10ms"},
+				{ID: "3", Event: "end", Data: JSON{"n": 3}},
+			},
+		})
+		defer ts.Close()
+
+		res, err := http.Get(ts.URL)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+		assert.Equal(t, "news", res.Header.Get("X-Stream"))
+
+		events := readSSE(bufio.NewReader(res.Body), 4)
+		assert.Equal(t, []string{
+			"id: 1\ndata: one\n\n",
+			"id: 2\ndata: two\n\n",
+			"event: end\nid: 3\ndata: {\"n\":3}\n\n",
+		}, events)
+
+		<-done
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		ts := newServer(&SSEStream{
+			Events: []SSEEvent{
+				{ID: "1", Data: "one"},
+				{ID: "2", Data: "two"},
+				{ID: "3", Data: "three"},
+			},
+		})
+		defer ts.Close()
+
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.Header.Set("Last-Event-ID", "2")
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		events := readSSE(bufio.NewReader(res.Body), 2)
+		assert.Equal(t, []string{"id: 3\ndata: three\n\n"}, events)
+
+		<-done
+	})
+
+	t.Run("LoopUntilDisconnect", func(t *testing.T) {
+		ts := newServer(&SSEStream{
+			Events: []SSEEvent{
+				{ID: "1", Data: "one", Delay: "1ms"},
+				{ID: "2", Data: "two", Delay: "1ms"},
+			},
+			Loop: true,
+		})
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+		req.Header.Set("Last-Event-ID", "1")
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		events := readSSE(bufio.NewReader(res.Body), 3)
+		assert.Equal(t, []string{
+			"id: 2\ndata: two\n\n",
+			"id: 1\ndata: one\n\n",
+			"id: 2\ndata: two\n\n",
+		}, events)
+
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "stream not stopped after the client disconnected")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// FormatJSON is the format for responding to unmatched requests with a json body.
+	FormatJSON = "json"
+	// FormatText is the format for responding to unmatched requests with a plain text body.
+	FormatText = "text"
+
+	defaultClosest = 3
+)
+
+// Unmatched represents the configurations for responding to requests not matching any mock.
+// If StatusCode is not set, 404 or 405 is used depending on whether or not the path of any mock matches the request.
+type Unmatched struct {
+	StatusCode int    `json:"status" yaml:"status"`
+	Format     string `json:"format" yaml:"format"`
+	Closest    int    `json:"closest" yaml:"closest"`
+}
+
+// UnmatchedResponse is the response body for a request not matching any mock.
+type UnmatchedResponse struct {
+	Message string      `json:"message" yaml:"message"`
+	Method  string      `json:"method" yaml:"method"`
+	Path    string      `json:"path" yaml:"path"`
+	Closest []Diagnosis `json:"closest" yaml:"closest"`
+}
+
+// Validate checks whether or not the configurations are valid.
+func (u *Unmatched) Validate() error {
+	if u == nil {
+		return nil
+	}
+
+	if u.Format != "" && u.Format != FormatJSON && u.Format != FormatText {
+		return fmt.Errorf("unknown unmatched format: %s", u.Format)
+	}
+
+	return nil
+}
+
+// Respond diagnoses a request not matching any mock and writes a response listing the closest mocks.
+// statusCode is used if no status code is configured.
+// It returns the response so it can be logged as well.
+func (u *Unmatched) Respond(w http.ResponseWriter, r Request, statusCode int, diagnoses []Diagnosis) UnmatchedResponse {
+	format, closest := FormatJSON, defaultClosest
+	if u != nil {
+		if u.StatusCode != 0 {
+			statusCode = u.StatusCode
+		}
+		if u.Format != "" {
+			format = u.Format
+		}
+		if u.Closest != 0 {
+			closest = u.Closest
+		}
+	}
+
+	res := UnmatchedResponse{
+		Message: "no mock matched the request",
+		Method:  r.Method,
+		Path:    r.Path,
+		Closest: Closest(diagnoses, closest),
+	}
+
+	if format == FormatText {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(res.String()))
+	} else {
+		writeJSON(w, statusCode, res)
+	}
+
+	return res
+}
+
+func (r UnmatchedResponse) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: %s %s\n", r.Message, r.Method, r.Path)
+	for _, d := range r.Closest {
+		fmt.Fprintf(&b, "\n%s (score %.2f)\n", d.Mock, d.Score)
+		for _, m := range d.Mismatches {
+			fmt.Fprintf(&b, "  %s\n", m)
+		}
+	}
+
+	return b.String()
+}
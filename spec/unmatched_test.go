@@ -0,0 +1,111 @@
+package spec
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmatchedValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		unmatched     *Unmatched
+		expectedError string
+	}{
+		{"Nil", nil, ""},
+		{"JSON", &Unmatched{Format: "json"}, ""},
+		{"Text", &Unmatched{Format: "text"}, ""},
+		{"InvalidFormat", &Unmatched{Format: "xml"}, "unknown unmatched format: xml"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.unmatched.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestUnmatchedRespond(t *testing.T) {
+	request := Request{Method: "GET", Path: "/api/v1/message"}
+
+	diagnoses := []Diagnosis{
+		{
+			Mock:  "GET /api/v1/messages",
+			Score: 0.4,
+			Mismatches: []Mismatch{
+				{Criterion: "path", Expected: "/api/v1/messages", Actual: "/api/v1/message"},
+			},
+		},
+		{
+			Mock:  "POST /api/v1/message",
+			Score: 0.6,
+			Mismatches: []Mismatch{
+				{Criterion: "method", Expected: "POST", Actual: "GET"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		unmatched           *Unmatched
+		statusCode          int
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+		expectedClosest     int
+	}{
+		{
+			name:                "Default",
+			unmatched:           nil,
+			statusCode:          405,
+			expectedStatusCode:  405,
+			expectedContentType: "application/json",
+			expectedBody: `{
+				"message": "no mock matched the request",
+				"method": "GET",
+				"path": "/api/v1/message",
+				"closest": [
+					{"mock": "POST /api/v1/message", "score": 0.6, "mismatches": [{"criterion": "method", "expected": "POST", "actual": "GET"}]},
+					{"mock": "GET /api/v1/messages", "score": 0.4, "mismatches": [{"criterion": "path", "expected": "/api/v1/messages", "actual": "/api/v1/message"}]}
+				]
+			}`,
+			expectedClosest: 2,
+		},
+		{
+			name:                "Text",
+			unmatched:           &Unmatched{StatusCode: 418, Format: "text", Closest: 1},
+			statusCode:          404,
+			expectedStatusCode:  418,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "no mock matched the request: GET /api/v1/message\n\nPOST /api/v1/message (score 0.60)\n  method: expected \"POST\", got \"GET\"\n",
+			expectedClosest:     1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in := make([]Diagnosis, len(diagnoses))
+			copy(in, diagnoses)
+
+			res := httptest.NewRecorder()
+			r := tc.unmatched.Respond(res, request, tc.statusCode, in)
+
+			assert.Equal(t, tc.expectedStatusCode, res.Result().StatusCode)
+			assert.Equal(t, tc.expectedContentType, res.Result().Header.Get("Content-Type"))
+			assert.Len(t, r.Closest, tc.expectedClosest)
+
+			if tc.unmatched == nil || tc.unmatched.Format != FormatText {
+				assert.JSONEq(t, tc.expectedBody, res.Body.String())
+			} else {
+				assert.Equal(t, tc.expectedBody, res.Body.String())
+			}
+		})
+	}
+}
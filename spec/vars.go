@@ -0,0 +1,113 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// varRegexp matches $$, ${NAME}, and ${NAME:-default} in spec files.
+var varRegexp = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][0-9A-Za-z_.-]*)(:-([^}]*))?\}`)
+
+// ParseVars parses a list of NAME=VALUE pairs into a map of variables.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+
+		i := strings.Index(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid variable %q: expected NAME=VALUE", pair)
+		}
+		vars[pair[:i]] = pair[i+1:]
+	}
+
+	return vars, nil
+}
+
+// lookupFunc looks up the value of a variable.
+type lookupFunc func(name string) (string, bool)
+
+// chain creates a lookupFunc which tries a list of lookupFuncs in order.
+func chain(lookups ...lookupFunc) lookupFunc {
+	return func(name string) (string, bool) {
+		for _, lookup := range lookups {
+			if val, ok := lookup(name); ok {
+				return val, true
+			}
+		}
+		return "", false
+	}
+}
+
+func mapLookup(vars map[string]string) lookupFunc {
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	}
+}
+
+// interpolate expands ${NAME} and ${NAME:-default} variables in data using the lookup function.
+// $$ is an escaped $.
+// If strict is true, a variable that cannot be resolved and has no default value is an error.
+// Otherwise, it is left unchanged.
+func interpolate(data []byte, lookup lookupFunc, strict bool) ([]byte, error) {
+	var err error
+
+	res := varRegexp.ReplaceAllFunc(data, func(match []byte) []byte {
+		if string(match) == "$$" {
+			return []byte("$")
+		}
+
+		sub := varRegexp.FindSubmatch(match)
+		name, hasDefault, def := string(sub[1]), len(sub[2]) > 0, sub[3]
+
+		if val, ok := lookup(name); ok {
+			return []byte(val)
+		}
+
+		if hasDefault {
+			return def
+		}
+
+		if strict && err == nil {
+			err = fmt.Errorf("variable %q is not defined", name)
+		}
+
+		return match
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// expand expands variables in the content of a spec file.
+// Variables are resolved from command-line variables first, then the vars section of spec files, then environment variables.
+func (l *loader) expand(path string, data []byte) ([]byte, error) {
+	// The vars section can only refer to command-line variables and environment variables.
+	if varsData, err := interpolate(data, chain(mapLookup(l.overrides), os.LookupEnv), false); err == nil {
+		s := new(struct {
+			Vars map[string]string `json:"vars" yaml:"vars"`
+		})
+		if err := decodeBytes(varsData, s); err == nil {
+			for name, val := range s.Vars {
+				if _, ok := l.vars[name]; !ok {
+					l.vars[name] = val
+				}
+			}
+		}
+	}
+
+	data, err := interpolate(data, chain(mapLookup(l.overrides), mapLookup(l.vars), os.LookupEnv), true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	return data, nil
+}
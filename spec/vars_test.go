@@ -0,0 +1,119 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVars(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         []string
+		expectedVars  map[string]string
+		expectedError string
+	}{
+		{
+			name:          "Empty",
+			pairs:         []string{""},
+			expectedVars:  map[string]string{},
+			expectedError: "",
+		},
+		{
+			name:  "OK",
+			pairs: []string{"host=payments", "token=a=b", "empty="},
+			expectedVars: map[string]string{
+				"host":  "payments",
+				"token": "a=b",
+				"empty": "",
+			},
+			expectedError: "",
+		},
+		{
+			name:          "Invalid",
+			pairs:         []string{"host"},
+			expectedVars:  nil,
+			expectedError: `invalid variable "host": expected NAME=VALUE`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vars, err := ParseVars(tc.pairs)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVars, vars)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, vars)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	lookup := chain(
+		mapLookup(map[string]string{"HOST": "payments"}),
+		mapLookup(map[string]string{"HOST": "identity", "PORT": "8080"}),
+	)
+
+	tests := []struct {
+		name          string
+		data          string
+		strict        bool
+		expectedData  string
+		expectedError string
+	}{
+		{
+			name:         "NoVariable",
+			data:         "to: http://example.com",
+			strict:       true,
+			expectedData: "to: http://example.com",
+		},
+		{
+			name:         "Variables",
+			data:         "to: http://${HOST}:${PORT}",
+			strict:       true,
+			expectedData: "to: http://payments:8080",
+		},
+		{
+			name:         "Defaults",
+			data:         "to: http://${UPSTREAM:-search}:${PORT:-9090}${PATH_PREFIX:-}",
+			strict:       true,
+			expectedData: "to: http://search:8080",
+		},
+		{
+			name:         "Escaped",
+			data:         "price: $$10 and $${HOST}",
+			strict:       true,
+			expectedData: "price: $10 and ${HOST}",
+		},
+		{
+			name:         "UndefinedLenient",
+			data:         "token: ${TOKEN}",
+			strict:       false,
+			expectedData: "token: ${TOKEN}",
+		},
+		{
+			name:          "UndefinedStrict",
+			data:          "token: ${TOKEN}",
+			strict:        true,
+			expectedError: `variable "TOKEN" is not defined`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := interpolate([]byte(tc.data), lookup, tc.strict)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedData, string(data))
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package spec
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketMethod is the method of the journal entries for messages received by websocket mocks.
+const WebSocketMethod = "WEBSOCKET"
+
+// websocketCloseTimeout is how long a websocket mock waits for the client to acknowledge closing a connection.
+const websocketCloseTimeout = time.Second
+
+// WithRecorder returns a new context which records requests received outside of http requests (i.e. websocket messages).
+func WithRecorder(ctx context.Context, f func(Request)) context.Context {
+	return context.WithValue(ctx, recorderKey, f)
+}
+
+func record(ctx context.Context, r Request) {
+	if f, ok := ctx.Value(recorderKey).(func(Request)); ok {
+		f(r)
+	}
+}
+
+// WebSocketClose represents closing a websocket connection with a status code.
+type WebSocketClose struct {
+	Code   int    `json:"code" yaml:"code"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// WebSocketStep is a step in the script of a websocket mock.
+// A step with neither On nor Every runs once the connection is opened, and these steps run in order.
+// A step with On runs whenever a received message matches its regular expression, and only the first matching step runs.
+// A step with Every runs periodically until the connection is closed.
+// A step waits for Delay, then sends Send if it is set, and then closes the connection if Close is set.
+// A string message is sent as is, and any other message is sent as JSON.
+type WebSocketStep struct {
+	On    string          `json:"on" yaml:"on"`
+	Every string          `json:"every" yaml:"every"`
+	Delay string          `json:"delay" yaml:"delay"`
+	Send  interface{}     `json:"send" yaml:"send"`
+	Close *WebSocketClose `json:"close" yaml:"close"`
+}
+
+// Validate checks whether or not the step is valid.
+func (s WebSocketStep) Validate() error {
+	if s.On != "" && s.Every != "" {
+		return errors.New("only one of on or every can be set")
+	}
+
+	if s.On != "" {
+		if _, err := regexp.Compile(s.On); err != nil {
+			return fmt.Errorf("invalid on: %s", err)
+		}
+	}
+
+	if s.Every != "" {
+		if d, err := time.ParseDuration(s.Every); err != nil {
+			return fmt.Errorf("invalid every: %s", err)
+		} else if d <= 0 {
+			return errors.New("invalid every: must be positive")
+		}
+
+		if s.Close != nil {
+			return errors.New("close cannot be set with every")
+		}
+	}
+
+	if s.Delay != "" {
+		if _, err := time.ParseDuration(s.Delay); err != nil {
+			return fmt.Errorf("invalid delay: %s", err)
+		}
+	}
+
+	if s.Send == nil && s.Close == nil {
+		return errors.New("one of send or close is required")
+	}
+
+	if _, err := s.message(); err != nil {
+		return fmt.Errorf("invalid send: %s", err)
+	}
+
+	return nil
+}
+
+// message returns the text message sent by the step.
+func (s WebSocketStep) message() ([]byte, error) {
+	switch v := s.Send.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// WebSocketMock represents a websocket mock.
+// Requests matching the expectation are upgraded to websocket connections, and then the script runs on the connection.
+// Every message received on the connection is recorded in the journal.
+type WebSocketMock struct {
+	HTTPExpect `json:",inline" yaml:",inline"`
+	Script     []WebSocketStep `json:"script" yaml:"script"`
+	Auth       *Auth           `json:"auth" yaml:"auth"`
+	RateLimit  *RateLimit      `json:"rateLimit" yaml:"rate_limit"`
+	Priority   int             `json:"priority" yaml:"priority"`
+	Tags       []string        `json:"tags" yaml:"tags"`
+
+	chaos *Chaos
+}
+
+// SetDefaults set default values for empty fields.
+func (m *WebSocketMock) SetDefaults() {
+	if len(m.HTTPExpect.Methods) == 0 {
+		m.HTTPExpect.Methods = []string{"GET"}
+	}
+
+	m.HTTPExpect.Path = path.Clean("/" + m.HTTPExpect.Path)
+
+	for i := range m.Script {
+		if c := m.Script[i].Close; c != nil && c.Code == 0 {
+			c.Code = websocket.CloseNormalClosure
+		}
+	}
+
+	m.RateLimit.SetDefaults()
+}
+
+// Inherit sets the spec-wide configurations not overridden by the mock.
+func (m *WebSocketMock) Inherit(c Config) {
+	if m.Auth == nil {
+		m.Auth = c.Auth
+	}
+
+	if m.RateLimit == nil {
+		m.RateLimit = c.RateLimit
+	}
+
+	if c.Chaos.Selects(m.Tags) {
+		m.chaos = c.Chaos
+	}
+}
+
+// Validate checks whether or not the mock is valid.
+func (m *WebSocketMock) Validate() error {
+	if err := m.HTTPExpect.Validate(); err != nil {
+		return err
+	}
+
+	for i, s := range m.Script {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("invalid script step %d: %s", i+1, err)
+		}
+	}
+
+	if err := m.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %s", err)
+	}
+
+	if err := m.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %s", err)
+	}
+
+	return nil
+}
+
+// String returns a string representation of the mock.
+func (m WebSocketMock) String() string {
+	return withListener(m.HTTPExpect.Listener, "WebSocket "+m.HTTPExpect.Host+m.HTTPExpect.Path)
+}
+
+// Hash calculates a hash for a websocket mock based on the http expectation.
+func (m WebSocketMock) Hash() uint64 {
+	h := fnv.New64a()
+
+	hashString(h, "websocket")
+	m.HTTPExpect.hash(h)
+
+	return h.Sum64()
+}
+
+// Rank returns the rank of the mock for ordering overlapping mocks.
+// Websocket upgrades count as a criterion, so websocket mocks take precedence over http mocks of the same path.
+func (m WebSocketMock) Rank() Rank {
+	return Rank{
+		Priority:    m.Priority,
+		Specificity: specificity(!m.HTTPExpect.Prefix, m.HTTPExpect.Path, m.HTTPExpect.criteria()+1),
+	}
+}
+
+// Diagnose scores a recorded request against the mock.
+// Mocks of other listeners are not considered at all.
+func (m WebSocketMock) Diagnose(r Request) Diagnosis {
+	if m.HTTPExpect.Listener != r.Listener {
+		return listenerMismatch(m.String(), m.HTTPExpect.Listener, r)
+	}
+
+	e := m.HTTPExpect
+	e.Headers = map[string]string{"Upgrade": "(?i)^websocket$"}
+	for header, pattern := range m.HTTPExpect.Headers {
+		e.Headers[header] = pattern
+	}
+
+	d := e.Diagnose(r)
+	d.Mock = m.String()
+
+	return d
+}
+
+// wrap wraps a handler of the mock with chaos, rate limit, and auth.
+// Websocket upgrades are not subject to CORS.
+func (m WebSocketMock) wrap(h http.Handler) http.Handler {
+	return wrapHandler(nil, m.chaos, m.RateLimit, m.Auth, h)
+}
+
+// RegisterRoutes configure routes for a websocket mock.
+func (m WebSocketMock) RegisterRoutes(router *mux.Router) {
+	route := m.HTTPExpect.route(router.NewRoute())
+	route.MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+		return websocket.IsWebSocketUpgrade(r)
+	})
+
+	route.Handler(m.wrap(m.handler()))
+}
+
+// handler creates an http handler for upgrading requests and running the script on their connections.
+func (m WebSocketMock) handler() http.Handler {
+	upgrader := websocket.Upgrader{
+		// A mock accepts connections from any origin.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has already responded with an error.
+			return
+		}
+
+		s := &websocketSession{
+			mock:   m,
+			conn:   conn,
+			closed: make(chan struct{}),
+		}
+
+		s.run(r)
+	})
+}
+
+// websocketSession runs the script of a websocket mock on a connection.
+type websocketSession struct {
+	mock   WebSocketMock
+	conn   *websocket.Conn
+	mutex  sync.Mutex
+	once   sync.Once
+	closed chan struct{}
+}
+
+// run runs the script until the connection is closed by either the client or the script.
+func (s *websocketSession) run(r *http.Request) {
+	defer s.conn.Close()
+	defer s.once.Do(func() { close(s.closed) })
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	open := []WebSocketStep{}
+	for _, step := range s.mock.Script {
+		switch {
+		case step.Every != "":
+			wg.Add(1)
+			go func(step WebSocketStep) {
+				defer wg.Done()
+				s.repeat(step)
+			}(step)
+		case step.On == "":
+			open = append(open, step)
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, step := range open {
+			if !s.do(step) {
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.once.Do(func() { close(s.closed) })
+			return
+		}
+
+		body := string(data)
+		if typ == websocket.BinaryMessage {
+			body = base64.StdEncoding.EncodeToString(data)
+		}
+
+		record(r.Context(), Request{
+			Time:     time.Now(),
+			Listener: ListenerFromContext(r.Context()),
+			Host:     r.Host,
+			Method:   WebSocketMethod,
+			Path:     r.URL.Path,
+			Queries:  r.URL.Query(),
+			Headers:  r.Header.Clone(),
+			Body:     body,
+			Mock:     s.mock.String(),
+		})
+
+		for _, step := range s.mock.Script {
+			if step.On != "" && matchAny(step.On, []string{body}) {
+				wg.Add(1)
+				go func(step WebSocketStep) {
+					defer wg.Done()
+					s.do(step)
+				}(step)
+				break
+			}
+		}
+	}
+}
+
+// wait waits for a duration, and returns false if the connection is closed in the meantime.
+func (s *websocketSession) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.closed:
+		return false
+	}
+}
+
+// repeat runs a step periodically until the connection is closed.
+func (s *websocketSession) repeat(step WebSocketStep) {
+	delay, _ := time.ParseDuration(step.Delay)
+	every, _ := time.ParseDuration(step.Every)
+
+	if !s.wait(delay) {
+		return
+	}
+
+	step.Delay = ""
+	for {
+		if !s.do(step) || !s.wait(every) {
+			return
+		}
+	}
+}
+
+// do runs a step, and returns false if the connection is closed.
+func (s *websocketSession) do(step WebSocketStep) bool {
+	delay, _ := time.ParseDuration(step.Delay)
+	if !s.wait(delay) {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	select {
+	case <-s.closed:
+		return false
+	default:
+	}
+
+	if msg, _ := step.message(); msg != nil {
+		if err := s.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			return false
+		}
+	}
+
+	if step.Close != nil {
+		// The client is expected to acknowledge closing the connection, so the read loop ends.
+		data := websocket.FormatCloseMessage(step.Close.Code, step.Close.Reason)
+		_ = s.conn.WriteControl(websocket.CloseMessage, data, time.Now().Add(websocketCloseTimeout))
+		s.once.Do(func() { close(s.closed) })
+		_ = s.conn.SetReadDeadline(time.Now().Add(websocketCloseTimeout))
+		return false
+	}
+
+	return true
+}
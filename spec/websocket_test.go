@@ -0,0 +1,208 @@
+package spec
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketStepValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		step          WebSocketStep
+		expectedError string
+	}{
+		{
+			name:          "Empty",
+			step:          WebSocketStep{},
+			expectedError: "one of send or close is required",
+		},
+		{
+			name:          "OnAndEvery",
+			step:          WebSocketStep{On: "ping", Every: "1s", Send: "pong"},
+			expectedError: "only one of on or every can be set",
+		},
+		{
+			name:          "InvalidOn",
+			step:          WebSocketStep{On: "(", Send: "pong"},
+			expectedError: "invalid on: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:          "InvalidEvery",
+			step:          WebSocketStep{Every: "often", Send: "tick"},
+			expectedError: `invalid every: time: invalid duration "often"`,
+		},
+		{
+			name:          "ZeroEvery",
+			step:          WebSocketStep{Every: "0s", Send: "tick"},
+			expectedError: "invalid every: must be positive",
+		},
+		{
+			name:          "CloseWithEvery",
+			step:          WebSocketStep{Every: "1s", Close: &WebSocketClose{Code: 1000}},
+			expectedError: "close cannot be set with every",
+		},
+		{
+			name:          "InvalidDelay",
+			step:          WebSocketStep{Delay: "soon", Send: "hello"},
+			expectedError: `invalid delay: time: invalid duration "soon"`,
+		},
+		{
+			name:          "InvalidSend",
+			step:          WebSocketStep{Send: map[string]interface{}{"f": func() {}}},
+			expectedError: "invalid send: json: unsupported type: func()",
+		},
+		{
+			name: "Valid",
+			step: WebSocketStep{On: "^bye$", Delay: "10ms", Send: JSON{"type": "bye"}, Close: &WebSocketClose{Code: 1000}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.step.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestWebSocketMockValidate(t *testing.T) {
+	m := WebSocketMock{
+		Script: []WebSocketStep{
+			{Send: "hello"},
+			{On: "ping"},
+		},
+	}
+
+	assert.EqualError(t, m.Validate(), "invalid script step 2: one of send or close is required")
+}
+
+func TestWebSocketMockSetDefaults(t *testing.T) {
+	m := WebSocketMock{
+		HTTPExpect: HTTPExpect{Path: "feed/"},
+		Script: []WebSocketStep{
+			{Close: &WebSocketClose{}},
+		},
+	}
+
+	m.SetDefaults()
+
+	assert.Equal(t, []string{"GET"}, m.HTTPExpect.Methods)
+	assert.Equal(t, "/feed", m.HTTPExpect.Path)
+	assert.Equal(t, websocket.CloseNormalClosure, m.Script[0].Close.Code)
+}
+
+func TestWebSocketMockString(t *testing.T) {
+	m := WebSocketMock{HTTPExpect: HTTPExpect{Host: "api.example.com", Path: "/feed"}}
+	assert.Equal(t, "WebSocket api.example.com/feed", m.String())
+
+	m.HTTPExpect.Listener = "admin"
+	assert.Equal(t, "admin: WebSocket api.example.com/feed", m.String())
+}
+
+func TestWebSocketMockHash(t *testing.T) {
+	e := HTTPExpect{Methods: []string{"GET"}, Path: "/feed"}
+	assert.NotEqual(t, HTTPMock{HTTPExpect: e}.Hash(), WebSocketMock{HTTPExpect: e}.Hash())
+}
+
+func TestWebSocketMockServe(t *testing.T) {
+	m := WebSocketMock{
+		HTTPExpect: HTTPExpect{Path: "/feed"},
+		Script: []WebSocketStep{
+			{Send: JSON{"type": "hello"}},
+			{On: "^ping$", Send: "pong"},
+			{On: "^ping", Send: "never"},
+			{Every: "20ms", Delay: "100ms", Send: "tick"},
+			{On: "^bye$", Send: "bye", Close: &WebSocketClose{Code: 4000, Reason: "goodbye"}},
+		},
+	}
+	m.SetDefaults()
+	assert.NoError(t, m.Validate())
+
+	var mutex sync.Mutex
+	recorded := []Request{}
+
+	router := mux.NewRouter()
+	m.RegisterRoutes(router)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithRecorder(r.Context(), func(r Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			recorded = append(recorded, r)
+		})
+		router.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	defer ts.Close()
+
+	t.Run("NotUpgrade", func(t *testing.T) {
+		res, err := http.Get(ts.URL + "/feed")
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Script", func(t *testing.T) {
+		url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/feed?topic=news"
+		conn, _, err := websocket.DefaultDialer.DialContext(context.Background(), url, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		read := func() string {
+			_, data, err := conn.ReadMessage()
+			assert.NoError(t, err)
+			return string(data)
+		}
+
+		assert.Equal(t, `{"type":"hello"}`, read())
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+		assert.Equal(t, "pong", read())
+
+		assert.Equal(t, "tick", read())
+		assert.Equal(t, "tick", read())
+
+		assert.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("bye")))
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+
+		// Periodic messages may arrive until the connection is closed.
+		var closeErr error
+		msgs := []string{}
+		for closeErr == nil {
+			var data []byte
+			_, data, closeErr = conn.ReadMessage()
+			if closeErr == nil && string(data) != "tick" {
+				msgs = append(msgs, string(data))
+			}
+		}
+
+		assert.Equal(t, []string{"bye"}, msgs)
+		assert.True(t, websocket.IsCloseError(closeErr, 4000))
+		assert.Equal(t, "goodbye", closeErr.(*websocket.CloseError).Text)
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.Len(t, recorded, 3)
+	for _, r := range recorded {
+		assert.Equal(t, WebSocketMethod, r.Method)
+		assert.Equal(t, "/feed", r.Path)
+		assert.Equal(t, []string{"news"}, r.Queries["topic"])
+		assert.Equal(t, "WebSocket /feed", r.Mock)
+	}
+	assert.Equal(t, "ping", recorded[0].Body)
+	assert.Equal(t, "Ynll", recorded[1].Body)
+	assert.Equal(t, "bye", recorded[2].Body)
+}
@@ -0,0 +1,95 @@
+package spec
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/gorilla/mux"
+)
+
+// prefixRegexp matches the string literals and the prefixed names of an xpath expression.
+// Literals are matched so that prefix-like text in them is kept as is.
+var prefixRegexp = regexp.MustCompile(`('[^']*'|"[^"]*")|[A-Za-z_][0-9A-Za-z_.-]*:([A-Za-z_*])`)
+
+// parseXML parses an xml document into a tree of nodes.
+// Namespace prefixes and declarations are removed, so elements and attributes are matched by their local names.
+func parseXML(data []byte) (*xmlquery.Node, error) {
+	doc, err := xmlquery.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	stripPrefixes(doc)
+
+	return doc, nil
+}
+
+// stripPrefixes removes the namespace prefixes and declarations of a node and its descendants.
+func stripPrefixes(n *xmlquery.Node) {
+	n.Prefix = ""
+
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		a.Name.Space = ""
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		stripPrefixes(c)
+	}
+}
+
+// compileXPath compiles an xpath 1.0 expression.
+// Namespace prefixes are ignored, so names are matched by their local names.
+func compileXPath(expr string) (*xpath.Expr, error) {
+	return xpath.Compile(prefixRegexp.ReplaceAllString(expr, "${1}${2}"))
+}
+
+// xpathValues returns the string values of the nodes selected by an xpath expression in an xml document.
+func xpathValues(expr string, data []byte) ([]string, error) {
+	x, err := compileXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := xmlquery.QuerySelectorAll(doc, x)
+
+	values := make([]string, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.InnerText()
+	}
+
+	return values, nil
+}
+
+// xpathMatcher creates a route matcher for an xml request body.
+// For every xpath expression, the string value of any selected node should match the regular expression.
+func xpathMatcher(patterns map[string]string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		body, err := readBody(r)
+		if err != nil {
+			return false
+		}
+
+		for expr, pattern := range patterns {
+			values, err := xpathValues(expr, body)
+			if err != nil || !matchAny(pattern, values) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
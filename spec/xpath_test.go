@@ -0,0 +1,129 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXML(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name:          "Empty",
+			data:          "",
+			expectedError: "invalid XML document",
+		},
+
+		{
+			name:          "Malformed",
+			data:          "<a><b></a>",
+			expectedError: "element <b> closed by </a>",
+		},
+		{
+			name:          "OK",
+			data:          `<?xml version="1.0"?><a x="1"><b>text</b></a>`,
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parseXML([]byte(tc.data))
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, doc)
+			} else {
+				assert.Nil(t, doc)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCompileXPath(t *testing.T) {
+	tests := []struct {
+		name          string
+		expr          string
+		expectedError string
+	}{
+		{"Absolute", "/a/b", ""},
+		{"Descendant", "//b", ""},
+		{"Attribute", "//b/@id", ""},
+		{"Text", "/a/b/text()", ""},
+		{"Predicates", "/a/b[@id='1'][2]/c[last()]", ""},
+		{"Prefixed", "//soap:Body/ns:GetBalance", ""},
+		{"PrefixInLiteral", "//b[@id='p:1']", ""},
+		{"NoNodeSet", "/a/", "expression must evaluate to a node-set"},
+		{"UnclosedPredicate", "/a[1", "/a[1 has an invalid token"},
+		{"UnterminatedLiteral", "/a[@id='1]", "xpath: scanString got unclosed string"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compileXPath(tc.expr)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestXPathValues(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:p="urn:payments">
+  <soap:Body>
+    <p:Transfer currency="CAD">
+      <p:From>1111</p:From>
+      <p:To>2222</p:To>
+      <p:Items>
+        <p:Item id="a"><p:Amount>10</p:Amount></p:Item>
+        <p:Item id="b"><p:Amount>20</p:Amount></p:Item>
+        <p:Item id="c"><p:Amount>30</p:Amount></p:Item>
+      </p:Items>
+    </p:Transfer>
+  </soap:Body>
+</soap:Envelope>`)
+
+	tests := []struct {
+		name           string
+		expr           string
+		expectedValues []string
+	}{
+		{"Absolute", "/Envelope/Body/Transfer/From", []string{"1111"}},
+		{"Prefixed", "/soap:Envelope/soap:Body/p:Transfer/p:To", []string{"2222"}},
+		{"Descendant", "//Amount", []string{"10", "20", "30"}},
+		{"DescendantInPath", "/Envelope//Item/Amount", []string{"10", "20", "30"}},
+		{"Wildcard", "/Envelope/Body/*/From", []string{"1111"}},
+		{"Attribute", "//Transfer/@currency", []string{"CAD"}},
+		{"DescendantAttribute", "//@id", []string{"a", "b", "c"}},
+		{"Text", "//From/text()", []string{"1111"}},
+		{"Position", "//Item[2]/Amount", []string{"20"}},
+		{"Last", "//Item[last()]/@id", []string{"c"}},
+		{"AttributeEquals", "//Item[@id='b']/Amount", []string{"20"}},
+		{"ChildEquals", `//Item[Amount="30"]/@id`, []string{"c"}},
+		{"NotEquals", "//Item[@id!='b']/@id", []string{"a", "c"}},
+		{"PrefixInLiteral", "//Transfer[@currency!='p:CAD']/From", []string{"1111"}},
+		{"Exists", "//*[@currency]/From", []string{"1111"}},
+		{"Parent", "//From/../To", []string{"2222"}},
+		{"Self", "//From/.", []string{"1111"}},
+		{"Element", "//Item[1]", []string{"10"}},
+		{"NoMatch", "//Balance", []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := xpathValues(tc.expr, data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedValues, values)
+		})
+	}
+}